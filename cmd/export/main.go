@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"golearning/internal/content"
+	"golearning/internal/db"
+	"golearning/internal/ingest"
+)
+
+func main() {
+	dbPath := flag.String("db", "./data.db", "Путь к файлу базы данных SQLite")
+	out := flag.String("out", "", "Каталог для выгрузки уроков в формате lessons_mdx")
+	clean := flag.Bool("clean", false, "Удалить из -out каталоги курсов перед выгрузкой")
+	check := flag.Bool("check", false, "Выгрузить во временный каталог, импортировать обратно и сравнить с БД")
+	flag.Parse()
+
+	if (*out == "") == !*check {
+		log.Println("Укажите либо -out, либо -check")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	database, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("Ошибка открытия БД: %v", err)
+	}
+	defer database.Close()
+
+	if err := db.Migrate(database); err != nil {
+		log.Fatalf("Ошибка миграции: %v", err)
+	}
+
+	repo := content.NewRepository(database)
+
+	if *check {
+		if err := checkRoundTrip(ctx, repo); err != nil {
+			log.Fatalf("Проверка не пройдена: %v", err)
+		}
+		return
+	}
+
+	report, err := ingest.NewMDXExporter(repo, *out, ingest.ExportOptions{Clean: *clean}).Export(ctx)
+	printReport(report)
+	if err != nil {
+		log.Fatalf("Ошибка выгрузки: %v", err)
+	}
+	log.Printf("Уроки выгружены в %s", *out)
+}
+
+// checkRoundTrip выгружает БД во временный каталог, импортирует выгрузку в чистую БД
+// и сравнивает контент: выгрузка без потерь даёт ноль расхождений.
+func checkRoundTrip(ctx context.Context, repo *content.Repository) error {
+	tmp, err := os.MkdirTemp("", "golearning-export-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	dir := filepath.Join(tmp, "lessons")
+	report, err := ingest.NewMDXExporter(repo, dir, ingest.ExportOptions{}).Export(ctx)
+	printReport(report)
+	if err != nil {
+		return err
+	}
+
+	checkDB, err := db.Open(filepath.Join(tmp, "check.db"))
+	if err != nil {
+		return err
+	}
+	defer checkDB.Close()
+	if err := db.Migrate(checkDB); err != nil {
+		return err
+	}
+	checkRepo := content.NewRepository(checkDB)
+
+	// Лог импорта по каждому уроку здесь не нужен — важен только итог
+	log.Printf("Импортируем выгрузку обратно...")
+	log.SetOutput(io.Discard)
+	tree, err := ingest.NewMDXSource(dir).Load(ctx)
+	var imported *ingest.ImportReport
+	if err == nil {
+		imported, err = ingest.NewWriter(checkRepo).Write(ctx, tree)
+	}
+	log.SetOutput(os.Stderr)
+	if err != nil {
+		if imported != nil {
+			fmt.Print(imported.Summary())
+		}
+		return fmt.Errorf("import export: %w", err)
+	}
+
+	diffs, err := ingest.CompareContent(repo, checkRepo)
+	if err != nil {
+		return err
+	}
+	for _, d := range diffs {
+		fmt.Println("  ≠", d)
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("%d difference(s) after round trip", len(diffs))
+	}
+	log.Printf("Выгрузка без потерь: курсов %d, модулей %d, уроков %d", report.Courses, report.Modules, report.Lessons)
+	return nil
+}
+
+func printReport(report *ingest.ExportReport) {
+	if report == nil {
+		return
+	}
+	for _, w := range report.Warnings {
+		fmt.Println("  ⚠️", w)
+	}
+	for _, f := range report.Failed {
+		fmt.Printf("  ❌ %s: %v\n", f.Lesson, f.Err)
+	}
+	fmt.Printf("Курсов: %d, модулей: %d, уроков: %d\n", report.Courses, report.Modules, report.Lessons)
+}
@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 
 	"golearning/internal/content"
@@ -16,13 +21,32 @@ import (
 func main() {
 	// Флаги командной строки
 	dbPath := flag.String("db", "./data.db", "Путь к файлу базы данных SQLite")
+	source := flag.String("source", "", "Источник контента: "+strings.Join(ingest.SourceNames(), "|")+" (по умолчанию выводится из -pack/-dir/-mdx/-demo)")
 	limit := flag.Int("limit", 0, "Ограничение количества уроков (0 = без ограничения)")
 	baseURL := flag.String("url", "https://metanit.com/go/tutorial", "Базовый URL для импорта")
 	demo := flag.Bool("demo", false, "Использовать демонстрационные данные вместо загрузки")
 	dir := flag.String("dir", "", "Директория с Markdown/MDX файлами уроков")
-	useMDX := flag.Bool("mdx", false, "Использовать MDX парсер (рекомендуется для lessons_mdx)")
+	useMDX := flag.Bool("mdx", false, "Каталог -dir в формате MDX (то же, что -source mdx)")
+	pack := flag.String("pack", "", "Курс-пак: архив .zip/.tar.gz или путь к git-репозиторию (в том числе bare)")
+	ref := flag.String("ref", "", "Ветка, тег или коммит git-пака (по умолчанию HEAD)")
+	dryRun := flag.Bool("dry-run", false, "Показать, что изменится в БД, не сохраняя изменений")
+	jsonOut := flag.Bool("json", false, "Вывести результат -dry-run в формате JSON")
+	prune := flag.Bool("prune", false, "Удалить из БД модули и уроки курсов -dir, которых в нём больше нет")
+	assumeYes := flag.Bool("yes", false, "Не спрашивать подтверждение удаления при -prune")
 	flag.Parse()
 
+	if *source == "" && *pack != "" {
+		if *source = ingest.PackKind(*pack); *source == "" {
+			log.Fatalf("Не удалось определить вид пака %s: ожидается .zip, .tar.gz или git-репозиторий", *pack)
+		}
+	}
+	if *source == "" {
+		*source = defaultSource(*dir, *useMDX, *demo)
+	}
+	if *prune && *dir == "" {
+		log.Fatal("-prune работает только с -dir: удаляется то, чего нет в каталоге контента")
+	}
+
 	log.Printf("Go Learning — Импорт контента")
 	log.Printf("База данных: %s", *dbPath)
 
@@ -52,59 +76,196 @@ func main() {
 
 	repo := content.NewRepository(database)
 
-	// Выбираем режим импорта
-	switch {
-	case *dir != "":
-		// Импорт из директории с файлами уроков
-		if *useMDX {
-			log.Printf("Режим: MDX импорт из директории %s", *dir)
-			importer := ingest.NewMDXImporter(repo, *dir)
-			if err := importer.Import(ctx); err != nil {
-				log.Fatalf("Ошибка MDX импорта: %v", err)
-			}
-		} else {
-			log.Printf("Режим: Markdown импорт из директории %s", *dir)
-			importer := ingest.NewMarkdownImporter(repo, *dir)
-			if err := importer.Import(ctx); err != nil {
-				log.Fatalf("Ошибка импорта: %v", err)
-			}
-		}
+	opts := importOptions{
+		source: *source,
+		config: ingest.SourceConfig{Dir: *dir, BaseURL: *baseURL, Limit: *limit, Pack: *pack, Ref: *ref},
+		prune:  *prune,
+	}
+
+	if *dryRun {
+		os.Exit(runDryRun(ctx, repo, opts, *jsonOut))
+	}
+
+	report, err := runImport(ctx, repo, opts)
+	if report != nil {
+		fmt.Print(report.Summary())
+	}
+	if err != nil {
+		log.Fatalf("Ошибка импорта: %v", err)
+	}
 
-	case *demo:
-		// Демонстрационные данные
-		log.Println("Режим: демонстрационные данные")
-		demoData := ingest.NewDemoData(repo)
-		if err := demoData.Seed(ctx); err != nil {
-			log.Fatalf("Ошибка создания демо-данных: %v", err)
+	if opts.prune {
+		if err := pruneStale(repo, report.Source(), *assumeYes); err != nil {
+			log.Fatalf("Ошибка удаления устаревшего контента: %v", err)
 		}
+	}
+
+	log.Println("Импорт успешно завершён!")
+}
+
+// pruneStale удаляет контент, которого нет в источнике, после подтверждения.
+func pruneStale(repo *content.Repository, source content.ContentSet, assumeYes bool) error {
+	set, err := repo.FindStale(source)
+	if err != nil {
+		return err
+	}
+	if set.Empty() {
+		log.Println("Устаревшего контента нет")
+		return nil
+	}
+	if !ingest.ConfirmPrune(set, assumeYes, os.Stdin, os.Stdout) {
+		log.Println("Удаление отменено")
+		return nil
+	}
+	if err := repo.Prune(set); err != nil {
+		return err
+	}
+	log.Printf("Удалено: курсов %d, модулей %d, уроков %d", len(set.Courses), len(set.Modules), len(set.Lessons))
+	return nil
+}
 
+// importOptions — выбранный флагами источник и режим импорта.
+type importOptions struct {
+	source string
+	config ingest.SourceConfig
+	prune  bool
+}
+
+// defaultSource выводит источник из флагов, которые были до -source.
+func defaultSource(dir string, useMDX, demo bool) string {
+	switch {
+	case dir != "" && useMDX:
+		return "mdx"
+	case dir != "":
+		return "md"
+	case demo:
+		return "demo"
 	default:
-		// Импорт с сайта
-		log.Printf("Источник: %s", *baseURL)
+		return "web"
+	}
+}
+
+// runImport загружает контент из источника и записывает его в БД.
+func runImport(ctx context.Context, repo *content.Repository, opts importOptions) (*ingest.ImportReport, error) {
+	src, err := ingest.NewSource(opts.source, opts.config)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Источник: %s", src.Name())
+
+	tree, err := src.Load(ctx)
+	if err != nil && src.Name() == "web" && ctx.Err() == nil {
+		// Сайт недоступен — заполняем БД демонстрационными данными
+		log.Printf("Ошибка загрузки с сайта: %v", err)
+		log.Println("Переключаемся на демонстрационные данные...")
+		tree, err = ingest.NewDemoData().Load(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", src.Name(), err)
+	}
 
-		// Создаём компоненты pipeline
-		crawler := ingest.NewCrawler(*baseURL)
-		parser := ingest.NewParser()
-		rewriter := ingest.NewLocalRewriter()
+	// Capstone-проекты лежат рядом с уроками и пишутся в той же транзакции
+	writer := ingest.NewWriter(repo)
+	if opts.config.Dir != "" {
+		projectsDir := filepath.Join(opts.config.Dir, ingest.ProjectsDir)
+		if info, err := os.Stat(projectsDir); err == nil && info.IsDir() {
+			writer.WithProjects(projectsDir)
+		}
+	}
+	return writer.Write(ctx, tree)
+}
+
+// errDryRun откатывает транзакцию пробного импорта.
+var errDryRun = errors.New("dry run")
+
+// dryRunOutput — результат пробного импорта в формате JSON.
+type dryRunOutput struct {
+	Created   int                 `json:"created"`
+	Updated   int                 `json:"updated"`
+	Unchanged int                 `json:"unchanged"`
+	Failed    []dryRunFailure     `json:"failed"`
+	Diff      *ingest.ContentDiff `json:"diff"`
+}
+
+type dryRunFailure struct {
+	Lesson string `json:"lesson"`
+	Error  string `json:"error"`
+}
 
-		// Создаём и запускаем pipeline
-		pipeline := ingest.NewPipeline(crawler, parser, rewriter, repo)
+// runDryRun выполняет импорт в транзакции, которая всегда откатывается, и печатает,
+// что изменилось бы в БД. Возвращает код завершения: 1 — если импорт завершился бы с ошибкой.
+func runDryRun(ctx context.Context, repo *content.Repository, opts importOptions, jsonOut bool) int {
+	log.Println("Режим: dry-run — изменения не будут сохранены")
 
-		if err := pipeline.Run(ctx, *limit); err != nil {
-			if ctx.Err() != nil {
-				log.Println("Импорт прерван пользователем")
-				os.Exit(0)
+	var report *ingest.ImportReport
+	var diff *ingest.ContentDiff
+	var importErr error
+	err := repo.InTx(func(tx *content.Repository) error {
+		before, err := tx.Snapshot()
+		if err != nil {
+			return err
+		}
+
+		report, importErr = runImport(ctx, tx, opts)
+		if importErr != nil && !errors.Is(importErr, ingest.ErrImportFailed) {
+			return importErr
+		}
+
+		// Удаление тоже откатится: подтверждение не нужно, дифф покажет удаляемые уроки
+		if opts.prune && importErr == nil {
+			set, err := tx.FindStale(report.Source())
+			if err != nil {
+				return err
 			}
+			if err := tx.Prune(set); err != nil {
+				return err
+			}
+		}
 
-			log.Printf("Ошибка загрузки с сайта: %v", err)
-			log.Println("Переключаемся на демонстрационные данные...")
+		after, err := tx.Snapshot()
+		if err != nil {
+			return err
+		}
+		var source []string
+		if report != nil {
+			source = report.Lessons
+		}
+		diff = ingest.DiffSnapshots(before, after, source)
+		return errDryRun
+	})
+	if !errors.Is(err, errDryRun) {
+		log.Printf("Ошибка пробного импорта: %v", err)
+		return 1
+	}
 
-			demoData := ingest.NewDemoData(repo)
-			if err := demoData.Seed(ctx); err != nil {
-				log.Fatalf("Ошибка создания демо-данных: %v", err)
+	if jsonOut {
+		out := dryRunOutput{Failed: []dryRunFailure{}, Diff: diff}
+		if report != nil {
+			out.Created, out.Updated, out.Unchanged = report.Created, report.Updated, report.Unchanged
+			for _, f := range report.Failed {
+				out.Failed = append(out.Failed, dryRunFailure{Lesson: f.Lesson, Error: f.Err.Error()})
 			}
 		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			log.Printf("Ошибка вывода JSON: %v", err)
+			return 1
+		}
+	} else {
+		if report != nil {
+			fmt.Print(report.Summary())
+		}
+		if diff.Empty() {
+			fmt.Println("Изменений в БД нет")
+		} else {
+			fmt.Print(diff.Text())
+		}
 	}
 
-	log.Println("Импорт успешно завершён!")
+	if importErr != nil {
+		log.Printf("Ошибка импорта: %v", importErr)
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"golearning/internal/content"
+	"golearning/internal/db"
+	"golearning/internal/practice"
+	"golearning/internal/progress"
+)
+
+func main() {
+	dbPath := flag.String("db", "./data.db", "Путь к файлу базы данных SQLite")
+	taskRef := flag.String("task", "", "Задание: ID или slug-урока/ключ-задания (например, jwt-osnovy-1/lab)")
+	dir := flag.String("dir", ".", "Каталог проекта для проверки")
+	flag.Parse()
+
+	if *taskRef == "" {
+		fmt.Fprintln(os.Stderr, "Укажите задание: -task")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	database, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("Ошибка открытия БД: %v", err)
+	}
+	defer database.Close()
+
+	if err := db.Migrate(database); err != nil {
+		log.Fatalf("Ошибка миграции: %v", err)
+	}
+
+	contentRepo := content.NewRepository(database)
+	progressRepo := progress.NewRepository(database)
+	checker := practice.NewChecker(practice.NewLocalRunner(), contentRepo, progressRepo)
+
+	task, err := resolveTask(contentRepo, *taskRef)
+	if err != nil {
+		log.Fatalf("Ошибка поиска задания: %v", err)
+	}
+
+	fmt.Printf("🔎 %s\n", task.Title)
+	result, err := checker.CheckProject(context.Background(), task.ID, *dir)
+	if err != nil {
+		log.Fatalf("Ошибка проверки: %v", err)
+	}
+
+	fmt.Print(result.Report())
+	if !result.Success {
+		fmt.Println("❌ Проверка не пройдена")
+		os.Exit(1)
+	}
+	if result.Review != nil {
+		fmt.Printf("✅ Проверка пройдена: сдача #%d ждёт проверки на /reviews, очки — после одобрения\n", result.Review.ID)
+	} else {
+		fmt.Println("✅ Проверка пройдена (задание уже было засчитано)")
+	}
+}
+
+// resolveTask находит задание по ID или по паре slug-урока/ключ-задания.
+func resolveTask(repo *content.Repository, ref string) (*content.Task, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		task, err := repo.GetTaskByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if task == nil {
+			return nil, fmt.Errorf("задание %d не найдено", id)
+		}
+		return task, nil
+	}
+
+	slug, key, ok := strings.Cut(ref, "/")
+	if !ok {
+		return nil, fmt.Errorf("ожидается ID или slug-урока/ключ-задания: %q", ref)
+	}
+	lesson, err := repo.GetLessonBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	if lesson == nil {
+		return nil, fmt.Errorf("урок %q не найден", slug)
+	}
+	tasks, err := repo.GetTasksByLessonID(lesson.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		if tasks[i].Key == key {
+			return &tasks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("в уроке %q нет задания %q", slug, key)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golearning/internal/ingest"
+)
+
+func main() {
+	dir := flag.String("dir", "./lessons_mdx", "Каталог MDX-уроков")
+	jsonOut := flag.Bool("json", false, "Вывести замечания в формате JSON")
+	noCompile := flag.Bool("no-compile", false, "Не проверять компиляцию StarterCode")
+	strict := flag.Bool("strict", false, "Считать предупреждения ошибками")
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	linter := ingest.NewLinter(*dir, ingest.LintOptions{SkipCompile: *noCompile})
+	report, err := linter.Lint(ctx)
+	if err != nil {
+		log.Fatalf("Ошибка проверки: %v", err)
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatalf("Ошибка вывода JSON: %v", err)
+		}
+	} else {
+		for _, issue := range report.Issues {
+			fmt.Println(issue)
+		}
+		fmt.Printf("Уроков: %d, ошибок: %d, предупреждений: %d\n", report.Lessons, report.Errors, report.Warnings)
+	}
+
+	if report.Errors > 0 || (*strict && report.Warnings > 0) {
+		os.Exit(1)
+	}
+}
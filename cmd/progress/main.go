@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"golearning/internal/db"
+	"golearning/internal/progress"
+)
+
+func main() {
+	dbPath := flag.String("db", "./data.db", "Путь к файлу базы данных SQLite")
+	exportPath := flag.String("export", "", "Выгрузить прогресс, заметки и отправки в JSON-файл (\"-\" — stdout)")
+	importPath := flag.String("import", "", "Слить прогресс из JSON-файла экспорта с текущей базой")
+	recalc := flag.Bool("recalc", false, "Пересчитать очки уроков по решённым заданиям")
+	notesPath := flag.String("notes", "", "Выгрузить все заметки в Markdown-файл (или ZIP, если путь оканчивается на .zip)")
+	flag.Parse()
+
+	modes := 0
+	for _, set := range []bool{*exportPath != "", *importPath != "", *recalc, *notesPath != ""} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
+		fmt.Fprintln(os.Stderr, "Укажите ровно один из флагов: -export, -import, -recalc или -notes")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	database, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("Ошибка открытия БД: %v", err)
+	}
+	defer database.Close()
+
+	if err := db.Migrate(database); err != nil {
+		log.Fatalf("Ошибка миграции: %v", err)
+	}
+
+	repo := progress.NewRepository(database)
+
+	if *recalc {
+		report, err := repo.RecalculatePoints()
+		if err != nil {
+			log.Fatalf("Ошибка пересчёта: %v", err)
+		}
+		fmt.Println("✅ Очки пересчитаны")
+		fmt.Printf("- восстановлено отметок о решении: %d\n", report.CompletionsRestored)
+		fmt.Printf("- уроков обновлено: %d\n", report.LessonsUpdated)
+		fmt.Printf("- очков: было %d, стало %d\n", report.PointsBefore, report.PointsAfter)
+		return
+	}
+
+	if *notesPath != "" {
+		entries, err := repo.Notebook()
+		if err != nil {
+			log.Fatalf("Ошибка чтения заметок: %v", err)
+		}
+
+		f, err := os.Create(*notesPath)
+		if err != nil {
+			log.Fatalf("Ошибка создания файла: %v", err)
+		}
+		defer f.Close()
+
+		if strings.HasSuffix(strings.ToLower(*notesPath), ".zip") {
+			err = progress.WriteNotebookZip(f, entries)
+		} else {
+			err = progress.WriteNotebookMarkdown(f, entries)
+		}
+		if err != nil {
+			log.Fatalf("Ошибка записи: %v", err)
+		}
+
+		fmt.Printf("✅ Выгружено заметок: %d → %s\n", len(entries), *notesPath)
+		return
+	}
+
+	if *exportPath != "" {
+		export, err := repo.Export()
+		if err != nil {
+			log.Fatalf("Ошибка экспорта: %v", err)
+		}
+
+		out := os.Stdout
+		if *exportPath != "-" {
+			f, err := os.Create(*exportPath)
+			if err != nil {
+				log.Fatalf("Ошибка создания файла: %v", err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(export); err != nil {
+			log.Fatalf("Ошибка записи: %v", err)
+		}
+
+		if *exportPath != "-" {
+			fmt.Printf("✅ Экспортировано: уроков %d, заметок %d, отправок %d → %s\n",
+				len(export.Progress), len(export.Notes), len(export.Submissions), *exportPath)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(*importPath)
+	if err != nil {
+		log.Fatalf("Ошибка чтения файла: %v", err)
+	}
+
+	var export progress.Export
+	if err := json.Unmarshal(data, &export); err != nil {
+		log.Fatalf("Некорректный файл экспорта: %v", err)
+	}
+
+	report, err := repo.Import(&export)
+	if err != nil {
+		log.Fatalf("Ошибка импорта: %v", err)
+	}
+
+	fmt.Println("✅ Прогресс импортирован")
+	fmt.Printf("- уроков объединено: %d\n", report.ProgressMerged)
+	fmt.Printf("- заметок: импортировано %d, оставлено локальных %d\n", report.NotesImported, report.NotesKept)
+	fmt.Printf("- отправок: добавлено %d, пропущено %d\n", report.SubmissionsAdded, report.SubmissionsSkipped)
+	fmt.Printf("- открытых решений: %d\n", report.RevealsImported)
+	for _, slug := range report.UnknownLessons {
+		fmt.Printf("⚠️ урок не найден: %s\n", slug)
+	}
+	for _, ref := range report.UnknownTasks {
+		fmt.Printf("⚠️ задание не найдено: %s\n", ref)
+	}
+}
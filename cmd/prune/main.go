@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"golearning/internal/content"
+	"golearning/internal/db"
+	"golearning/internal/ingest"
+)
+
+func main() {
+	dbPath := flag.String("db", "./data.db", "Путь к файлу базы данных SQLite")
+	dir := flag.String("dir", "", "Удалить модули и уроки курсов каталога, которых в нём больше нет")
+	useMDX := flag.Bool("mdx", false, "Каталог -dir в формате MDX (lessons_mdx)")
+	demo := flag.Bool("demo", false, "Удалить демо-модули и демо-уроки")
+	courses := flag.String("courses", "", "Удалить курсы (slug'и через запятую) вместе с их модулями и уроками")
+	modules := flag.String("modules", "", "Удалить модули (slug'и через запятую) вместе с их уроками")
+	lessons := flag.String("lessons", "", "Удалить уроки (slug'и через запятую)")
+	assumeYes := flag.Bool("yes", false, "Не спрашивать подтверждение")
+	flag.Parse()
+
+	target := content.ContentSet{
+		Courses: splitSlugs(*courses),
+		Modules: splitSlugs(*modules),
+		Lessons: splitSlugs(*lessons),
+	}
+	if *demo {
+		demoSet := ingest.DemoContent()
+		target.Modules = append(target.Modules, demoSet.Modules...)
+		target.Lessons = append(target.Lessons, demoSet.Lessons...)
+	}
+	explicit := len(target.Courses)+len(target.Modules)+len(target.Lessons) > 0
+	if (*dir == "") == !explicit {
+		log.Println("Укажите либо -dir, либо -demo/-courses/-modules/-lessons")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	database, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("Ошибка открытия БД: %v", err)
+	}
+	defer database.Close()
+
+	if err := db.Migrate(database); err != nil {
+		log.Fatalf("Ошибка миграции: %v", err)
+	}
+
+	repo := content.NewRepository(database)
+
+	var set *content.PruneSet
+	if *dir != "" {
+		log.Printf("Сравниваем БД с каталогом %s", *dir)
+		name := "md"
+		if *useMDX {
+			name = "mdx"
+		}
+		src, err := ingest.NewSource(name, ingest.SourceConfig{Dir: *dir})
+		if err != nil {
+			log.Fatalf("Ошибка источника: %v", err)
+		}
+		source, err := ingest.ScanSource(ctx, src)
+		if err != nil {
+			log.Fatalf("Ошибка разбора каталога: %v", err)
+		}
+		set, err = repo.FindStale(source)
+		if err != nil {
+			log.Fatalf("Ошибка поиска устаревшего контента: %v", err)
+		}
+	} else {
+		set, err = repo.FindContent(target)
+		if err != nil {
+			log.Fatalf("Ошибка поиска контента: %v", err)
+		}
+	}
+
+	if set.Empty() {
+		log.Println("Удалять нечего")
+		return
+	}
+	if !ingest.ConfirmPrune(set, *assumeYes, os.Stdin, os.Stdout) {
+		log.Println("Удаление отменено")
+		return
+	}
+	if err := repo.Prune(set); err != nil {
+		log.Fatalf("Ошибка удаления: %v", err)
+	}
+	log.Printf("✅ Удалено: курсов %d, модулей %d, уроков %d", len(set.Courses), len(set.Modules), len(set.Lessons))
+}
+
+// splitSlugs разбирает список slug'ов через запятую.
+func splitSlugs(s string) []string {
+	var slugs []string
+	for _, slug := range strings.Split(s, ",") {
+		if slug = strings.TrimSpace(slug); slug != "" {
+			slugs = append(slugs, slug)
+		}
+	}
+	return slugs
+}
@@ -2,16 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"golearning/internal/content"
 	"golearning/internal/db"
+	"golearning/internal/ingest"
 	"golearning/internal/practice"
 	"golearning/internal/progress"
 	"golearning/internal/web"
@@ -21,8 +24,14 @@ func main() {
 	// Флаги командной строки
 	dbPath := flag.String("db", "./data.db", "Путь к файлу базы данных SQLite")
 	addr := flag.String("addr", ":8080", "Адрес для прослушивания")
+	contentDir := flag.String("content-dir", "", "Каталог MDX-уроков: импортировать изменения при запуске")
+	watch := flag.Bool("watch", false, "Следить за -content-dir и переимпортировать изменённые уроки на лету")
 	flag.Parse()
 
+	if *watch && *contentDir == "" {
+		log.Fatalf("Флаг -watch требует -content-dir")
+	}
+
 	log.Printf("Go Learning — Веб-сервер")
 	log.Printf("База данных: %s", *dbPath)
 	log.Printf("Адрес: %s", *addr)
@@ -53,6 +62,32 @@ func main() {
 		log.Fatalf("Ошибка создания сервера: %v", err)
 	}
 
+	// Контекст живёт до сигнала завершения; по нему останавливается наблюдатель
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *contentDir != "" {
+		source := ingest.NewMDXSource(*contentDir)
+		writer := ingest.NewWriter(contentRepo)
+		tree, err := source.Load(ctx)
+		var report *ingest.ImportReport
+		if err == nil {
+			report, err = writer.Write(ctx, tree)
+		}
+		if report != nil {
+			log.Printf("Импорт %s: %s", *contentDir, strings.TrimSpace(report.Summary()))
+		}
+		// В режиме -watch сломанный урок можно исправить на лету, поэтому не падаем
+		if err != nil && !*watch {
+			log.Fatalf("Ошибка импорта контента: %v", err)
+		}
+
+		if *watch {
+			server.EnableLiveReload()
+			go watchContent(ctx, writer, source, server, *contentDir)
+		}
+	}
+
 	httpServer := &http.Server{
 		Addr:         *addr,
 		Handler:      server.Router(),
@@ -88,3 +123,35 @@ func main() {
 	<-done
 	log.Println("Сервер остановлен")
 }
+
+// watchContent переимпортирует изменённые уроки и просит открытые вкладки
+// с ними перезагрузиться. Ошибка импорта не останавливает наблюдение.
+func watchContent(ctx context.Context, writer *ingest.Writer, source ingest.FileSource, server *web.Server, dir string) {
+	log.Printf("Слежу за изменениями в %s", dir)
+	err := ingest.Watch(ctx, dir, func(paths []string) {
+		for _, path := range paths {
+			report, err := writer.WriteFile(ctx, source, path)
+			if errors.Is(err, ingest.ErrNotLesson) {
+				continue
+			}
+			if err != nil {
+				log.Printf("Ошибка импорта %s: %v", path, err)
+				if report != nil {
+					log.Print(report.Summary())
+				}
+				continue
+			}
+			for _, slug := range report.Changed {
+				log.Printf("Урок обновлён: %s", slug)
+				server.NotifyLessonChanged(slug)
+			}
+			for oldSlug, newSlug := range report.Renamed {
+				log.Printf("Урок переименован: %s → %s", oldSlug, newSlug)
+				server.NotifyLessonMoved(oldSlug, newSlug)
+			}
+		}
+	})
+	if err != nil {
+		log.Printf("Наблюдение за %s остановлено: %v", dir, err)
+	}
+}
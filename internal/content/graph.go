@@ -0,0 +1,256 @@
+package content
+
+import "sort"
+
+// Размеры узлов графа на SVG-схеме.
+const (
+	GraphNodeWidth  = 220
+	GraphNodeHeight = 44
+	graphGapX       = 80
+	graphGapY       = 20
+	graphPadding    = 20
+)
+
+// Graph — граф зависимостей уроков.
+type Graph struct {
+	requires map[int64][]int64 // урок → уроки, которые он требует
+	unlocks  map[int64][]int64 // урок → уроки, которые от него зависят
+}
+
+// GraphNode — урок на схеме графа.
+type GraphNode struct {
+	LessonID int64
+	Depth    int
+	X, Y     int
+}
+
+// GraphEdge — стрелка от требуемого урока к зависимому.
+type GraphEdge struct {
+	FromID, ToID   int64
+	X1, Y1, X2, Y2 int
+	MidX           int // X опорных точек кривой Безье
+}
+
+// GraphLayout — раскладка графа по слоям: слой = длина самой длинной цепочки требований.
+type GraphLayout struct {
+	Nodes  []GraphNode
+	Edges  []GraphEdge
+	Width  int
+	Height int
+}
+
+// NewGraph строит граф из рёбер.
+func NewGraph(edges []Prerequisite) *Graph {
+	g := &Graph{
+		requires: make(map[int64][]int64),
+		unlocks:  make(map[int64][]int64),
+	}
+	for _, e := range edges {
+		g.requires[e.LessonID] = append(g.requires[e.LessonID], e.RequiresID)
+		g.unlocks[e.RequiresID] = append(g.unlocks[e.RequiresID], e.LessonID)
+	}
+	return g
+}
+
+// Requires возвращает уроки, которые требует урок.
+func (g *Graph) Requires(lessonID int64) []int64 {
+	return g.requires[lessonID]
+}
+
+// Missing возвращает непройденные пререквизиты урока.
+func (g *Graph) Missing(lessonID int64, done map[int64]bool) []int64 {
+	var missing []int64
+	for _, id := range g.requires[lessonID] {
+		if !done[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// Ready сообщает, пройдены ли все пререквизиты урока.
+func (g *Graph) Ready(lessonID int64, done map[int64]bool) bool {
+	return len(g.Missing(lessonID, done)) == 0
+}
+
+// Recommend выбирает следующий урок после current: сначала открытые им уроки,
+// затем следующие по курсу, затем первые по курсу. Подходит только непройденный
+// урок, все пререквизиты которого пройдены. order — все уроки в порядке курса.
+// Возвращает 0, если рекомендовать нечего.
+func (g *Graph) Recommend(current int64, order []int64, done map[int64]bool) int64 {
+	position := make(map[int64]int, len(order))
+	for i, id := range order {
+		position[id] = i
+	}
+
+	candidate := func(id int64) bool {
+		return id != current && !done[id] && g.Ready(id, done)
+	}
+
+	unlocked := append([]int64(nil), g.unlocks[current]...)
+	sort.Slice(unlocked, func(i, j int) bool { return position[unlocked[i]] < position[unlocked[j]] })
+	for _, id := range unlocked {
+		if candidate(id) {
+			return id
+		}
+	}
+
+	start, ok := position[current]
+	if !ok {
+		start = -1
+	}
+	for _, id := range order[start+1:] {
+		if candidate(id) {
+			return id
+		}
+	}
+	for _, id := range order[:start+1] {
+		if candidate(id) {
+			return id
+		}
+	}
+	return 0
+}
+
+// FindCycle возвращает уроки, образующие цикл зависимостей, или nil.
+func (g *Graph) FindCycle() []int64 {
+	const (
+		unvisited = iota
+		inStack
+		finished
+	)
+	state := make(map[int64]int)
+	var stack []int64
+
+	var visit func(id int64) []int64
+	visit = func(id int64) []int64 {
+		state[id] = inStack
+		stack = append(stack, id)
+		for _, req := range g.requires[id] {
+			switch state[req] {
+			case inStack:
+				for i, s := range stack {
+					if s == req {
+						return append([]int64(nil), stack[i:]...)
+					}
+				}
+			case unvisited:
+				if cycle := visit(req); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = finished
+		return nil
+	}
+
+	ids := make([]int64, 0, len(g.requires))
+	for id := range g.requires {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		if state[id] == unvisited {
+			if cycle := visit(id); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// Layout раскладывает уроки, участвующие в зависимостях, по слоям слева направо.
+// Внутри слоя уроки идут в порядке курса. Рёбра цикла (если он есть) не удлиняют слои.
+func (g *Graph) Layout(order []int64) *GraphLayout {
+	position := make(map[int64]int, len(order))
+	for i, id := range order {
+		position[id] = i
+	}
+
+	depth := make(map[int64]int)
+	visiting := make(map[int64]bool)
+	var depthOf func(id int64) int
+	depthOf = func(id int64) int {
+		if d, ok := depth[id]; ok {
+			return d
+		}
+		visiting[id] = true
+		d := 0
+		for _, req := range g.requires[id] {
+			if visiting[req] {
+				continue
+			}
+			if rd := depthOf(req) + 1; rd > d {
+				d = rd
+			}
+		}
+		visiting[id] = false
+		depth[id] = d
+		return d
+	}
+
+	for id := range g.requires {
+		depthOf(id)
+	}
+	for id := range g.unlocks {
+		depthOf(id)
+	}
+
+	ids := make([]int64, 0, len(depth))
+	for id := range depth {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if depth[ids[i]] != depth[ids[j]] {
+			return depth[ids[i]] < depth[ids[j]]
+		}
+		return position[ids[i]] < position[ids[j]]
+	})
+
+	layout := &GraphLayout{}
+	coords := make(map[int64]GraphNode, len(ids))
+	rows := make(map[int]int)
+	maxRows := 0
+	maxDepth := 0
+	for _, id := range ids {
+		d := depth[id]
+		n := GraphNode{
+			LessonID: id,
+			Depth:    d,
+			X:        graphPadding + d*(GraphNodeWidth+graphGapX),
+			Y:        graphPadding + rows[d]*(GraphNodeHeight+graphGapY),
+		}
+		rows[d]++
+		if rows[d] > maxRows {
+			maxRows = rows[d]
+		}
+		if d > maxDepth {
+			maxDepth = d
+		}
+		coords[id] = n
+		layout.Nodes = append(layout.Nodes, n)
+	}
+
+	for _, id := range ids {
+		to := coords[id]
+		for _, req := range g.requires[id] {
+			from := coords[req]
+			layout.Edges = append(layout.Edges, GraphEdge{
+				FromID: req,
+				ToID:   id,
+				X1:     from.X + GraphNodeWidth,
+				Y1:     from.Y + GraphNodeHeight/2,
+				X2:     to.X,
+				Y2:     to.Y + GraphNodeHeight/2,
+				MidX:   (from.X + GraphNodeWidth + to.X) / 2,
+			})
+		}
+	}
+
+	if len(ids) > 0 {
+		layout.Width = 2*graphPadding + (maxDepth+1)*GraphNodeWidth + maxDepth*graphGapX
+		layout.Height = 2*graphPadding + maxRows*GraphNodeHeight + (maxRows-1)*graphGapY
+	}
+	return layout
+}
@@ -0,0 +1,244 @@
+package content
+
+import (
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Milestone — этап capstone-проекта: пункт чек-листа из ТЗ.
+//
+// В Markdown ТЗ этап — это пункт списка задач GFM с необязательной аннотацией
+// в HTML-комментарии (при рендеринге она не видна):
+//
+//   - [ ] Graceful shutdown (SIGTERM) <!-- id: nfr-shutdown; lessons: production-ready-http-server-1; verify: contains *.go Shutdown( -->
+//
+// id — стабильный ключ этапа (иначе — хеш раздела и текста), lessons — slug уроков,
+// от которых зависит этап, verify — шаги скрипта проверки проекта через «|».
+type Milestone struct {
+	ID           int64
+	ProjectSlug  string
+	Key          string
+	Section      string
+	Title        string
+	LessonSlugs  []string
+	VerifyScript string
+	OrderIndex   int
+}
+
+var (
+	milestoneItemRe    = regexp.MustCompile(`^[-*]\s+\[[ xX]\]\s+(.+)$`)
+	milestoneCommentRe = regexp.MustCompile(`<!--(.*?)-->`)
+	milestoneHeadingRe = regexp.MustCompile(`^(#{2,3})\s+(.+)$`)
+	lessonLinkRe       = regexp.MustCompile(`\(/lessons/([a-z0-9-]+)\)`)
+)
+
+// ParseMilestones извлекает этапы из Markdown ТЗ проекта.
+// Раздел этапа — ближайший заголовок второго уровня (и третьего, если он есть).
+func ParseMilestones(projectSlug, spec string) ([]Milestone, error) {
+	var (
+		milestones []Milestone
+		h2, h3     string
+		seen       = make(map[string]bool)
+	)
+
+	for _, raw := range strings.Split(spec, "\n") {
+		line := strings.TrimSpace(raw)
+
+		if m := milestoneHeadingRe.FindStringSubmatch(line); m != nil {
+			if m[1] == "##" {
+				h2, h3 = m[2], ""
+			} else {
+				h3 = m[2]
+			}
+			continue
+		}
+
+		m := milestoneItemRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		ms := Milestone{
+			ProjectSlug: projectSlug,
+			Section:     h2,
+			OrderIndex:  len(milestones),
+		}
+		if h3 != "" {
+			ms.Section = h2 + " · " + h3
+		}
+
+		text := m[1]
+		if c := milestoneCommentRe.FindStringSubmatch(text); c != nil {
+			if err := parseMilestoneAnnotation(&ms, c[1]); err != nil {
+				return nil, fmt.Errorf("milestone %q: %w", text, err)
+			}
+			text = milestoneCommentRe.ReplaceAllString(text, "")
+		}
+		ms.Title = strings.TrimSpace(text)
+
+		// Ссылки на уроки прямо в тексте тоже считаются зависимостями
+		for _, l := range lessonLinkRe.FindAllStringSubmatch(ms.Title, -1) {
+			ms.LessonSlugs = appendUnique(ms.LessonSlugs, l[1])
+		}
+
+		if ms.Key == "" {
+			sum := sha1.Sum([]byte(ms.Section + "\n" + ms.Title))
+			ms.Key = hex.EncodeToString(sum[:])[:10]
+		}
+		if seen[ms.Key] {
+			return nil, fmt.Errorf("duplicate milestone id %q", ms.Key)
+		}
+		seen[ms.Key] = true
+
+		milestones = append(milestones, ms)
+	}
+
+	return milestones, nil
+}
+
+// parseMilestoneAnnotation разбирает аннотацию вида "id: x; lessons: a, b; verify: build | test".
+func parseMilestoneAnnotation(ms *Milestone, annotation string) error {
+	for _, part := range strings.Split(annotation, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return fmt.Errorf("annotation %q: expected key: value", part)
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case "id":
+			ms.Key = value
+		case "lessons":
+			for _, slug := range strings.Split(value, ",") {
+				if slug = strings.TrimSpace(slug); slug != "" {
+					ms.LessonSlugs = appendUnique(ms.LessonSlugs, slug)
+				}
+			}
+		case "verify":
+			var steps []string
+			for _, step := range strings.Split(value, "|") {
+				if step = strings.TrimSpace(step); step != "" {
+					steps = append(steps, step)
+				}
+			}
+			ms.VerifyScript = strings.Join(steps, "\n")
+		default:
+			return fmt.Errorf("unknown annotation key %q", key)
+		}
+	}
+	return nil
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// SyncMilestones приводит этапы проекта в БД к списку из ТЗ: новые добавляются,
+// изменённые обновляются по ключу, исчезнувшие удаляются вместе с отметками.
+func (r *Repository) SyncMilestones(projectSlug string, milestones []Milestone) error {
+	return r.withTx("sync milestones", func(tx dbtx) error {
+		keys := make([]interface{}, 0, len(milestones)+1)
+		keys = append(keys, projectSlug)
+		for i := range milestones {
+			ms := &milestones[i]
+			_, err := tx.Exec(
+				`INSERT INTO project_milestones (project_slug, milestone_key, section, title, lessons, verify_script, order_index)
+				 VALUES (?, ?, ?, ?, ?, ?, ?)
+				 ON CONFLICT(project_slug, milestone_key) DO UPDATE SET
+				 section = excluded.section, title = excluded.title, lessons = excluded.lessons,
+				 verify_script = excluded.verify_script, order_index = excluded.order_index`,
+				projectSlug, ms.Key, ms.Section, ms.Title, strings.Join(ms.LessonSlugs, ","), ms.VerifyScript, ms.OrderIndex,
+			)
+			if err != nil {
+				return fmt.Errorf("upsert milestone %s: %w", ms.Key, err)
+			}
+			err = tx.QueryRow(
+				`SELECT id FROM project_milestones WHERE project_slug = ? AND milestone_key = ?`, projectSlug, ms.Key,
+			).Scan(&ms.ID)
+			if err != nil {
+				return fmt.Errorf("get milestone id: %w", err)
+			}
+			ms.ProjectSlug = projectSlug
+			keys = append(keys, ms.Key)
+		}
+
+		notIn := ""
+		if len(milestones) > 0 {
+			notIn = ` AND milestone_key NOT IN (?` + strings.Repeat(", ?", len(milestones)-1) + `)`
+		}
+		_, err := tx.Exec(
+			`DELETE FROM milestone_progress WHERE milestone_id IN
+			 (SELECT id FROM project_milestones WHERE project_slug = ?`+notIn+`)`,
+			keys...,
+		)
+		if err != nil {
+			return fmt.Errorf("delete stale milestone progress: %w", err)
+		}
+		_, err = tx.Exec(`DELETE FROM project_milestones WHERE project_slug = ?`+notIn, keys...)
+		if err != nil {
+			return fmt.Errorf("delete stale milestones: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListMilestones возвращает этапы проекта в порядке ТЗ.
+func (r *Repository) ListMilestones(projectSlug string) ([]Milestone, error) {
+	rows, err := r.db.Query(
+		`SELECT id, project_slug, milestone_key, section, title, lessons, verify_script, order_index
+		 FROM project_milestones WHERE project_slug = ? ORDER BY order_index`,
+		projectSlug,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list milestones: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Milestone
+	for rows.Next() {
+		var ms Milestone
+		var lessons string
+		if err := rows.Scan(&ms.ID, &ms.ProjectSlug, &ms.Key, &ms.Section, &ms.Title, &lessons,
+			&ms.VerifyScript, &ms.OrderIndex); err != nil {
+			return nil, fmt.Errorf("scan milestone: %w", err)
+		}
+		if lessons != "" {
+			ms.LessonSlugs = strings.Split(lessons, ",")
+		}
+		result = append(result, ms)
+	}
+	return result, rows.Err()
+}
+
+// GetMilestone возвращает этап по ID или nil, если его нет.
+func (r *Repository) GetMilestone(id int64) (*Milestone, error) {
+	var ms Milestone
+	var lessons string
+	err := r.db.QueryRow(
+		`SELECT id, project_slug, milestone_key, section, title, lessons, verify_script, order_index
+		 FROM project_milestones WHERE id = ?`, id,
+	).Scan(&ms.ID, &ms.ProjectSlug, &ms.Key, &ms.Section, &ms.Title, &lessons, &ms.VerifyScript, &ms.OrderIndex)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get milestone: %w", err)
+	}
+	if lessons != "" {
+		ms.LessonSlugs = strings.Split(lessons, ",")
+	}
+	return &ms, nil
+}
@@ -47,6 +47,7 @@ type Lesson struct {
 	SourceURL      string
 	BodyMD         string
 	ReadingTimeMin int
+	ContentHash    string // Хеш источника урока при импорте
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 
@@ -70,6 +71,7 @@ type Section struct {
 type Task struct {
 	ID               int64
 	LessonID         int64
+	Key              string // Стабильный ключ внутри урока (id из <Task id="…">)
 	Title            string
 	PromptMD         string
 	Criteria         string // Критерии приёмки
@@ -79,10 +81,46 @@ type Task struct {
 	ExpectedOutput   string // Ожидаемый вывод программы
 	RequiredPatterns string // Паттерны, которые должны быть в коде (разделённые |)
 	Mode             string // auto (встроенная проверка) / manual (выполнение в IDE)
+	VerifyScript     string // Скрипт проверки локального проекта (для manual)
+	Solution         string // Эталонное решение: только для записи, читается через GetTaskSolution
+	HasSolution      bool   // У задания есть эталонное решение
 	Points           int
 	OrderIndex       int
 }
 
+// Уровни сложности capstone-проекта.
+const (
+	DifficultyBeginner     = "beginner"
+	DifficultyIntermediate = "intermediate"
+	DifficultyAdvanced     = "advanced"
+)
+
+// Project — capstone-проект: ТЗ в Markdown, чек-лист которого разбирается в этапы.
+type Project struct {
+	ID          int64
+	Slug        string
+	Title       string
+	Subtitle    string
+	Difficulty  string
+	SpecMD      string
+	LessonSlugs []string // Связанные уроки
+	OrderIndex  int
+}
+
+// DifficultyLabel возвращает название уровня сложности.
+func (p Project) DifficultyLabel() string {
+	switch p.Difficulty {
+	case DifficultyBeginner:
+		return "Начальный"
+	case DifficultyIntermediate:
+		return "Средний"
+	case DifficultyAdvanced:
+		return "Продвинутый"
+	default:
+		return p.Difficulty
+	}
+}
+
 // StructuredLesson — структурированный урок после обработки rewriter.
 type StructuredLesson struct {
 	Title          string
@@ -92,6 +130,12 @@ type StructuredLesson struct {
 	Tasks          []Task
 }
 
+// Prerequisite — ребро графа зависимостей: урок LessonID требует урок RequiresID.
+type Prerequisite struct {
+	LessonID   int64
+	RequiresID int64
+}
+
 // SearchResult — результат поиска.
 type SearchResult struct {
 	LessonID int64
@@ -0,0 +1,117 @@
+package content
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+const projectColumns = `id, slug, title, subtitle, difficulty, spec_md, lessons, order_index`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanProject(s scanner, p *Project) error {
+	var lessons string
+	if err := s.Scan(&p.ID, &p.Slug, &p.Title, &p.Subtitle, &p.Difficulty, &p.SpecMD, &lessons, &p.OrderIndex); err != nil {
+		return err
+	}
+	if lessons != "" {
+		p.LessonSlugs = strings.Split(lessons, ",")
+	}
+	return nil
+}
+
+// UpsertProject создаёт или обновляет проект по slug.
+func (r *Repository) UpsertProject(p *Project) error {
+	_, err := r.db.Exec(
+		`INSERT INTO projects (slug, title, subtitle, difficulty, spec_md, lessons, order_index)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(slug) DO UPDATE SET
+		 title = excluded.title, subtitle = excluded.subtitle, difficulty = excluded.difficulty,
+		 spec_md = excluded.spec_md, lessons = excluded.lessons, order_index = excluded.order_index,
+		 updated_at = CURRENT_TIMESTAMP`,
+		p.Slug, p.Title, p.Subtitle, p.Difficulty, p.SpecMD, strings.Join(p.LessonSlugs, ","), p.OrderIndex,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert project: %w", err)
+	}
+
+	err = r.db.QueryRow(`SELECT id FROM projects WHERE slug = ?`, p.Slug).Scan(&p.ID)
+	if err != nil {
+		return fmt.Errorf("get project id: %w", err)
+	}
+	return nil
+}
+
+// DeleteProjectsExcept удаляет проекты, которых нет в списке, вместе с их этапами и отметками.
+// Возвращает число удалённых проектов.
+func (r *Repository) DeleteProjectsExcept(slugs []string) (int64, error) {
+	var removed int64
+	err := r.withTx("delete projects", func(tx dbtx) error {
+		notIn := ""
+		args := make([]interface{}, len(slugs))
+		for i, slug := range slugs {
+			args[i] = slug
+		}
+		if len(slugs) > 0 {
+			notIn = ` WHERE slug NOT IN (?` + strings.Repeat(", ?", len(slugs)-1) + `)`
+		}
+
+		_, err := tx.Exec(
+			`DELETE FROM milestone_progress WHERE milestone_id IN
+			 (SELECT pm.id FROM project_milestones pm WHERE pm.project_slug IN (SELECT slug FROM projects`+notIn+`))`,
+			args...,
+		)
+		if err != nil {
+			return fmt.Errorf("delete stale milestone progress: %w", err)
+		}
+		_, err = tx.Exec(
+			`DELETE FROM project_milestones WHERE project_slug IN (SELECT slug FROM projects`+notIn+`)`,
+			args...,
+		)
+		if err != nil {
+			return fmt.Errorf("delete stale milestones: %w", err)
+		}
+		result, err := tx.Exec(`DELETE FROM projects`+notIn, args...)
+		if err != nil {
+			return fmt.Errorf("delete stale projects: %w", err)
+		}
+		removed, err = result.RowsAffected()
+		return err
+	})
+	return removed, err
+}
+
+// ListProjects возвращает все проекты по порядку.
+func (r *Repository) ListProjects() ([]Project, error) {
+	rows, err := r.db.Query(`SELECT ` + projectColumns + ` FROM projects ORDER BY order_index, title`)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projects []Project
+	for rows.Next() {
+		var p Project
+		if err := scanProject(rows, &p); err != nil {
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// GetProjectBySlug возвращает проект по slug или nil, если его нет.
+func (r *Repository) GetProjectBySlug(slug string) (*Project, error) {
+	p := &Project{}
+	err := scanProject(r.db.QueryRow(`SELECT `+projectColumns+` FROM projects WHERE slug = ?`, slug), p)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get project by slug: %w", err)
+	}
+	return p, nil
+}
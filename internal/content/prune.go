@@ -0,0 +1,229 @@
+package content
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContentSet — slug'и курсов, модулей и уроков.
+type ContentSet struct {
+	Courses []string
+	Modules []string
+	Lessons []string
+}
+
+// PruneSet — контент, выбранный для удаления, вместе с объёмом затронутого прогресса.
+type PruneSet struct {
+	Courses []Course
+	Modules []Module
+	Lessons []PruneLesson
+}
+
+// PruneLesson — удаляемый урок.
+type PruneLesson struct {
+	ID           int64
+	ModuleID     int64
+	Slug         string
+	Title        string
+	ProgressRows int // записи пользователя по уроку и его заданиям
+}
+
+// Empty сообщает, что удалять нечего.
+func (s *PruneSet) Empty() bool {
+	return len(s.Courses) == 0 && len(s.Modules) == 0 && len(s.Lessons) == 0
+}
+
+// ProgressRows возвращает общее число удаляемых записей пользователя.
+func (s *PruneSet) ProgressRows() int {
+	n := 0
+	for _, l := range s.Lessons {
+		n += l.ProgressRows
+	}
+	return n
+}
+
+// FindStale выбирает контент, убранный из источника keep: модули его курсов и уроки его модулей,
+// которых в keep нет. Контент других источников (паков, сайта, демо) не рассматривается, поэтому
+// курс, убранный из источника целиком, так не найти — его удаляют через FindContent.
+func (r *Repository) FindStale(keep ContentSet) (*PruneSet, error) {
+	courses, modules, lessons, err := r.pruneCandidates()
+	if err != nil {
+		return nil, err
+	}
+
+	keepCourses, keepModules, keepLessons := slugSet(keep.Courses), slugSet(keep.Modules), slugSet(keep.Lessons)
+	courseIDs := make(map[int64]bool)
+	for _, c := range courses {
+		if keepCourses[c.Slug] {
+			courseIDs[c.ID] = true
+		}
+	}
+
+	set := &PruneSet{}
+	moduleIDs := make(map[int64]bool)
+	for _, m := range modules {
+		if !keepModules[m.Slug] && !courseIDs[m.CourseID] {
+			continue
+		}
+		moduleIDs[m.ID] = true
+		if !keepModules[m.Slug] {
+			set.Modules = append(set.Modules, m)
+		}
+	}
+	for _, l := range lessons {
+		if moduleIDs[l.ModuleID] && !keepLessons[l.Slug] {
+			set.Lessons = append(set.Lessons, l)
+		}
+	}
+	return set, nil
+}
+
+// FindContent выбирает контент по slug'ам вместе с вложенным: модулями курсов и уроками модулей.
+func (r *Repository) FindContent(target ContentSet) (*PruneSet, error) {
+	courses, modules, lessons, err := r.pruneCandidates()
+	if err != nil {
+		return nil, err
+	}
+
+	set := &PruneSet{}
+	courseIDs := make(map[int64]bool)
+	moduleIDs := make(map[int64]bool)
+	targetCourses, targetModules, targetLessons := slugSet(target.Courses), slugSet(target.Modules), slugSet(target.Lessons)
+	for _, c := range courses {
+		if targetCourses[c.Slug] {
+			set.Courses = append(set.Courses, c)
+			courseIDs[c.ID] = true
+		}
+	}
+	for _, m := range modules {
+		if targetModules[m.Slug] || courseIDs[m.CourseID] {
+			set.Modules = append(set.Modules, m)
+			moduleIDs[m.ID] = true
+		}
+	}
+	for _, l := range lessons {
+		if targetLessons[l.Slug] || moduleIDs[l.ModuleID] {
+			set.Lessons = append(set.Lessons, l)
+		}
+	}
+	return set, nil
+}
+
+// pruneCandidates читает все курсы, модули и уроки с объёмом прогресса по каждому уроку.
+func (r *Repository) pruneCandidates() ([]Course, []Module, []PruneLesson, error) {
+	courses, err := r.ListCourses()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	modules, err := r.ListModules()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT l.id, l.module_id, l.slug, l.title, ` + lessonProgressRows + `
+		        + (SELECT COALESCE(SUM(` + taskProgressRows + `), 0) FROM tasks t WHERE t.lesson_id = l.id)
+		 FROM lessons l
+		 ORDER BY l.module_id, l.order_index`,
+	)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("list lessons for prune: %w", err)
+	}
+	defer rows.Close()
+
+	var lessons []PruneLesson
+	for rows.Next() {
+		var l PruneLesson
+		if err := rows.Scan(&l.ID, &l.ModuleID, &l.Slug, &l.Title, &l.ProgressRows); err != nil {
+			return nil, nil, nil, fmt.Errorf("scan lesson for prune: %w", err)
+		}
+		lessons = append(lessons, l)
+	}
+	return courses, modules, lessons, rows.Err()
+}
+
+// Prune удаляет выбранный контент в одной транзакции вместе с секциями, заданиями
+// и всеми записями пользователя по ним. Связанные записи удаляются явно, а не каскадом:
+// внешние ключи в соединении не включены. Курс, в котором остались модули, не удаляется.
+func (r *Repository) Prune(set *PruneSet) error {
+	lessonIDs := make([]interface{}, 0, len(set.Lessons))
+	for _, l := range set.Lessons {
+		lessonIDs = append(lessonIDs, l.ID)
+	}
+	moduleIDs := make([]interface{}, 0, len(set.Modules))
+	for _, m := range set.Modules {
+		moduleIDs = append(moduleIDs, m.ID)
+	}
+	courseIDs := make([]interface{}, 0, len(set.Courses))
+	for _, c := range set.Courses {
+		courseIDs = append(courseIDs, c.ID)
+	}
+
+	return r.withTx("prune content", func(tx dbtx) error {
+		if len(lessonIDs) > 0 {
+			in := placeholders(len(lessonIDs))
+			tasks := `(SELECT id FROM tasks WHERE lesson_id IN ` + in + `)`
+			var queries []string
+			for _, table := range taskProgressTables {
+				queries = append(queries, `DELETE FROM `+table+` WHERE task_id IN `+tasks)
+			}
+			queries = append(queries,
+				`DELETE FROM bookmarks WHERE lesson_id IN `+in,
+				`DELETE FROM tasks WHERE lesson_id IN `+in,
+				`DELETE FROM lesson_sections WHERE lesson_id IN `+in,
+				`DELETE FROM lesson_prerequisites WHERE lesson_id IN `+in,
+				`DELETE FROM learning_path_lessons WHERE lesson_id IN `+in,
+				`DELETE FROM progress WHERE lesson_id IN `+in,
+				`DELETE FROM notes WHERE lesson_id IN `+in,
+				`DELETE FROM note_revisions WHERE lesson_id IN `+in,
+				`DELETE FROM highlights WHERE lesson_id IN `+in,
+				`DELETE FROM lesson_time WHERE lesson_id IN `+in,
+				`DELETE FROM lessons WHERE id IN `+in,
+			)
+			for _, q := range queries {
+				if _, err := tx.Exec(q, lessonIDs...); err != nil {
+					return fmt.Errorf("prune lessons: %w", err)
+				}
+			}
+		}
+
+		if len(moduleIDs) > 0 {
+			_, err := tx.Exec(
+				`DELETE FROM modules WHERE id IN `+placeholders(len(moduleIDs))+`
+				 AND NOT EXISTS (SELECT 1 FROM lessons WHERE module_id = modules.id)`,
+				moduleIDs...,
+			)
+			if err != nil {
+				return fmt.Errorf("prune modules: %w", err)
+			}
+		}
+
+		if len(courseIDs) > 0 {
+			_, err := tx.Exec(
+				`DELETE FROM courses WHERE id IN `+placeholders(len(courseIDs))+`
+				 AND NOT EXISTS (SELECT 1 FROM modules WHERE course_id = courses.id)`,
+				courseIDs...,
+			)
+			if err != nil {
+				return fmt.Errorf("prune courses: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// taskProgressTables — таблицы с записями пользователя по заданию (task_id). Внешние ключи
+// в соединении не включены, поэтому при удалении задания эти записи удаляются явно.
+var taskProgressTables = []string{"submissions", "task_completions", "task_reviews", "task_time", "solution_reveals", "bookmarks"}
+
+func placeholders(n int) string {
+	return "(?" + strings.Repeat(", ?", n-1) + ")"
+}
+
+func slugSet(slugs []string) map[string]bool {
+	set := make(map[string]bool, len(slugs))
+	for _, s := range slugs {
+		set[s] = true
+	}
+	return set
+}
@@ -3,12 +3,20 @@ package content
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
+// dbtx — общее подмножество *sql.DB и *sql.Tx: методы репозитория работают с любым из них.
+type dbtx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 // Repository — репозиторий для работы с контентом.
 type Repository struct {
-	db *sql.DB
+	db dbtx
 }
 
 // NewRepository создаёт новый репозиторий.
@@ -16,6 +24,40 @@ func NewRepository(db *sql.DB) *Repository {
 	return &Repository{db: db}
 }
 
+// InTx выполняет fn с репозиторием, все запросы которого идут в одной транзакции.
+// Если fn возвращает ошибку, изменения откатываются. Внутри транзакции вызов
+// просто передаёт текущий репозиторий — вложенных транзакций нет.
+func (r *Repository) InTx(fn func(*Repository) error) error {
+	return r.withTx("content", func(tx dbtx) error {
+		if tx == r.db {
+			return fn(r)
+		}
+		return fn(&Repository{db: tx})
+	})
+}
+
+// withTx выполняет fn в транзакции: новой — для *sql.DB, текущей — внутри InTx.
+func (r *Repository) withTx(name string, fn func(tx dbtx) error) error {
+	db, ok := r.db.(*sql.DB)
+	if !ok {
+		return fn(r.db)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin %s: %w", name, err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit %s: %w", name, err)
+	}
+	return nil
+}
+
 // --- Courses ---
 
 // CreateCourse создаёт или обновляет курс.
@@ -164,8 +206,8 @@ func (r *Repository) ListModulesByCourseID(courseID int64) ([]Module, error) {
 // CreateLesson создаёт новый урок.
 func (r *Repository) CreateLesson(l *Lesson) error {
 	_, err := r.db.Exec(
-		`INSERT INTO lessons (module_id, slug, title, order_index, source_url, body_md, reading_time_min)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		`INSERT INTO lessons (module_id, slug, title, order_index, source_url, body_md, reading_time_min, content_hash)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		 ON CONFLICT(slug) DO UPDATE SET 
 		   module_id = excluded.module_id,
 		   title = excluded.title, 
@@ -173,8 +215,9 @@ func (r *Repository) CreateLesson(l *Lesson) error {
 		   source_url = excluded.source_url,
 		   body_md = excluded.body_md,
 		   reading_time_min = excluded.reading_time_min,
+		   content_hash = excluded.content_hash,
 		   updated_at = CURRENT_TIMESTAMP`,
-		l.ModuleID, l.Slug, l.Title, l.OrderIndex, l.SourceURL, l.BodyMD, l.ReadingTimeMin,
+		l.ModuleID, l.Slug, l.Title, l.OrderIndex, l.SourceURL, l.BodyMD, l.ReadingTimeMin, l.ContentHash,
 	)
 	if err != nil {
 		return fmt.Errorf("insert lesson: %w", err)
@@ -189,6 +232,33 @@ func (r *Repository) CreateLesson(l *Lesson) error {
 	return nil
 }
 
+// GetLessonHash возвращает хеш источника урока; found = false, если урока ещё нет.
+func (r *Repository) GetLessonHash(slug string) (hash string, found bool, err error) {
+	err = r.db.QueryRow(`SELECT content_hash FROM lessons WHERE slug = ?`, slug).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("get lesson hash: %w", err)
+	}
+	return hash, true, nil
+}
+
+// GetLessonCourseID возвращает ID курса урока (0 — модуль без курса); found = false, если урока ещё нет.
+func (r *Repository) GetLessonCourseID(slug string) (courseID int64, found bool, err error) {
+	err = r.db.QueryRow(
+		`SELECT COALESCE(m.course_id, 0) FROM lessons l JOIN modules m ON m.id = l.module_id WHERE l.slug = ?`,
+		slug,
+	).Scan(&courseID)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("get lesson course: %w", err)
+	}
+	return courseID, true, nil
+}
+
 // GetLessonBySlug возвращает урок по slug с секциями и заданиями.
 func (r *Repository) GetLessonBySlug(slug string) (*Lesson, error) {
 	l := &Lesson{Module: &Module{}}
@@ -357,40 +427,129 @@ func (r *Repository) GetSectionsByLessonID(lessonID int64) ([]Section, error) {
 
 // --- Tasks ---
 
-// CreateTask создаёт задание.
+// CreateTask создаёт или обновляет задание урока.
+// Задание идентифицируется парой (урок, ключ), поэтому при повторном импорте
+// его ID, а вместе с ним отправки и отметки о решении, сохраняются.
 func (r *Repository) CreateTask(t *Task) error {
 	if strings.TrimSpace(t.Mode) == "" {
 		t.Mode = "auto"
 	}
-	result, err := r.db.Exec(
-		`INSERT INTO tasks (lesson_id, title, prompt_md, criteria, hints, starter_code, tests_go, expected_output, required_patterns, mode, points, order_index)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		t.LessonID, t.Title, t.PromptMD, t.Criteria, t.Hints, t.StarterCode, t.TestsGo, t.ExpectedOutput, t.RequiredPatterns, t.Mode, t.Points, t.OrderIndex,
+	if strings.TrimSpace(t.Key) == "" {
+		t.Key = strconv.Itoa(t.OrderIndex + 1)
+	}
+	_, err := r.db.Exec(
+		`INSERT INTO tasks (lesson_id, task_key, title, prompt_md, criteria, hints, starter_code, tests_go, expected_output, required_patterns, mode, verify_script, solution, points, order_index)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(lesson_id, task_key) DO UPDATE SET
+		   title = excluded.title,
+		   prompt_md = excluded.prompt_md,
+		   criteria = excluded.criteria,
+		   hints = excluded.hints,
+		   starter_code = excluded.starter_code,
+		   tests_go = excluded.tests_go,
+		   expected_output = excluded.expected_output,
+		   required_patterns = excluded.required_patterns,
+		   mode = excluded.mode,
+		   verify_script = excluded.verify_script,
+		   solution = excluded.solution,
+		   points = excluded.points,
+		   order_index = excluded.order_index`,
+		t.LessonID, t.Key, t.Title, t.PromptMD, t.Criteria, t.Hints, t.StarterCode, t.TestsGo, t.ExpectedOutput, t.RequiredPatterns, t.Mode, t.VerifyScript, t.Solution, t.Points, t.OrderIndex,
 	)
 	if err != nil {
 		return fmt.Errorf("insert task: %w", err)
 	}
 
-	t.ID, _ = result.LastInsertId()
+	// Всегда получаем ID по ключу (надёжнее чем LastInsertId при ON CONFLICT)
+	err = r.db.QueryRow("SELECT id FROM tasks WHERE lesson_id = ? AND task_key = ?", t.LessonID, t.Key).Scan(&t.ID)
+	if err != nil {
+		return fmt.Errorf("get task id: %w", err)
+	}
+
 	return nil
 }
 
-// DeleteTasksByLessonID удаляет все задания урока.
+// DeleteTasksByLessonID удаляет все задания урока вместе с записями пользователя по ним.
 func (r *Repository) DeleteTasksByLessonID(lessonID int64) error {
-	_, err := r.db.Exec(`DELETE FROM tasks WHERE lesson_id = ?`, lessonID)
+	_, err := r.DeleteTasksExcept(lessonID, nil)
 	return err
 }
 
+// DeleteTasksExcept удаляет задания урока, ключей которых нет в keys
+// (задания, убранные из исходного файла), вместе с отправками, отметками о решении
+// и другими записями пользователя по ним. Возвращает число удалённых заданий.
+func (r *Repository) DeleteTasksExcept(lessonID int64, keys []string) (int64, error) {
+	where := `lesson_id = ?`
+	args := []any{lessonID}
+	if len(keys) > 0 {
+		where += ` AND task_key NOT IN ` + placeholders(len(keys))
+		for _, k := range keys {
+			args = append(args, k)
+		}
+	}
+
+	var n int64
+	err := r.withTx("delete stale tasks", func(tx dbtx) error {
+		// Внешние ключи не включены — каскада нет, зависимые записи удаляем сами
+		for _, table := range taskProgressTables {
+			if _, err := tx.Exec(`DELETE FROM `+table+` WHERE task_id IN (SELECT id FROM tasks WHERE `+where+`)`, args...); err != nil {
+				return fmt.Errorf("delete stale task %s: %w", table, err)
+			}
+		}
+		res, err := tx.Exec(`DELETE FROM tasks WHERE `+where, args...)
+		if err != nil {
+			return fmt.Errorf("delete stale tasks: %w", err)
+		}
+		n, _ = res.RowsAffected()
+		return nil
+	})
+	return n, err
+}
+
+// RecalculateLessonPoints пересчитывает очки и флаг практики в прогрессе урока по решениям
+// его текущих заданий — после того как импорт удалил или перенёс задания. Урок, у которого
+// решённых заданий не осталось, получает 0 очков.
+func (r *Repository) RecalculateLessonPoints(lessonID int64) error {
+	const solved = `FROM task_completions c JOIN tasks t ON t.id = c.task_id WHERE t.lesson_id = progress.lesson_id`
+	_, err := r.db.Exec(
+		`UPDATE progress SET
+		   points_earned = (SELECT COALESCE(SUM(c.points), 0) `+solved+`),
+		   practice_done = EXISTS (SELECT 1 `+solved+`),
+		   updated_at = CURRENT_TIMESTAMP
+		 WHERE lesson_id = ?
+		   AND (points_earned != (SELECT COALESCE(SUM(c.points), 0) `+solved+`)
+		        OR practice_done != EXISTS (SELECT 1 `+solved+`))`,
+		lessonID,
+	)
+	if err != nil {
+		return fmt.Errorf("recalculate lesson points: %w", err)
+	}
+	return nil
+}
+
+// RenameTaskKey меняет ключ задания урока, сохраняя его ID и связанные данные.
+func (r *Repository) RenameTaskKey(lessonID int64, oldKey, newKey string) error {
+	_, err := r.db.Exec(
+		`UPDATE tasks SET task_key = ? WHERE lesson_id = ? AND task_key = ?`,
+		newKey, lessonID, oldKey,
+	)
+	if err != nil {
+		return fmt.Errorf("rename task key: %w", err)
+	}
+	return nil
+}
+
 // GetTasksByLessonID возвращает задания урока.
 func (r *Repository) GetTasksByLessonID(lessonID int64) ([]Task, error) {
 	rows, err := r.db.Query(
-		`SELECT id, lesson_id, title, prompt_md, 
+		`SELECT id, lesson_id, task_key, title, prompt_md, 
 		        COALESCE(criteria, '') as criteria,
 		        COALESCE(hints, '') as hints,
 		        starter_code, tests_go, 
 		        COALESCE(expected_output, '') as expected_output,
 		        COALESCE(required_patterns, '') as required_patterns,
 		        COALESCE(mode, 'auto') as mode,
+		        verify_script, solution != '',
 		        points, order_index
 		 FROM tasks WHERE lesson_id = ? ORDER BY order_index`,
 		lessonID,
@@ -403,7 +562,7 @@ func (r *Repository) GetTasksByLessonID(lessonID int64) ([]Task, error) {
 	var tasks []Task
 	for rows.Next() {
 		var t Task
-		if err := rows.Scan(&t.ID, &t.LessonID, &t.Title, &t.PromptMD, &t.Criteria, &t.Hints, &t.StarterCode, &t.TestsGo, &t.ExpectedOutput, &t.RequiredPatterns, &t.Mode, &t.Points, &t.OrderIndex); err != nil {
+		if err := rows.Scan(&t.ID, &t.LessonID, &t.Key, &t.Title, &t.PromptMD, &t.Criteria, &t.Hints, &t.StarterCode, &t.TestsGo, &t.ExpectedOutput, &t.RequiredPatterns, &t.Mode, &t.VerifyScript, &t.HasSolution, &t.Points, &t.OrderIndex); err != nil {
 			return nil, fmt.Errorf("scan task: %w", err)
 		}
 		tasks = append(tasks, t)
@@ -416,17 +575,18 @@ func (r *Repository) GetTasksByLessonID(lessonID int64) ([]Task, error) {
 func (r *Repository) GetTaskByID(id int64) (*Task, error) {
 	t := &Task{}
 	err := r.db.QueryRow(
-		`SELECT id, lesson_id, title, prompt_md, 
+		`SELECT id, lesson_id, task_key, title, prompt_md, 
 		        COALESCE(criteria, '') as criteria,
 		        COALESCE(hints, '') as hints,
 		        starter_code, tests_go, 
 		        COALESCE(expected_output, '') as expected_output, 
 		        COALESCE(required_patterns, '') as required_patterns, 
 		        COALESCE(mode, 'auto') as mode,
+		        verify_script, solution != '',
 		        points, order_index
 		 FROM tasks WHERE id = ?`,
 		id,
-	).Scan(&t.ID, &t.LessonID, &t.Title, &t.PromptMD, &t.Criteria, &t.Hints, &t.StarterCode, &t.TestsGo, &t.ExpectedOutput, &t.RequiredPatterns, &t.Mode, &t.Points, &t.OrderIndex)
+	).Scan(&t.ID, &t.LessonID, &t.Key, &t.Title, &t.PromptMD, &t.Criteria, &t.Hints, &t.StarterCode, &t.TestsGo, &t.ExpectedOutput, &t.RequiredPatterns, &t.Mode, &t.VerifyScript, &t.HasSolution, &t.Points, &t.OrderIndex)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -436,6 +596,117 @@ func (r *Repository) GetTaskByID(id int64) (*Task, error) {
 	return t, nil
 }
 
+// GetTaskSolution возвращает эталонное решение задания ("" — решения нет).
+// Решение не входит в выборки заданий, чтобы не попасть в страницу урока.
+func (r *Repository) GetTaskSolution(taskID int64) (string, error) {
+	var solution string
+	err := r.db.QueryRow(`SELECT solution FROM tasks WHERE id = ?`, taskID).Scan(&solution)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get task solution: %w", err)
+	}
+	return solution, nil
+}
+
+// --- Prerequisites ---
+
+// SetLessonPrerequisites заменяет список пререквизитов урока (slug'и требуемых уроков).
+func (r *Repository) SetLessonPrerequisites(lessonID int64, slugs []string) error {
+	return r.withTx("set prerequisites", func(tx dbtx) error {
+		if _, err := tx.Exec(`DELETE FROM lesson_prerequisites WHERE lesson_id = ?`, lessonID); err != nil {
+			return fmt.Errorf("delete prerequisites: %w", err)
+		}
+		for _, slug := range slugs {
+			slug = strings.TrimSpace(slug)
+			if slug == "" {
+				continue
+			}
+			_, err := tx.Exec(
+				`INSERT OR IGNORE INTO lesson_prerequisites (lesson_id, requires_slug) VALUES (?, ?)`,
+				lessonID, slug,
+			)
+			if err != nil {
+				return fmt.Errorf("insert prerequisite: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetLessonPrerequisites возвращает slug'и уроков, которые требует урок, в порядке requires.
+func (r *Repository) GetLessonPrerequisites(lessonID int64) ([]string, error) {
+	rows, err := r.db.Query(
+		`SELECT requires_slug FROM lesson_prerequisites WHERE lesson_id = ? ORDER BY rowid`,
+		lessonID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get prerequisites: %w", err)
+	}
+	defer rows.Close()
+
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, fmt.Errorf("scan prerequisite: %w", err)
+		}
+		slugs = append(slugs, slug)
+	}
+	return slugs, rows.Err()
+}
+
+// ListPrerequisites возвращает все рёбра графа зависимостей между существующими уроками.
+func (r *Repository) ListPrerequisites() ([]Prerequisite, error) {
+	rows, err := r.db.Query(
+		`SELECT p.lesson_id, l.id
+		 FROM lesson_prerequisites p
+		 JOIN lessons l ON l.slug = p.requires_slug
+		 WHERE l.id != p.lesson_id
+		 ORDER BY p.lesson_id, l.id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list prerequisites: %w", err)
+	}
+	defer rows.Close()
+
+	var edges []Prerequisite
+	for rows.Next() {
+		var e Prerequisite
+		if err := rows.Scan(&e.LessonID, &e.RequiresID); err != nil {
+			return nil, fmt.Errorf("scan prerequisite: %w", err)
+		}
+		edges = append(edges, e)
+	}
+	return edges, rows.Err()
+}
+
+// ListUnresolvedPrerequisites возвращает ссылки на несуществующие уроки: «slug урока → slug требования».
+func (r *Repository) ListUnresolvedPrerequisites() ([]string, error) {
+	rows, err := r.db.Query(
+		`SELECT l.slug, p.requires_slug
+		 FROM lesson_prerequisites p
+		 JOIN lessons l ON l.id = p.lesson_id
+		 WHERE NOT EXISTS (SELECT 1 FROM lessons r WHERE r.slug = p.requires_slug)
+		 ORDER BY l.slug, p.requires_slug`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list unresolved prerequisites: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []string
+	for rows.Next() {
+		var lesson, required string
+		if err := rows.Scan(&lesson, &required); err != nil {
+			return nil, fmt.Errorf("scan unresolved prerequisite: %w", err)
+		}
+		refs = append(refs, lesson+" → "+required)
+	}
+	return refs, rows.Err()
+}
+
 // --- Search ---
 
 // Search выполняет полнотекстовый поиск по урокам.
@@ -0,0 +1,122 @@
+package content
+
+import "fmt"
+
+// Snapshot — состояние контента в БД для сравнения до и после импорта:
+// уроки, их секции и задания вместе с числом связанных с ними записей прогресса.
+type Snapshot struct {
+	Lessons map[string]*LessonSnapshot // по slug
+}
+
+// LessonSnapshot — урок в снимке.
+type LessonSnapshot struct {
+	ID           int64
+	Slug         string
+	Title        string
+	ModuleSlug   string
+	OrderIndex   int
+	ContentHash  string
+	Sections     []SectionSnapshot // по порядку
+	Tasks        map[int64]*TaskSnapshot
+	ProgressRows int // progress, заметки, выделения, закладки, время
+}
+
+// SectionSnapshot — секция урока в снимке.
+type SectionSnapshot struct {
+	Kind   SectionKind
+	Title  string
+	BodyMD string
+}
+
+// TaskSnapshot — задание в снимке.
+type TaskSnapshot struct {
+	ID           int64
+	Key          string
+	Title        string
+	Mode         string
+	Points       int
+	ProgressRows int // отправки, решения, сдачи на проверку, закладки, время, открытые решения
+}
+
+// lessonProgressRows — число записей пользователя, привязанных к уроку l (без его заданий).
+const lessonProgressRows = `(
+	(SELECT COUNT(*) FROM progress WHERE lesson_id = l.id)
+	+ (SELECT COUNT(*) FROM notes WHERE lesson_id = l.id)
+	+ (SELECT COUNT(*) FROM highlights WHERE lesson_id = l.id)
+	+ (SELECT COUNT(*) FROM bookmarks WHERE lesson_id = l.id AND task_id IS NULL)
+	+ (SELECT COUNT(*) FROM lesson_time WHERE lesson_id = l.id))`
+
+// taskProgressRows — число записей пользователя, привязанных к заданию t.
+const taskProgressRows = `(
+	(SELECT COUNT(*) FROM submissions WHERE task_id = t.id)
+	+ (SELECT COUNT(*) FROM task_completions WHERE task_id = t.id)
+	+ (SELECT COUNT(*) FROM task_reviews WHERE task_id = t.id)
+	+ (SELECT COUNT(*) FROM bookmarks WHERE task_id = t.id)
+	+ (SELECT COUNT(*) FROM task_time WHERE task_id = t.id)
+	+ (SELECT COUNT(*) FROM solution_reveals WHERE task_id = t.id))`
+
+// Snapshot читает текущее состояние контента.
+func (r *Repository) Snapshot() (*Snapshot, error) {
+	snap := &Snapshot{Lessons: make(map[string]*LessonSnapshot)}
+	byID := make(map[int64]*LessonSnapshot)
+
+	rows, err := r.db.Query(
+		`SELECT l.id, l.slug, l.title, m.slug, l.order_index, l.content_hash, ` + lessonProgressRows + `
+		 FROM lessons l
+		 JOIN modules m ON m.id = l.module_id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot lessons: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		l := &LessonSnapshot{Tasks: make(map[int64]*TaskSnapshot)}
+		if err := rows.Scan(&l.ID, &l.Slug, &l.Title, &l.ModuleSlug, &l.OrderIndex, &l.ContentHash, &l.ProgressRows); err != nil {
+			return nil, fmt.Errorf("scan lesson snapshot: %w", err)
+		}
+		snap.Lessons[l.Slug] = l
+		byID[l.ID] = l
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(`SELECT lesson_id, kind, title, body_md FROM lesson_sections ORDER BY lesson_id, order_index`)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot sections: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var lessonID int64
+		var s SectionSnapshot
+		if err := rows.Scan(&lessonID, &s.Kind, &s.Title, &s.BodyMD); err != nil {
+			return nil, fmt.Errorf("scan section snapshot: %w", err)
+		}
+		if l := byID[lessonID]; l != nil {
+			l.Sections = append(l.Sections, s)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(
+		`SELECT t.id, t.lesson_id, t.task_key, t.title, COALESCE(t.mode, 'auto'), t.points, ` + taskProgressRows + `
+		 FROM tasks t`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot tasks: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var lessonID int64
+		t := &TaskSnapshot{}
+		if err := rows.Scan(&t.ID, &lessonID, &t.Key, &t.Title, &t.Mode, &t.Points, &t.ProgressRows); err != nil {
+			return nil, fmt.Errorf("scan task snapshot: %w", err)
+		}
+		if l := byID[lessonID]; l != nil {
+			l.Tasks[t.ID] = t
+		}
+	}
+	return snap, rows.Err()
+}
@@ -0,0 +1,9 @@
+-- Стабильный ключ задания внутри урока (id из <Task id="…">).
+-- Вместе со slug урока он образует внешний идентификатор задания, который переживает
+-- повторный импорт контента, поэтому отправки и решённые задания больше не теряются.
+ALTER TABLE tasks ADD COLUMN task_key TEXT NOT NULL DEFAULT '';
+
+-- Для уже импортированных заданий ключом становится порядковый номер (совпадает с id="1", id="2", ...)
+UPDATE tasks SET task_key = CAST(order_index + 1 AS TEXT) WHERE task_key = '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_lesson_key ON tasks(lesson_id, task_key);
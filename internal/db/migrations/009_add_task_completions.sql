@@ -0,0 +1,28 @@
+-- Решённые задания: одна строка на задание, очки фиксируются на момент первого решения.
+-- progress.points_earned теперь вычисляется из этой таблицы, а не накапливается.
+CREATE TABLE IF NOT EXISTS task_completions (
+    task_id INTEGER PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+    points INTEGER NOT NULL DEFAULT 0,
+    solved_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Заполняем по истории успешных отправок
+INSERT OR IGNORE INTO task_completions (task_id, points, solved_at)
+SELECT s.task_id, t.points, MIN(s.created_at)
+FROM submissions s
+JOIN tasks t ON t.id = s.task_id
+WHERE s.status = 'success'
+GROUP BY s.task_id;
+
+-- Пересчитываем накопленные очки по урокам
+UPDATE progress SET
+    points_earned = COALESCE((
+        SELECT SUM(c.points) FROM task_completions c
+        JOIN tasks t ON t.id = c.task_id
+        WHERE t.lesson_id = progress.lesson_id
+    ), 0),
+    practice_done = EXISTS (
+        SELECT 1 FROM task_completions c
+        JOIN tasks t ON t.id = c.task_id
+        WHERE t.lesson_id = progress.lesson_id
+    );
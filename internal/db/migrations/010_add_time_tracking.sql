@@ -0,0 +1,14 @@
+-- Фактически затраченное время: копится из heartbeat-событий, пока вкладка урока видима.
+CREATE TABLE IF NOT EXISTS lesson_time (
+    lesson_id INTEGER PRIMARY KEY REFERENCES lessons(id) ON DELETE CASCADE,
+    active_sec INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Время по заданию копится только до первого успешного решения,
+-- поэтому active_sec решённого задания — это время до первого правильного ответа.
+CREATE TABLE IF NOT EXISTS task_time (
+    task_id INTEGER PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+    active_sec INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
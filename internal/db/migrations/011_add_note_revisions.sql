@@ -0,0 +1,33 @@
+-- История заметок: снимки прежних версий, из которых заметку можно восстановить
+CREATE TABLE IF NOT EXISTS note_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    lesson_id INTEGER NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    note_md TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_revisions_lesson ON note_revisions(lesson_id, created_at);
+
+-- Полнотекстовый поиск по заметкам (rowid = lesson_id)
+CREATE VIRTUAL TABLE IF NOT EXISTS notes_fts USING fts5(
+    note_md,
+    content='notes',
+    content_rowid='lesson_id'
+);
+
+-- Триггеры для синхронизации FTS
+CREATE TRIGGER IF NOT EXISTS notes_ai AFTER INSERT ON notes BEGIN
+    INSERT INTO notes_fts(rowid, note_md) VALUES (new.lesson_id, new.note_md);
+END;
+
+CREATE TRIGGER IF NOT EXISTS notes_ad AFTER DELETE ON notes BEGIN
+    INSERT INTO notes_fts(notes_fts, rowid, note_md) VALUES('delete', old.lesson_id, old.note_md);
+END;
+
+CREATE TRIGGER IF NOT EXISTS notes_au AFTER UPDATE ON notes BEGIN
+    INSERT INTO notes_fts(notes_fts, rowid, note_md) VALUES('delete', old.lesson_id, old.note_md);
+    INSERT INTO notes_fts(rowid, note_md) VALUES (new.lesson_id, new.note_md);
+END;
+
+-- Индексируем уже существующие заметки
+INSERT INTO notes_fts(notes_fts) VALUES('rebuild');
@@ -0,0 +1,20 @@
+-- Выделения и комментарии к тексту урока.
+-- Секции пересоздаются при импорте, поэтому якорь — не id секции, а (вид, порядок)
+-- плюс цитата с контекстом: по ним выделение заново находится в обновлённом тексте.
+CREATE TABLE IF NOT EXISTS highlights (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    lesson_id INTEGER NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    section_kind TEXT NOT NULL DEFAULT '',
+    section_index INTEGER NOT NULL DEFAULT 0,
+    start_offset INTEGER NOT NULL DEFAULT 0,
+    quote TEXT NOT NULL,
+    prefix TEXT NOT NULL DEFAULT '',
+    suffix TEXT NOT NULL DEFAULT '',
+    color TEXT NOT NULL DEFAULT 'yellow',
+    comment TEXT NOT NULL DEFAULT '',
+    orphaned INTEGER NOT NULL DEFAULT 0,  -- цитата не найдена в текущей версии урока
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_highlights_lesson ON highlights(lesson_id);
@@ -0,0 +1,27 @@
+-- Закладки на уроки и задания (task_id = NULL — закладка на весь урок)
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    lesson_id INTEGER NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    task_id INTEGER REFERENCES tasks(id) ON DELETE CASCADE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmarks_target ON bookmarks(lesson_id, COALESCE(task_id, 0));
+
+-- Пользовательские учебные пути: свой набор уроков в своём порядке
+CREATE TABLE IF NOT EXISTS learning_paths (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS learning_path_lessons (
+    path_id INTEGER NOT NULL REFERENCES learning_paths(id) ON DELETE CASCADE,
+    lesson_id INTEGER NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (path_id, lesson_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_learning_path_lessons_order ON learning_path_lessons(path_id, position);
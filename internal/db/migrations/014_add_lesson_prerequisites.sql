@@ -0,0 +1,9 @@
+-- Пререквизиты уроков из `requires:` в <Meta>.
+-- Храним slug, а не id: требуемый урок может импортироваться позже зависимого.
+CREATE TABLE IF NOT EXISTS lesson_prerequisites (
+    lesson_id INTEGER NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    requires_slug TEXT NOT NULL,
+    PRIMARY KEY (lesson_id, requires_slug)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lesson_prerequisites_slug ON lesson_prerequisites(requires_slug);
@@ -0,0 +1,3 @@
+-- Урок засчитан по вступительному тесту (placement), а не пройден вручную.
+-- Такие уроки считаются пройденными, но очки за их задания не начисляются.
+ALTER TABLE progress ADD COLUMN placement INTEGER NOT NULL DEFAULT 0;
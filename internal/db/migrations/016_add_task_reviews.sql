@@ -0,0 +1,17 @@
+-- Сдачи manual-заданий на проверку: артефакт (путь, архив или ссылка) и решение проверяющего.
+-- Очки начисляются только при одобрении.
+CREATE TABLE IF NOT EXISTS task_reviews (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    artifact_kind TEXT NOT NULL CHECK(artifact_kind IN ('path', 'archive', 'link')),
+    artifact TEXT NOT NULL DEFAULT '',
+    archive BLOB,
+    description TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending', 'approved', 'rejected')),
+    feedback TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    reviewed_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_reviews_task ON task_reviews(task_id);
+CREATE INDEX IF NOT EXISTS idx_task_reviews_status ON task_reviews(status);
@@ -0,0 +1,2 @@
+-- Скрипт проверки локального проекта для manual-заданий (тег <Verify> в MDX)
+ALTER TABLE tasks ADD COLUMN verify_script TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,21 @@
+-- Этапы capstone-проектов: пункты чек-листа из ТЗ (синхронизируются при старте сервера)
+-- и отметки о их выполнении.
+CREATE TABLE IF NOT EXISTS project_milestones (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id TEXT NOT NULL,
+    milestone_key TEXT NOT NULL,
+    section TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL,
+    lessons TEXT NOT NULL DEFAULT '',
+    verify_script TEXT NOT NULL DEFAULT '',
+    order_index INTEGER NOT NULL DEFAULT 0,
+    UNIQUE(project_id, milestone_key)
+);
+
+CREATE TABLE IF NOT EXISTS milestone_progress (
+    milestone_id INTEGER PRIMARY KEY REFERENCES project_milestones(id) ON DELETE CASCADE,
+    done INTEGER NOT NULL DEFAULT 0,
+    verified INTEGER NOT NULL DEFAULT 0,
+    verify_report TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,15 @@
+-- Capstone-проекты как контент: импортируются из lessons_mdx/Проекты/*.md (front matter + ТЗ).
+CREATE TABLE IF NOT EXISTS projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    slug TEXT NOT NULL UNIQUE,
+    title TEXT NOT NULL,
+    subtitle TEXT NOT NULL DEFAULT '',
+    difficulty TEXT NOT NULL DEFAULT '',
+    spec_md TEXT NOT NULL DEFAULT '',
+    lessons TEXT NOT NULL DEFAULT '',
+    order_index INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Этапы ссылаются на проект по slug
+ALTER TABLE project_milestones RENAME COLUMN project_id TO project_slug;
@@ -0,0 +1,2 @@
+-- Хеш исходного файла урока: при повторном импорте неизменённые уроки пропускаются.
+ALTER TABLE lessons ADD COLUMN content_hash TEXT NOT NULL DEFAULT '';
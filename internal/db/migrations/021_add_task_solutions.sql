@@ -0,0 +1,9 @@
+-- Эталонное решение задания (тег <Solution> в MDX). В браузер не отдаётся,
+-- пока задание не решено или решение не открыто явно.
+ALTER TABLE tasks ADD COLUMN solution TEXT NOT NULL DEFAULT '';
+
+-- Открытые до решения эталонные решения: за такое задание начисляется меньше очков.
+CREATE TABLE IF NOT EXISTS solution_reveals (
+    task_id INTEGER PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+    revealed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
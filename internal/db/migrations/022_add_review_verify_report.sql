@@ -0,0 +1,3 @@
+-- Отчёт автопроверки проекта (<Verify>), приложенный к сдаче: проверяющий видит его
+-- рядом с артефактом. Очки по-прежнему начисляются только при одобрении.
+ALTER TABLE task_reviews ADD COLUMN verify_report TEXT NOT NULL DEFAULT '';
@@ -7,70 +7,72 @@ import (
 	"golearning/internal/content"
 )
 
-// DemoData содержит демонстрационные уроки для тестирования.
-type DemoData struct {
-	repo *content.Repository
+func init() {
+	RegisterSource("demo", func(SourceConfig) (Source, error) {
+		return NewDemoData(), nil
+	})
 }
 
+// DemoData содержит демонстрационные уроки для тестирования.
+type DemoData struct{}
+
 // NewDemoData создаёт новый генератор демо-данных.
-func NewDemoData(repo *content.Repository) *DemoData {
-	return &DemoData{repo: repo}
+func NewDemoData() *DemoData {
+	return &DemoData{}
 }
 
-// Seed заполняет БД демонстрационными уроками.
-func (d *DemoData) Seed(ctx context.Context) error {
+// Name возвращает имя источника в реестре.
+func (d *DemoData) Name() string { return "demo" }
+
+// Load возвращает демонстрационные уроки. Демо-модули не привязаны к курсу.
+func (d *DemoData) Load(ctx context.Context) (*CourseTree, error) {
 	log.Println("Создание демонстрационных данных...")
 
-	// Создаём модули
-	modules := []content.Module{
-		{Slug: "osnovy", Title: "Основы Go", OrderIndex: 0},
-		{Slug: "tipy-dannyh", Title: "Типы данных", OrderIndex: 1},
-		{Slug: "upravlenie", Title: "Управляющие конструкции", OrderIndex: 2},
+	var course SourceCourse
+	for _, m := range demoModules() {
+		course.Modules = append(course.Modules, SourceModule{Module: m})
 	}
 
-	for i := range modules {
-		if err := d.repo.CreateModule(&modules[i]); err != nil {
-			return err
-		}
+	// Уроки по модулям: введение и переменные, типы и операторы, условия
+	lessons := []struct {
+		module int
+		data   lessonData
+	}{
+		{0, createLesson1()},
+		{0, createLesson2()},
+		{1, createLesson3()},
+		{1, createLesson4()},
+		{2, createLesson5()},
 	}
-
-	// Урок 1: Введение в Go
-	lesson1 := createLesson1()
-	lesson1.Lesson.ModuleID = modules[0].ID
-	if err := d.saveLesson(lesson1); err != nil {
-		return err
+	for _, l := range lessons {
+		lesson := SourceLesson{Lesson: l.data.Lesson, Sections: l.data.Sections, Origin: l.data.Lesson.Slug}
+		for _, t := range l.data.Tasks {
+			lesson.Tasks = append(lesson.Tasks, SourceTask{Task: t})
+		}
+		course.Modules[l.module].Lessons = append(course.Modules[l.module].Lessons, lesson)
 	}
 
-	// Урок 2: Переменные
-	lesson2 := createLesson2()
-	lesson2.Lesson.ModuleID = modules[0].ID
-	if err := d.saveLesson(lesson2); err != nil {
-		return err
-	}
+	return &CourseTree{Courses: []SourceCourse{course}}, nil
+}
 
-	// Урок 3: Типы данных
-	lesson3 := createLesson3()
-	lesson3.Lesson.ModuleID = modules[1].ID
-	if err := d.saveLesson(lesson3); err != nil {
-		return err
+func demoModules() []content.Module {
+	return []content.Module{
+		{Slug: "osnovy", Title: "Основы Go", OrderIndex: 0},
+		{Slug: "tipy-dannyh", Title: "Типы данных", OrderIndex: 1},
+		{Slug: "upravlenie", Title: "Управляющие конструкции", OrderIndex: 2},
 	}
+}
 
-	// Урок 4: Операторы
-	lesson4 := createLesson4()
-	lesson4.Lesson.ModuleID = modules[1].ID
-	if err := d.saveLesson(lesson4); err != nil {
-		return err
+// DemoContent возвращает slug'и модулей и уроков демо-данных — чтобы удалить их из БД.
+func DemoContent() content.ContentSet {
+	var set content.ContentSet
+	for _, m := range demoModules() {
+		set.Modules = append(set.Modules, m.Slug)
 	}
-
-	// Урок 5: Условные конструкции
-	lesson5 := createLesson5()
-	lesson5.Lesson.ModuleID = modules[2].ID
-	if err := d.saveLesson(lesson5); err != nil {
-		return err
+	for _, l := range []lessonData{createLesson1(), createLesson2(), createLesson3(), createLesson4(), createLesson5()} {
+		set.Lessons = append(set.Lessons, l.Lesson.Slug)
 	}
-
-	log.Println("Демонстрационные данные созданы!")
-	return nil
+	return set
 }
 
 type lessonData struct {
@@ -79,32 +81,6 @@ type lessonData struct {
 	Tasks    []content.Task
 }
 
-func (d *DemoData) saveLesson(data lessonData) error {
-	if err := d.repo.CreateLesson(&data.Lesson); err != nil {
-		return err
-	}
-	log.Printf("  Урок: %s (ID=%d)", data.Lesson.Title, data.Lesson.ID)
-
-	d.repo.DeleteSectionsByLessonID(data.Lesson.ID)
-	d.repo.DeleteTasksByLessonID(data.Lesson.ID)
-
-	for i := range data.Sections {
-		data.Sections[i].LessonID = data.Lesson.ID
-		if err := d.repo.CreateSection(&data.Sections[i]); err != nil {
-			log.Printf("    Ошибка секции: %v", err)
-		}
-	}
-
-	for i := range data.Tasks {
-		data.Tasks[i].LessonID = data.Lesson.ID
-		if err := d.repo.CreateTask(&data.Tasks[i]); err != nil {
-			log.Printf("    Ошибка задания: %v", err)
-		}
-	}
-
-	return nil
-}
-
 // ============================================================
 // УРОК 1: Введение в Go
 // ============================================================
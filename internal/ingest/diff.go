@@ -0,0 +1,369 @@
+package ingest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golearning/internal/content"
+)
+
+// Виды изменений секций и заданий в ContentDiff.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeRenamed = "renamed"
+	ChangeUpdated = "updated"
+)
+
+// ContentDiff — что импорт изменит в БД. Строится сравнением снимков до и после
+// импорта, выполненного в откатываемой транзакции (cmd/ingest -dry-run).
+type ContentDiff struct {
+	AddedLessons     []LessonRef        `json:"added_lessons"`
+	RemovedLessons   []LessonRef        `json:"removed_lessons"` // Есть в БД, но не в источнике: без -prune импорт их не удаляет
+	RenamedLessons   []LessonRename     `json:"renamed_lessons"`
+	UpdatedLessons   []LessonChange     `json:"updated_lessons"`
+	OrphanedProgress []OrphanedProgress `json:"orphaned_progress"`
+}
+
+// LessonRef — урок в диффе.
+type LessonRef struct {
+	Slug    string `json:"slug"`
+	Title   string `json:"title"`
+	Module  string `json:"module"`
+	Deleted bool   `json:"deleted,omitempty"` // Убранный из источника урок будет удалён (-prune)
+}
+
+// LessonRename — урок, у которого сменился slug (обычно из-за нового заголовка):
+// импорт создаст новый урок, а старый вместе с прогрессом останется в БД.
+type LessonRename struct {
+	From    string          `json:"from"`
+	To      string          `json:"to"`
+	Title   string          `json:"title"`
+	Changes []ElementChange `json:"changes,omitempty"`
+}
+
+// LessonChange — урок, содержимое которого изменится.
+type LessonChange struct {
+	Slug      string          `json:"slug"`
+	Title     string          `json:"title"`
+	OldTitle  string          `json:"old_title,omitempty"`
+	Module    string          `json:"module,omitempty"`
+	OldModule string          `json:"old_module,omitempty"`
+	Changes   []ElementChange `json:"changes,omitempty"`
+}
+
+// ElementChange — изменение секции или задания урока.
+type ElementChange struct {
+	Element   string `json:"element"` // section / task
+	Change    string `json:"change"`  // added / removed / renamed / updated
+	Key       string `json:"key"`     // вид секции или ключ задания
+	Title     string `json:"title"`
+	OldKey    string `json:"old_key,omitempty"`
+	OldTitle  string `json:"old_title,omitempty"`
+	Points    int    `json:"points,omitempty"`
+	OldPoints int    `json:"old_points,omitempty"`
+	Mode      string `json:"mode,omitempty"`
+	OldMode   string `json:"old_mode,omitempty"`
+}
+
+// OrphanedProgress — записи прогресса, которые после импорта перестанут относиться к контенту:
+// останутся у урока, которого нет в источнике, или удалятся вместе с заданием.
+type OrphanedProgress struct {
+	Lesson string `json:"lesson"`
+	Task   string `json:"task,omitempty"`
+	Rows   int    `json:"rows"`
+	Reason string `json:"reason"`
+}
+
+// Empty сообщает, что импорт ничего не изменит.
+func (d *ContentDiff) Empty() bool {
+	return len(d.AddedLessons) == 0 && len(d.RemovedLessons) == 0 && len(d.RenamedLessons) == 0 &&
+		len(d.UpdatedLessons) == 0 && len(d.OrphanedProgress) == 0
+}
+
+// DiffSnapshots сравнивает снимки БД до и после импорта. source — slug'и уроков источника
+// (ImportReport.Lessons); уроки из before, которых там нет, считаются убранными из источника.
+// При source == nil (демо-данные) убранные уроки не ищутся.
+func DiffSnapshots(before, after *content.Snapshot, source []string) *ContentDiff {
+	// Пустые списки, а не null — JSON читают скрипты
+	d := &ContentDiff{
+		AddedLessons:     []LessonRef{},
+		RemovedLessons:   []LessonRef{},
+		RenamedLessons:   []LessonRename{},
+		UpdatedLessons:   []LessonChange{},
+		OrphanedProgress: []OrphanedProgress{},
+	}
+
+	inSource := make(map[string]bool, len(source))
+	for _, slug := range source {
+		inSource[slug] = true
+	}
+
+	var added, removed []*content.LessonSnapshot
+	for slug, l := range after.Lessons {
+		if before.Lessons[slug] == nil {
+			added = append(added, l)
+		}
+	}
+	if source != nil {
+		for slug, l := range before.Lessons {
+			if !inSource[slug] {
+				removed = append(removed, l)
+			}
+		}
+	}
+	sortLessons(added)
+	sortLessons(removed)
+
+	// Новый урок на месте убранного (тот же модуль и порядок) — переименование
+	renamedFrom := make(map[string]bool)
+	for _, nl := range added {
+		var old *content.LessonSnapshot
+		for _, ol := range removed {
+			if !renamedFrom[ol.Slug] && ol.ModuleSlug == nl.ModuleSlug && ol.OrderIndex == nl.OrderIndex {
+				old = ol
+				break
+			}
+		}
+		if old == nil {
+			d.AddedLessons = append(d.AddedLessons, LessonRef{Slug: nl.Slug, Title: nl.Title, Module: nl.ModuleSlug})
+			continue
+		}
+		renamedFrom[old.Slug] = true
+		d.RenamedLessons = append(d.RenamedLessons, LessonRename{
+			From:    old.Slug,
+			To:      nl.Slug,
+			Title:   nl.Title,
+			Changes: diffLessonElements(old, nl),
+		})
+		reason := "урок переименован в " + nl.Slug
+		if after.Lessons[old.Slug] == nil {
+			reason += " и удалится вместе с прогрессом"
+		}
+		d.orphanLesson(old, reason)
+	}
+	for _, ol := range removed {
+		if renamedFrom[ol.Slug] {
+			continue
+		}
+		deleted := after.Lessons[ol.Slug] == nil
+		d.RemovedLessons = append(d.RemovedLessons, LessonRef{Slug: ol.Slug, Title: ol.Title, Module: ol.ModuleSlug, Deleted: deleted})
+		if deleted {
+			d.orphanLesson(ol, "урок удалится вместе с прогрессом")
+		} else {
+			d.orphanLesson(ol, "урок убран из источника")
+		}
+	}
+
+	slugs := make([]string, 0, len(before.Lessons))
+	for slug := range before.Lessons {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	for _, slug := range slugs {
+		ol, nl := before.Lessons[slug], after.Lessons[slug]
+		if nl == nil || ol.ContentHash == nl.ContentHash {
+			continue
+		}
+		change := LessonChange{Slug: slug, Title: nl.Title, Changes: diffLessonElements(ol, nl)}
+		if ol.Title != nl.Title {
+			change.OldTitle = ol.Title
+		}
+		if ol.ModuleSlug != nl.ModuleSlug {
+			change.Module, change.OldModule = nl.ModuleSlug, ol.ModuleSlug
+		}
+		d.UpdatedLessons = append(d.UpdatedLessons, change)
+
+		for _, c := range change.Changes {
+			if c.Element != "task" || c.Change != ChangeRemoved {
+				continue
+			}
+			for _, t := range ol.Tasks {
+				if t.Key == c.Key && t.ProgressRows > 0 {
+					d.OrphanedProgress = append(d.OrphanedProgress, OrphanedProgress{
+						Lesson: slug, Task: t.Key, Rows: t.ProgressRows, Reason: "задание удалено из урока",
+					})
+				}
+			}
+		}
+	}
+
+	return d
+}
+
+// orphanLesson учитывает прогресс урока и его заданий, который останется без контента.
+func (d *ContentDiff) orphanLesson(l *content.LessonSnapshot, reason string) {
+	rows := l.ProgressRows
+	for _, t := range l.Tasks {
+		rows += t.ProgressRows
+	}
+	if rows > 0 {
+		d.OrphanedProgress = append(d.OrphanedProgress, OrphanedProgress{Lesson: l.Slug, Rows: rows, Reason: reason})
+	}
+}
+
+func sortLessons(lessons []*content.LessonSnapshot) {
+	sort.Slice(lessons, func(i, j int) bool {
+		if lessons[i].ModuleSlug != lessons[j].ModuleSlug {
+			return lessons[i].ModuleSlug < lessons[j].ModuleSlug
+		}
+		return lessons[i].OrderIndex < lessons[j].OrderIndex
+	})
+}
+
+// diffLessonElements сравнивает секции (по позиции) и задания (по ID, затем по ключу) двух версий урока.
+func diffLessonElements(ol, nl *content.LessonSnapshot) []ElementChange {
+	var changes []ElementChange
+
+	// Секции при импорте пересоздаются, поэтому сопоставляются по порядку
+	for i := 0; i < len(ol.Sections) || i < len(nl.Sections); i++ {
+		switch {
+		case i >= len(nl.Sections):
+			s := ol.Sections[i]
+			changes = append(changes, ElementChange{Element: "section", Change: ChangeRemoved, Key: string(s.Kind), Title: s.Title})
+		case i >= len(ol.Sections):
+			s := nl.Sections[i]
+			changes = append(changes, ElementChange{Element: "section", Change: ChangeAdded, Key: string(s.Kind), Title: s.Title})
+		default:
+			prev, next := ol.Sections[i], nl.Sections[i]
+			switch {
+			case prev.Kind != next.Kind:
+				changes = append(changes,
+					ElementChange{Element: "section", Change: ChangeRemoved, Key: string(prev.Kind), Title: prev.Title},
+					ElementChange{Element: "section", Change: ChangeAdded, Key: string(next.Kind), Title: next.Title})
+			case prev.Title != next.Title:
+				changes = append(changes, ElementChange{Element: "section", Change: ChangeRenamed, Key: string(next.Kind), Title: next.Title, OldTitle: prev.Title})
+			case prev.BodyMD != next.BodyMD:
+				changes = append(changes, ElementChange{Element: "section", Change: ChangeUpdated, Key: string(next.Kind), Title: next.Title})
+			}
+		}
+	}
+
+	oldTasks := sortedTasks(ol.Tasks)
+	newTasks := sortedTasks(nl.Tasks)
+	matched := make(map[int64]bool)
+	for _, nt := range newTasks {
+		ot := ol.Tasks[nt.ID]
+		if ot == nil {
+			for _, t := range oldTasks {
+				if !matched[t.ID] && nl.Tasks[t.ID] == nil && t.Key == nt.Key {
+					ot = t
+					break
+				}
+			}
+		}
+		if ot == nil {
+			changes = append(changes, ElementChange{Element: "task", Change: ChangeAdded, Key: nt.Key, Title: nt.Title, Points: nt.Points, Mode: nt.Mode})
+			continue
+		}
+		matched[ot.ID] = true
+
+		c := ElementChange{Element: "task", Key: nt.Key, Title: nt.Title}
+		if ot.Key != nt.Key || ot.Title != nt.Title {
+			c.Change = ChangeRenamed
+			if ot.Key != nt.Key {
+				c.OldKey = ot.Key
+			}
+			if ot.Title != nt.Title {
+				c.OldTitle = ot.Title
+			}
+		}
+		if ot.Points != nt.Points {
+			c.Points, c.OldPoints = nt.Points, ot.Points
+		}
+		if ot.Mode != nt.Mode {
+			c.Mode, c.OldMode = nt.Mode, ot.Mode
+		}
+		if c.Change == "" && (c.OldPoints != 0 || c.Points != 0 || c.OldMode != "") {
+			c.Change = ChangeUpdated
+		}
+		if c.Change != "" {
+			changes = append(changes, c)
+		}
+	}
+	for _, ot := range oldTasks {
+		if !matched[ot.ID] {
+			changes = append(changes, ElementChange{Element: "task", Change: ChangeRemoved, Key: ot.Key, Title: ot.Title, Points: ot.Points, Mode: ot.Mode})
+		}
+	}
+
+	return changes
+}
+
+func sortedTasks(tasks map[int64]*content.TaskSnapshot) []*content.TaskSnapshot {
+	result := make([]*content.TaskSnapshot, 0, len(tasks))
+	for _, t := range tasks {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// Text возвращает дифф в читаемом виде для консоли.
+func (d *ContentDiff) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Уроки: добавится %d, убрано из источника %d, переименуется %d, изменится %d\n",
+		len(d.AddedLessons), len(d.RemovedLessons), len(d.RenamedLessons), len(d.UpdatedLessons))
+
+	for _, l := range d.AddedLessons {
+		fmt.Fprintf(&b, "  + %s «%s» (%s)\n", l.Slug, l.Title, l.Module)
+	}
+	for _, l := range d.RemovedLessons {
+		if l.Deleted {
+			fmt.Fprintf(&b, "  - %s «%s» (%s) — удалится\n", l.Slug, l.Title, l.Module)
+		} else {
+			fmt.Fprintf(&b, "  - %s «%s» (%s) — останется в БД\n", l.Slug, l.Title, l.Module)
+		}
+	}
+	for _, l := range d.RenamedLessons {
+		fmt.Fprintf(&b, "  → %s → %s «%s»\n", l.From, l.To, l.Title)
+		writeElementChanges(&b, l.Changes)
+	}
+	for _, l := range d.UpdatedLessons {
+		fmt.Fprintf(&b, "  ~ %s «%s»\n", l.Slug, l.Title)
+		if l.OldTitle != "" {
+			fmt.Fprintf(&b, "      заголовок: «%s» → «%s»\n", l.OldTitle, l.Title)
+		}
+		if l.OldModule != "" {
+			fmt.Fprintf(&b, "      модуль: %s → %s\n", l.OldModule, l.Module)
+		}
+		writeElementChanges(&b, l.Changes)
+	}
+
+	if len(d.OrphanedProgress) > 0 {
+		b.WriteString("Прогресс без контента:\n")
+		for _, o := range d.OrphanedProgress {
+			target := o.Lesson
+			if o.Task != "" {
+				target += " / задание " + o.Task
+			}
+			fmt.Fprintf(&b, "  ! %s: записей %d — %s\n", target, o.Rows, o.Reason)
+		}
+	}
+	return b.String()
+}
+
+func writeElementChanges(b *strings.Builder, changes []ElementChange) {
+	marks := map[string]string{ChangeAdded: "+", ChangeRemoved: "-", ChangeRenamed: "→", ChangeUpdated: "~"}
+	names := map[string]string{"section": "секция", "task": "задание"}
+
+	for _, c := range changes {
+		fmt.Fprintf(b, "      %s %s %s «%s»", marks[c.Change], names[c.Element], c.Key, c.Title)
+		if c.OldKey != "" {
+			fmt.Fprintf(b, ", ключ был %s", c.OldKey)
+		}
+		if c.OldTitle != "" {
+			fmt.Fprintf(b, ", было «%s»", c.OldTitle)
+		}
+		if c.Change != ChangeAdded && c.Change != ChangeRemoved {
+			if c.OldPoints != c.Points {
+				fmt.Fprintf(b, ", очки %d → %d", c.OldPoints, c.Points)
+			}
+			if c.OldMode != "" {
+				fmt.Fprintf(b, ", режим %s → %s", c.OldMode, c.Mode)
+			}
+		}
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,237 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golearning/internal/content"
+)
+
+// Раскладка каталога контента, общая для MDX и Markdown:
+// NN_Курс/Глава_NN_Модуль/NN_Урок.mdx. Курс и модуль выводятся из имён каталогов.
+
+// DirEntry представляет директорию или файл.
+type DirEntry struct {
+	Name  string
+	Title string
+	Path  string
+	Order int
+}
+
+// loadDirLesson разбирает файл урока в урок дерева.
+type loadDirLesson func(ctx context.Context, lessonFile DirEntry) (*SourceLesson, error)
+
+// loadDirTree обходит каталог курсов и собирает дерево контента. Уроки, которые
+// не удалось разобрать, попадают в tree.Failed — остальное дерево остаётся целым.
+func loadDirTree(ctx context.Context, baseDir string, exts []string, load loadDirLesson) (*CourseTree, error) {
+	guides, err := findGuides(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("find guides: %w", err)
+	}
+	return loadCourses(ctx, guides, exts, load)
+}
+
+// loadCourses собирает дерево из каталогов курсов guides.
+func loadCourses(ctx context.Context, guides []DirEntry, exts []string, load loadDirLesson) (*CourseTree, error) {
+	tree := &CourseTree{}
+	moduleIndex := 0
+	for _, guide := range guides {
+		c, err := courseFromDir(guide)
+		if err != nil {
+			return nil, fmt.Errorf("course %s: %w", guide.Name, err)
+		}
+		course := SourceCourse{Course: c}
+
+		chapters, err := findChapters(guide.Path)
+		if err != nil {
+			return nil, fmt.Errorf("find chapters of %s: %w", guide.Title, err)
+		}
+		for _, chapter := range chapters {
+			module := SourceModule{Module: content.Module{
+				Slug:       slugify(chapter.Title),
+				Title:      chapter.Title,
+				OrderIndex: moduleIndex,
+			}}
+			moduleIndex++
+
+			lessons, err := findLessons(chapter.Path, exts)
+			if err != nil {
+				return nil, fmt.Errorf("find lessons of %s: %w", chapter.Title, err)
+			}
+			for _, lessonFile := range lessons {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				lesson, err := load(ctx, lessonFile)
+				if err != nil {
+					log.Printf("    ❌ Ошибка разбора урока %s: %v", lessonFile.Name, err)
+					tree.fail(lessonFile.Path, err)
+					continue
+				}
+				module.Lessons = append(module.Lessons, *lesson)
+			}
+			course.Modules = append(course.Modules, module)
+		}
+		tree.Courses = append(tree.Courses, course)
+	}
+	return tree, nil
+}
+
+// ErrNotLesson — файл лежит не на месте урока (<курс>/<глава>/<урок>.mdx).
+var ErrNotLesson = errors.New("not a lesson file")
+
+// loadDirFile разбирает один файл урока и возвращает slug его модуля.
+// Файл не на месте урока (<курс>/<глава>/<урок>) даёт ErrNotLesson.
+func loadDirFile(ctx context.Context, baseDir, path string, exts []string, load loadDirLesson) (string, *SourceLesson, error) {
+	rel, err := filepath.Rel(baseDir, path)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s", ErrNotLesson, path)
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) != 3 || skipGuideDir(parts[0]) || !hasExt(parts[2], exts) {
+		return "", nil, fmt.Errorf("%w: %s", ErrNotLesson, path)
+	}
+
+	_, chapterTitle := parseNumberedName(parts[1])
+	order, title := parseNumberedName(strings.TrimSuffix(parts[2], filepath.Ext(parts[2])))
+	lesson, err := load(ctx, DirEntry{Name: parts[2], Title: title, Path: path, Order: order})
+	if err != nil {
+		return "", nil, err
+	}
+	return slugify(chapterTitle), lesson, nil
+}
+
+// skipGuideDir сообщает, что каталог верхнего уровня — не курс.
+// Например, lessons_mdx/Проекты содержит ТЗ capstone-проектов — их импортирует ProjectImporter.
+func skipGuideDir(name string) bool {
+	return name == ProjectsDir || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+func hasExt(name string, exts []string) bool {
+	for _, ext := range exts {
+		if filepath.Ext(name) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func findGuides(baseDir string) ([]DirEntry, error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var guides []DirEntry
+	for _, entry := range entries {
+		// Служебные директории/метаданные — не считаем отдельными курсами
+		if !entry.IsDir() || skipGuideDir(entry.Name()) {
+			continue
+		}
+
+		name := entry.Name()
+		order, title := parseNumberedName(name)
+
+		guides = append(guides, DirEntry{
+			Name:  name,
+			Title: title,
+			Path:  filepath.Join(baseDir, name),
+			Order: order,
+		})
+	}
+
+	sort.Slice(guides, func(i, j int) bool {
+		return guides[i].Order < guides[j].Order
+	})
+
+	return guides, nil
+}
+
+func findChapters(guidePath string) ([]DirEntry, error) {
+	entries, err := os.ReadDir(guidePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var chapters []DirEntry
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		order, title := parseNumberedName(name)
+
+		chapters = append(chapters, DirEntry{
+			Name:  name,
+			Title: title,
+			Path:  filepath.Join(guidePath, name),
+			Order: order,
+		})
+	}
+
+	sort.Slice(chapters, func(i, j int) bool {
+		return chapters[i].Order < chapters[j].Order
+	})
+
+	return chapters, nil
+}
+
+// findLessons возвращает файлы уроков главы с расширениями exts.
+func findLessons(chapterPath string, exts []string) ([]DirEntry, error) {
+	entries, err := os.ReadDir(chapterPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var lessons []DirEntry
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !hasExt(name, exts) {
+			continue
+		}
+
+		order, title := parseNumberedName(strings.TrimSuffix(name, filepath.Ext(name)))
+
+		lessons = append(lessons, DirEntry{
+			Name:  name,
+			Title: title,
+			Path:  filepath.Join(chapterPath, name),
+			Order: order,
+		})
+	}
+
+	sort.Slice(lessons, func(i, j int) bool {
+		return lessons[i].Order < lessons[j].Order
+	})
+
+	return lessons, nil
+}
+
+var (
+	numberedNameRe = regexp.MustCompile(`^(\d+)_(.+)$`)
+	chapterNameRe  = regexp.MustCompile(`^Глава_(\d+)_(.+)$`)
+)
+
+// parseNumberedName разбирает имя вида "01_..." или "Глава_01_..." на номер и заголовок.
+func parseNumberedName(name string) (int, string) {
+	for _, re := range []*regexp.Regexp{numberedNameRe, chapterNameRe} {
+		if matches := re.FindStringSubmatch(name); len(matches) == 3 {
+			order, _ := strconv.Atoi(matches[1])
+			title := strings.ReplaceAll(matches[2], "_", " ")
+			return order, title
+		}
+	}
+
+	// Без номера
+	title := strings.ReplaceAll(name, "_", " ")
+	return 0, title
+}
@@ -0,0 +1,538 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golearning/internal/content"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrExportFailed возвращается, если хотя бы один урок не удалось выгрузить.
+var ErrExportFailed = errors.New("export failed")
+
+// ExportOptions — настройки выгрузки.
+type ExportOptions struct {
+	Clean bool // удалить каталоги курсов, которых больше нет в БД или которые переименованы
+}
+
+// ExportReport — итог выгрузки.
+type ExportReport struct {
+	Courses  int
+	Modules  int
+	Lessons  int
+	Warnings []string // что не выражается в MDX и изменится при обратном импорте
+	Failed   []ImportFailure
+}
+
+// Err возвращает ErrExportFailed, если хотя бы один урок не выгружен.
+func (r *ExportReport) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %d lesson(s)", ErrExportFailed, len(r.Failed))
+}
+
+// MDXExporter выгружает курсы, модули и уроки из БД в каталог в раскладке lessons_mdx:
+// NN_Курс/Глава_NN_Модуль/NN_Урок.mdx. Повторный импорт выгрузки через MDXSource
+// восстанавливает тот же контент — это проверяет cmd/export -check.
+type MDXExporter struct {
+	repo *content.Repository
+	dir  string
+	opts ExportOptions
+}
+
+// NewMDXExporter создаёт экспортёр в каталог dir.
+func NewMDXExporter(repo *content.Repository, dir string, opts ExportOptions) *MDXExporter {
+	return &MDXExporter{repo: repo, dir: dir, opts: opts}
+}
+
+// orphanCourseTitle — каталог для модулей без курса (их создают демо-данные и Pipeline):
+// в раскладке lessons_mdx модуль всегда лежит в каталоге курса.
+const orphanCourseTitle = "Без курса"
+
+// exportCourse — курс со всем содержимым в порядке выгрузки. У псевдокурса
+// для модулей без курса ID равен 0.
+type exportCourse struct {
+	content.Course
+	Modules []exportModule
+}
+
+type exportModule struct {
+	content.Module
+	Lessons []exportLesson
+}
+
+// exportLesson — урок с секциями, заданиями (вместе с эталонными решениями) и requires.
+type exportLesson struct {
+	content.Lesson
+	Requires []string
+}
+
+// Export выгружает весь контент БД. Файлы существующих уроков перезаписываются.
+func (e *MDXExporter) Export(ctx context.Context) (*ExportReport, error) {
+	courses, err := loadContentTree(e.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.opts.Clean {
+		if err := e.clean(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(e.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create export dir: %w", err)
+	}
+
+	report := &ExportReport{}
+	written := make(map[string]string) // путь файла → slug урока
+	for _, course := range courses {
+		courseDir := fmt.Sprintf("%02d_%s", course.OrderIndex, exportName(course.Title))
+		if course.ID != 0 {
+			if err := e.writeManifest(report, &course.Course, filepath.Join(e.dir, courseDir)); err != nil {
+				return report, err
+			}
+		}
+		report.Courses++
+
+		for i, module := range course.Modules {
+			moduleDir := fmt.Sprintf("Глава_%02d_%s", i+1, exportName(module.Title))
+			e.checkNaming(report, "module", module.Slug, module.Title, moduleDir)
+			if course.ID == 0 {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("module %s has no course, exported into %s", module.Slug, courseDir))
+			}
+			dir := filepath.Join(e.dir, courseDir, moduleDir)
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return report, fmt.Errorf("create module dir: %w", err)
+			}
+			report.Modules++
+
+			for _, lesson := range module.Lessons {
+				if err := ctx.Err(); err != nil {
+					return report, err
+				}
+				path := filepath.Join(dir, fmt.Sprintf("%02d_%s.mdx", lesson.OrderIndex, exportName(lesson.Title)))
+				if other, dup := written[path]; dup {
+					report.Failed = append(report.Failed, ImportFailure{Lesson: lesson.Slug, Err: fmt.Errorf("file %s is already taken by %s", path, other)})
+					continue
+				}
+
+				src, warnings := e.renderLesson(&lesson)
+				for _, w := range warnings {
+					report.Warnings = append(report.Warnings, lesson.Slug+": "+w)
+				}
+				// Выгрузка должна читаться тем же парсером, что и при импорте
+				if _, err := ParseMDXLesson(src); err != nil {
+					report.Failed = append(report.Failed, ImportFailure{Lesson: lesson.Slug, Err: fmt.Errorf("render mdx: %w", err)})
+					continue
+				}
+				if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+					return report, fmt.Errorf("write lesson: %w", err)
+				}
+				written[path] = lesson.Slug
+				report.Lessons++
+			}
+		}
+	}
+
+	return report, report.Err()
+}
+
+// clean удаляет каталоги курсов — то, что MDXSource считает курсами. Проекты и
+// служебные каталоги остаются.
+func (e *MDXExporter) clean() error {
+	guides, err := findGuides(e.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("find courses: %w", err)
+	}
+	for _, g := range guides {
+		if err := os.RemoveAll(g.Path); err != nil {
+			return fmt.Errorf("remove %s: %w", g.Path, err)
+		}
+	}
+	return nil
+}
+
+// writeManifest записывает course.yaml: заголовок, иконку, описание и порядок курса
+// переносит манифест, а slug выводится из заголовка.
+func (e *MDXExporter) writeManifest(report *ExportReport, course *content.Course, dir string) error {
+	if slug := slugify(course.Title); slug != course.Slug {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("course %s: slug becomes %s", course.Slug, slug))
+	}
+
+	// yaml.v3 экранирует эмодзи вне BMP (\U0001F4D8) — строки пишем в кавычках Go:
+	// это валидные YAML-строки в двойных кавычках, а иконка остаётся читаемой
+	var b strings.Builder
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(course.Title))
+	if course.Icon != defaultCourseIcon {
+		fmt.Fprintf(&b, "icon: %s\n", strconv.Quote(course.Icon))
+	}
+	if course.Description != "" {
+		fmt.Fprintf(&b, "description: %s\n", strconv.Quote(course.Description))
+	}
+	fmt.Fprintf(&b, "order: %d\n", course.OrderIndex)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create course dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	return nil
+}
+
+// checkNaming предупреждает, если заголовок или slug модуля не переживут
+// обратный импорт: оба выводятся из имени каталога.
+func (e *MDXExporter) checkNaming(report *ExportReport, kind, slug, title, dirName string) {
+	_, parsedTitle := parseNumberedName(dirName)
+	if parsedTitle != title {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%s %s: title %q becomes %q", kind, slug, title, parsedTitle))
+	}
+	if newSlug := slugify(parsedTitle); newSlug != slug {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%s %s: slug becomes %s", kind, slug, newSlug))
+	}
+}
+
+// renderLesson собирает MDX урока. Значения, которые импортёр подставляет сам
+// (критерии приёмки, стартовый код по умолчанию), не выгружаются.
+func (e *MDXExporter) renderLesson(l *exportLesson) (string, []string) {
+	var b strings.Builder
+	var warnings []string
+
+	fmt.Fprintf(&b, "# %s\n\n", l.Title)
+
+	meta := LessonMeta{
+		ReadingTime: l.ReadingTimeMin,
+		Requires:    l.Requires,
+		SourceURL:   l.SourceURL,
+	}
+	if slug := defaultLessonSlug(l.Title, l.OrderIndex); slug != l.Slug {
+		meta.Slug = l.Slug
+	}
+	var metaBuf bytes.Buffer
+	enc := yaml.NewEncoder(&metaBuf)
+	enc.SetIndent(2)
+	if err := enc.Encode(meta); err == nil && metaBuf.String() != "{}\n" {
+		fmt.Fprintf(&b, "<Meta>\n%s</Meta>\n\n", metaBuf.String())
+	}
+
+	seen := make(map[content.SectionKind]bool)
+	for _, sec := range l.Sections {
+		tag, title := "", ""
+		for _, st := range mdxSectionTags {
+			if st.Kind == sec.Kind {
+				tag, title = st.Tag, st.Title
+			}
+		}
+		switch {
+		case tag == "":
+			warnings = append(warnings, fmt.Sprintf("section %q of kind %s has no MDX tag, skipped", sec.Title, sec.Kind))
+			continue
+		case seen[sec.Kind]:
+			warnings = append(warnings, fmt.Sprintf("duplicate %s section %q skipped", sec.Kind, sec.Title))
+			continue
+		case sec.Title != title:
+			warnings = append(warnings, fmt.Sprintf("section title %q becomes %q", sec.Title, title))
+		}
+		seen[sec.Kind] = true
+		fmt.Fprintf(&b, "<%s>\n%s\n</%s>\n\n", tag, strings.TrimSpace(sec.BodyMD), tag)
+	}
+
+	for _, t := range l.Tasks {
+		b.WriteString(e.renderTask(&t))
+		b.WriteString("\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n", warnings
+}
+
+// renderTask собирает компонент <Task>.
+func (e *MDXExporter) renderTask(t *content.Task) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<Task id=%s points=\"%d\"", mdxAttr(t.Key), t.Points)
+	if t.Mode == "manual" {
+		b.WriteString(` mode="manual"`)
+	}
+	b.WriteString(">\n")
+
+	fmt.Fprintf(&b, "<Title>%s</Title>\n", t.Title)
+	block := func(tag, body string) {
+		if body = strings.TrimSpace(body); body != "" {
+			fmt.Fprintf(&b, "<%s>\n%s\n</%s>\n", tag, body, tag)
+		}
+	}
+	code := func(tag, body string) {
+		if body = strings.TrimSpace(body); body != "" {
+			fmt.Fprintf(&b, "<%s>\n```go\n%s\n```\n</%s>\n", tag, body, tag)
+		}
+	}
+
+	block("Prompt", t.PromptMD)
+	if t.Criteria != generateCriteria(t.ExpectedOutput, t.RequiredPatterns) {
+		block("Criteria", t.Criteria)
+	}
+	block("Hints", t.Hints)
+	if t.Mode == "manual" || t.StarterCode != defaultStarterCode {
+		code("StarterCode", t.StarterCode)
+	}
+	code("Tests", t.TestsGo)
+	block("ExpectedOutput", t.ExpectedOutput)
+	block("RequiredPatterns", t.RequiredPatterns)
+	block("Verify", t.VerifyScript)
+	code("Solution", t.Solution)
+
+	b.WriteString("</Task>\n")
+	return b.String()
+}
+
+// mdxAttr заключает значение атрибута в кавычки, которых в нём нет.
+func mdxAttr(v string) string {
+	if strings.Contains(v, `"`) {
+		return "'" + v + "'"
+	}
+	return `"` + v + `"`
+}
+
+// exportName превращает заголовок в имя файла или каталога: пробелы — в "_",
+// символы, недопустимые в именах файлов, убираются.
+func exportName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ':
+			return '_'
+		case r < ' ' || strings.ContainsRune(`/\:*?"<>|`, r):
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(title))
+	if name == "" {
+		name = "_"
+	}
+	return name
+}
+
+// loadContentTree читает весь контент БД в порядке курсов, модулей и уроков.
+func loadContentTree(repo *content.Repository) ([]exportCourse, error) {
+	courses, err := repo.ListCourses()
+	if err != nil {
+		return nil, err
+	}
+	modules, err := repo.ListModules()
+	if err != nil {
+		return nil, err
+	}
+
+	// Модули без курса собираются в псевдокурс перед настоящими курсами
+	tree := []exportCourse{{Course: content.Course{Title: orphanCourseTitle}}}
+	for _, c := range courses {
+		tree = append(tree, exportCourse{Course: c})
+	}
+	byCourse := make(map[int64]*exportCourse, len(tree))
+	for i := range tree {
+		byCourse[tree[i].ID] = &tree[i]
+	}
+
+	for _, mod := range modules {
+		course := byCourse[mod.CourseID]
+		if course == nil {
+			course = byCourse[0] // курс удалён, а модуль остался
+		}
+		module := exportModule{Module: mod}
+		lessons, err := repo.ListLessonsByModuleID(mod.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range lessons {
+			lesson := exportLesson{Lesson: l}
+			if lesson.Sections, err = repo.GetSectionsByLessonID(l.ID); err != nil {
+				return nil, err
+			}
+			if lesson.Tasks, err = repo.GetTasksByLessonID(l.ID); err != nil {
+				return nil, err
+			}
+			for i := range lesson.Tasks {
+				if !lesson.Tasks[i].HasSolution {
+					continue
+				}
+				if lesson.Tasks[i].Solution, err = repo.GetTaskSolution(lesson.Tasks[i].ID); err != nil {
+					return nil, err
+				}
+			}
+			if lesson.Requires, err = repo.GetLessonPrerequisites(l.ID); err != nil {
+				return nil, err
+			}
+			module.Lessons = append(module.Lessons, lesson)
+		}
+		course.Modules = append(course.Modules, module)
+	}
+
+	if len(tree[0].Modules) == 0 {
+		tree = tree[1:]
+	}
+	return tree, nil
+}
+
+// CompareContent сравнивает контент двух БД и возвращает расхождения got относительно want.
+// Идентификаторы, хеши, даты и исходный текст урока (body_md) не сравниваются:
+// они зависят от того, откуда и когда импортирован урок, а не от его содержимого.
+func CompareContent(want, got *content.Repository) ([]string, error) {
+	wantTree, err := loadContentTree(want)
+	if err != nil {
+		return nil, fmt.Errorf("load content: %w", err)
+	}
+	gotTree, err := loadContentTree(got)
+	if err != nil {
+		return nil, fmt.Errorf("load content: %w", err)
+	}
+
+	type moduleRef struct {
+		exportModule
+		Course string
+	}
+	type lessonRef struct {
+		exportLesson
+		Module string
+	}
+	index := func(tree []exportCourse) (map[string]exportCourse, map[string]moduleRef, map[string]lessonRef, []string) {
+		courses := make(map[string]exportCourse)
+		modules := make(map[string]moduleRef)
+		lessons := make(map[string]lessonRef)
+		var order []string
+		for _, c := range tree {
+			if c.ID != 0 {
+				courses[c.Slug] = c
+				order = append(order, "course "+c.Slug)
+			}
+			for _, m := range c.Modules {
+				modules[m.Slug] = moduleRef{m, c.Slug}
+				order = append(order, "module "+m.Slug)
+				for _, l := range m.Lessons {
+					lessons[l.Slug] = lessonRef{l, m.Slug}
+					order = append(order, "lesson "+l.Slug)
+				}
+			}
+		}
+		return courses, modules, lessons, order
+	}
+	wantCourses, wantModules, wantLessons, wantOrder := index(wantTree)
+	gotCourses, gotModules, gotLessons, gotOrder := index(gotTree)
+
+	var diffs []string
+	for _, key := range wantOrder {
+		kind, slug, _ := strings.Cut(key, " ")
+		switch kind {
+		case "course":
+			g, ok := gotCourses[slug]
+			if !ok {
+				diffs = append(diffs, key+": missing")
+				continue
+			}
+			diffs = append(diffs, diffFields(key, wantCourses[slug].Course, g.Course, "ID")...)
+		case "module":
+			w := wantModules[slug]
+			g, ok := gotModules[slug]
+			if !ok {
+				diffs = append(diffs, key+": missing")
+				continue
+			}
+			if w.Course != g.Course {
+				diffs = append(diffs, fmt.Sprintf("%s: course %q → %q", key, w.Course, g.Course))
+			}
+			diffs = append(diffs, diffFields(key, w.Module, g.Module, "ID", "CourseID", "Course")...)
+		case "lesson":
+			w := wantLessons[slug]
+			g, ok := gotLessons[slug]
+			if !ok {
+				diffs = append(diffs, key+": missing")
+				continue
+			}
+			if w.Module != g.Module {
+				diffs = append(diffs, fmt.Sprintf("%s: module %q → %q", key, w.Module, g.Module))
+			}
+			diffs = append(diffs, diffFields(key, w.Lesson, g.Lesson,
+				"ID", "ModuleID", "BodyMD", "ContentHash", "CreatedAt", "UpdatedAt", "Module", "Sections", "Tasks")...)
+			if !reflect.DeepEqual(w.Requires, g.Requires) {
+				diffs = append(diffs, fmt.Sprintf("%s: requires %v → %v", key, w.Requires, g.Requires))
+			}
+			diffs = append(diffs, diffList(key+" section", w.Sections, g.Sections, func(s content.Section) string { return string(s.Kind) }, "ID", "LessonID")...)
+			diffs = append(diffs, diffList(key+" task", w.Tasks, g.Tasks, func(t content.Task) string { return t.Key }, "ID", "LessonID")...)
+		}
+	}
+
+	wantSet := make(map[string]bool, len(wantOrder))
+	for _, key := range wantOrder {
+		wantSet[key] = true
+	}
+	for _, key := range gotOrder {
+		if !wantSet[key] {
+			diffs = append(diffs, key+": unexpected")
+		}
+	}
+	return diffs, nil
+}
+
+// diffList сравнивает секции или задания урока по позициям.
+func diffList[T any](prefix string, want, got []T, name func(T) string, skip ...string) []string {
+	var diffs []string
+	for i := 0; i < len(want) || i < len(got); i++ {
+		switch {
+		case i >= len(got):
+			diffs = append(diffs, fmt.Sprintf("%s %s: missing", prefix, name(want[i])))
+		case i >= len(want):
+			diffs = append(diffs, fmt.Sprintf("%s %s: unexpected", prefix, name(got[i])))
+		default:
+			diffs = append(diffs, diffFields(prefix+" "+name(want[i]), want[i], got[i], skip...)...)
+		}
+	}
+	return diffs
+}
+
+// diffFields перечисляет различающиеся поля двух структур одного типа, кроме skip.
+func diffFields(prefix string, want, got any, skip ...string) []string {
+	wv, gv := reflect.ValueOf(want), reflect.ValueOf(got)
+	var diffs []string
+	for i := 0; i < wv.NumField(); i++ {
+		field := wv.Type().Field(i).Name
+		if contains(skip, field) {
+			continue
+		}
+		w, g := wv.Field(i).Interface(), gv.Field(i).Interface()
+		if reflect.DeepEqual(w, g) {
+			continue
+		}
+		ws, wok := w.(string)
+		gs, gok := g.(string)
+		if wok && gok {
+			// Показываем строки с места первого расхождения, иначе оно может не попасть в вывод
+			at := 0
+			for at < len(ws) && at < len(gs) && ws[at] == gs[at] {
+				at++
+			}
+			from := strings.LastIndexByte(ws[:at], '\n') + 1
+			w, g = diffSnippet(ws, from), diffSnippet(gs, from)
+		}
+		diffs = append(diffs, fmt.Sprintf("%s: %s %v → %v", prefix, field, w, g))
+	}
+	return diffs
+}
+
+// diffSnippet — начало строки s с позиции from для вывода расхождения.
+func diffSnippet(s string, from int) string {
+	if from > 0 {
+		return strconv.Quote("…" + truncate(s[from:], 60))
+	}
+	return strconv.Quote(truncate(s, 60))
+}
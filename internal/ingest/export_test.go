@@ -0,0 +1,168 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golearning/internal/content"
+	"golearning/internal/db"
+)
+
+// roundTripFixture — небольшой каталог контента: курс с манифестом, явный slug,
+// задания с id и без, <Solution>, <Verify>, requires и ссылки из парного markdown.
+var roundTripFixture = map[string]string{
+	"lessons_mdx/01_Основы/course.yaml": `title: Основы Go
+icon: "🐹"
+description: Первые шаги в Go.
+runner: "1.21"
+`,
+	"lessons_mdx/01_Основы/Глава_01_Первые_шаги/01_Привет.mdx": "# Привет, мир\n\n" +
+		"<Meta>\nslug: hello-world\nreading_time: 6\n</Meta>\n\n" +
+		"<Overview>\n- Программа начинается с `main`\n</Overview>\n\n" +
+		"<Theory>\nПакет `fmt` печатает текст.\n</Theory>\n\n" +
+		"<Examples>\n```go\nfmt.Println(\"hi\")\n```\n</Examples>\n\n" +
+		"<Task id=\"hello\" points=\"10\">\n" +
+		"<Title>Вывести приветствие</Title>\n" +
+		"<Prompt>\nВыведите `Hello, Go!`.\n</Prompt>\n" +
+		"<Hints>\n- Используйте `fmt.Println`\n</Hints>\n" +
+		"<StarterCode>\n```go\npackage main\n\nfunc main() {\n}\n```\n</StarterCode>\n" +
+		"<ExpectedOutput>\nHello, Go!\n</ExpectedOutput>\n" +
+		"<RequiredPatterns>fmt.Println</RequiredPatterns>\n" +
+		"<Solution>\n```go\npackage main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"Hello, Go!\")\n}\n```\n</Solution>\n" +
+		"</Task>\n\n" +
+		"<Task points=\"20\" mode=\"manual\">\n" +
+		"<Title>Собрать проект</Title>\n" +
+		"<Prompt>\nСоздайте модуль с `main.go`.\n</Prompt>\n" +
+		"<Criteria>\n- Проект собирается\n</Criteria>\n" +
+		"<Verify>\nbuild\nfile main.go\n</Verify>\n" +
+		"<Solution>\n```go\npackage main\n\nfunc main() {}\n```\n</Solution>\n" +
+		"</Task>\n",
+	"lessons_mdx/01_Основы/Глава_01_Первые_шаги/02_Переменные.mdx": "# Переменные\n\n" +
+		"<Meta>\nrequires: [hello-world]\n</Meta>\n\n" +
+		"<Overview>\n- `var` и `:=`\n</Overview>\n\n" +
+		"<Theory>\nПеременная хранит значение.\n</Theory>\n\n" +
+		"<Examples>\n```go\nx := 1\n```\n</Examples>\n\n" +
+		"<Task id=\"sum\" points=\"5\" mode=\"manual\">\n" +
+		"<Title>Сумма</Title>\n" +
+		"<Prompt>\nСложите два числа.\n</Prompt>\n" +
+		"</Task>\n",
+	"lessons_ai/01_Основы/Глава_01_Первые_шаги/02_Переменные.md": "# Переменные\n\n" +
+		"## 🔗 Полезные ссылки\n\n- [Tour of Go](https://go.dev/tour)\n",
+}
+
+// writeFiles создаёт файлы files (путь относительно root → содержимое).
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newTestDB открывает чистую БД с применёнными миграциями.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := db.Migrate(database); err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+// newTestRepo — репозиторий контента чистой БД.
+func newTestRepo(t *testing.T) *content.Repository {
+	t.Helper()
+	return content.NewRepository(newTestDB(t))
+}
+
+// quietLog глушит построчный лог импорта на время теста.
+func quietLog(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+}
+
+// importSource записывает источник src в repo; любой неимпортированный урок — провал теста.
+func importSource(t *testing.T, repo *content.Repository, src Source) *ImportReport {
+	t.Helper()
+	ctx := context.Background()
+	tree, err := src.Load(ctx)
+	if err != nil {
+		t.Fatalf("load %s: %v", src.Name(), err)
+	}
+	report, err := NewWriter(repo).Write(ctx, tree)
+	if err != nil {
+		t.Fatalf("write %s: %v\n%s", src.Name(), err, report.Summary())
+	}
+	return report
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	quietLog(t)
+	ctx := context.Background()
+	root := t.TempDir()
+	writeFiles(t, root, roundTripFixture)
+
+	want := newTestRepo(t)
+	importSource(t, want, NewMDXSource(filepath.Join(root, "lessons_mdx")))
+
+	// Пустое сравнение ничего не докажет, если фикстура не дошла до БД
+	tree, err := loadContentTree(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) != 1 || len(tree[0].Modules) != 1 || len(tree[0].Modules[0].Lessons) != 2 {
+		t.Fatalf("imported tree: got %d courses, want 1 course with 1 module and 2 lessons", len(tree))
+	}
+	hello, vars := tree[0].Modules[0].Lessons[0], tree[0].Modules[0].Lessons[1]
+	var keys []string
+	for _, task := range hello.Tasks {
+		keys = append(keys, task.Key)
+	}
+	if !reflect.DeepEqual(keys, []string{"hello", "2"}) {
+		t.Errorf("task keys = %v, want [hello 2]", keys)
+	}
+	if len(hello.Tasks) == 2 && (hello.Tasks[0].Solution == "" || hello.Tasks[1].Solution == "" || hello.Tasks[1].VerifyScript == "") {
+		t.Errorf("solutions or verify script not imported: %+v", hello.Tasks)
+	}
+	if !reflect.DeepEqual(vars.Requires, []string{"hello-world"}) {
+		t.Errorf("requires = %v, want [hello-world]", vars.Requires)
+	}
+	if n := len(vars.Sections); n == 0 || vars.Sections[n-1].Kind != content.SectionLinks {
+		t.Errorf("links from the markdown counterpart not imported: %+v", vars.Sections)
+	}
+
+	out := filepath.Join(t.TempDir(), "lessons")
+	report, err := NewMDXExporter(want, out, ExportOptions{}).Export(ctx)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if report.Lessons != 2 {
+		t.Errorf("exported %d lessons, want 2", report.Lessons)
+	}
+
+	got := newTestRepo(t)
+	importSource(t, got, NewMDXSource(out))
+
+	diffs, err := CompareContent(want, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range diffs {
+		t.Errorf("round trip: %s", d)
+	}
+}
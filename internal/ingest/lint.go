@@ -0,0 +1,406 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golearning/internal/content"
+	"golearning/internal/practice"
+)
+
+// Уровни замечаний линтера.
+const (
+	LintError   = "error"   // урок нельзя публиковать: импорт упадёт или задание нерешаемо
+	LintWarning = "warning" // стоит исправить, но импорт пройдёт
+)
+
+// Допустимый диапазон очков за задание.
+const (
+	MinTaskPoints = 1
+	MaxTaskPoints = 100
+)
+
+// lintRequiredSections — секции, без которых урок неполон.
+var lintRequiredSections = []string{"Overview", "Theory", "Examples"}
+
+// LintIssue — замечание линтера к файлу урока.
+type LintIssue struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Lesson   string `json:"lesson,omitempty"` // slug урока
+	Task     string `json:"task,omitempty"`   // id задания
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// String форматирует замечание как сообщение компилятора: файл:строка: текст.
+func (i LintIssue) String() string {
+	pos := i.File
+	if i.Line > 0 {
+		pos = fmt.Sprintf("%s:%d", pos, i.Line)
+	}
+	return fmt.Sprintf("%s: %s [%s] %s", pos, i.Severity, i.Rule, i.Message)
+}
+
+// LintReport — итог проверки каталога.
+type LintReport struct {
+	Lessons  int         `json:"lessons"`
+	Errors   int         `json:"errors"`
+	Warnings int         `json:"warnings"`
+	Issues   []LintIssue `json:"issues"`
+}
+
+// LintOptions — настройки линтера.
+type LintOptions struct {
+	SkipCompile bool // не компилировать StarterCode и не запускать эталонные решения
+}
+
+// Linter проверяет MDX-уроки до импорта: разбирает их тем же парсером и по тем же
+// правилам именования, что и MDXSource, но ничего не пишет в БД.
+type Linter struct {
+	baseDir string
+	checker *practice.Checker // Запускает эталонные решения
+	opts    LintOptions
+	report  *LintReport
+
+	fset *token.FileSet
+	imp  types.Importer
+
+	slugs   map[string]string // slug урока -> файл
+	links   []lintLink
+	courses map[string]string // slug курса -> каталог
+	modules map[string]string // slug модуля -> каталог
+}
+
+// lintLink — ссылка на другой урок, проверяемая после обхода всех файлов.
+type lintLink struct {
+	file, lesson string
+	line         int
+	target       string
+	requires     bool // slug из requires в <Meta>
+}
+
+// NewLinter создаёт линтер каталога MDX-уроков.
+func NewLinter(baseDir string, opts LintOptions) *Linter {
+	fset := token.NewFileSet()
+	return &Linter{
+		baseDir: baseDir,
+		checker: practice.NewChecker(practice.NewLocalRunner(), nil, nil),
+		opts:    opts,
+		fset:    fset,
+		imp:     importer.ForCompiler(fset, "source", nil),
+		slugs:   make(map[string]string),
+		courses: make(map[string]string),
+		modules: make(map[string]string),
+	}
+}
+
+// Lint проверяет все уроки каталога. Ошибка возвращается, только если каталог
+// не удалось обойти; замечания к урокам — в отчёте.
+func (l *Linter) Lint(ctx context.Context) (*LintReport, error) {
+	l.report = &LintReport{Issues: []LintIssue{}}
+
+	guides, err := findGuides(l.baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("find guides: %w", err)
+	}
+	for _, guide := range guides {
+		course, err := courseFromDir(guide)
+		if err != nil {
+			l.add(LintIssue{File: filepath.Join(guide.Path, ManifestFile), Rule: "manifest", Severity: LintError, Message: err.Error()})
+		}
+		l.checkUnique(l.courses, course.Slug, guide.Path, "курса")
+
+		chapters, err := findChapters(guide.Path)
+		if err != nil {
+			return nil, fmt.Errorf("find chapters of %s: %w", guide.Title, err)
+		}
+		for _, chapter := range chapters {
+			l.checkUnique(l.modules, slugify(chapter.Title), chapter.Path, "модуля")
+
+			lessons, err := findLessons(chapter.Path, mdxExts)
+			if err != nil {
+				return nil, fmt.Errorf("find lessons of %s: %w", chapter.Title, err)
+			}
+			titles := make(map[string]string)
+			for _, lessonFile := range lessons {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				l.lintLesson(ctx, lessonFile, titles)
+			}
+		}
+	}
+	l.checkLinks()
+
+	sort.SliceStable(l.report.Issues, func(i, j int) bool {
+		a, b := l.report.Issues[i], l.report.Issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return l.report, nil
+}
+
+// lintLesson проверяет один файл урока. titles — заголовки уже проверенных уроков модуля.
+func (l *Linter) lintLesson(ctx context.Context, lessonFile DirEntry, titles map[string]string) {
+	file := lessonFile.Path
+	data, err := os.ReadFile(file)
+	if err != nil {
+		l.add(LintIssue{File: file, Rule: "read", Severity: LintError, Message: err.Error()})
+		return
+	}
+	src := string(data)
+	l.report.Lessons++
+
+	// Урок с ошибками разбора всё равно проверяем дальше — парсер возвращает то, что разобрал
+	parsed, err := ParseMDXLesson(src)
+	var mdxErrs MDXErrors
+	if errors.As(err, &mdxErrs) {
+		for _, e := range mdxErrs {
+			l.add(LintIssue{File: file, Line: e.Line, Rule: "mdx", Severity: LintError, Message: e.Msg})
+		}
+	}
+
+	title, slug := lessonSlug(parsed, lessonFile)
+	if other, dup := l.slugs[slug]; dup {
+		l.add(LintIssue{File: file, Lesson: slug, Rule: "unique", Severity: LintError,
+			Message: fmt.Sprintf("slug урока %q уже занят: %s — при импорте один урок перезапишет другой", slug, other)})
+	} else {
+		l.slugs[slug] = file
+	}
+	if other, dup := titles[title]; dup {
+		l.add(LintIssue{File: file, Lesson: slug, Rule: "unique", Severity: LintWarning,
+			Message: fmt.Sprintf("заголовок «%s» повторяется в модуле: %s", title, other)})
+	} else {
+		titles[title] = file
+	}
+
+	present := make(map[content.SectionKind]bool)
+	for _, sec := range parsed.Sections {
+		present[sec.Kind] = true
+	}
+	for _, tag := range lintRequiredSections {
+		for _, st := range mdxSectionTags {
+			if st.Tag == tag && !present[st.Kind] {
+				l.add(LintIssue{File: file, Lesson: slug, Rule: "sections", Severity: LintError,
+					Message: fmt.Sprintf("нет обязательной секции <%s> (%s)", tag, st.Title)})
+			}
+		}
+	}
+
+	for _, task := range parsed.Tasks {
+		l.lintTask(ctx, file, slug, task)
+	}
+
+	for _, req := range parsed.Meta.Requires {
+		l.links = append(l.links, lintLink{file: file, lesson: slug, line: lineOf(src, req), target: req, requires: true})
+	}
+	for _, sec := range parsed.Sections {
+		l.collectLinks(file, slug, src, sec.Body)
+	}
+	for _, task := range parsed.Tasks {
+		l.collectLinks(file, slug, src, task.Prompt)
+	}
+
+	// Ссылки из парного файла нужны только уроку без своей секции <Links>
+	if counterpart := markdownCounterpart(file); counterpart != file && !present[content.SectionLinks] {
+		if _, err := os.Stat(counterpart); err != nil {
+			l.add(LintIssue{File: file, Lesson: slug, Rule: "counterpart", Severity: LintWarning,
+				Message: fmt.Sprintf("нет парного markdown-файла %s: ссылки из него не попадут в урок", counterpart)})
+		}
+	}
+}
+
+// lintTask проверяет очки, стартовый код и эталонное решение задания. Неверный mode
+// и нечисловые points отмечает парсер (правило mdx).
+func (l *Linter) lintTask(ctx context.Context, file, slug string, task MDXTask) {
+	issue := LintIssue{File: file, Line: task.Line, Lesson: slug, Task: task.ID, Severity: LintError}
+
+	if task.Points < MinTaskPoints || task.Points > MaxTaskPoints {
+		issue.Rule = "points"
+		issue.Message = fmt.Sprintf("очки задания %d вне диапазона %d–%d", task.Points, MinTaskPoints, MaxTaskPoints)
+		l.add(issue)
+	}
+
+	if task.Mode == "auto" && task.StarterCode != "" && !l.opts.SkipCompile {
+		if syntaxErr, typeErr := l.compile(task.StarterCode); syntaxErr != nil {
+			issue.Rule = "starter-code"
+			issue.Message = "StarterCode не компилируется: " + syntaxErr.Error()
+			l.add(issue)
+		} else if typeErr != nil {
+			issue.Rule = "starter-code"
+			issue.Severity = LintWarning
+			issue.Message = "StarterCode не собирается без доработки: " + typeErr.Error()
+			l.add(issue)
+		}
+	}
+
+	if task.Mode == "auto" && task.Solution != "" {
+		issue.Rule = "solution"
+		issue.Severity = LintError
+		if l.opts.SkipCompile {
+			// Без запуска проверяем только обязательные паттерны
+			for _, p := range strings.Split(task.RequiredPatterns, "|") {
+				if p = strings.TrimSpace(p); p != "" && !strings.Contains(task.Solution, p) {
+					issue.Message = fmt.Sprintf("в эталонном решении нет обязательного паттерна %q", p)
+					l.add(issue)
+				}
+			}
+		} else if err := l.verifySolution(ctx, task); err != nil {
+			issue.Message = err.Error()
+			l.add(issue)
+		}
+	}
+}
+
+// verifySolution запускает эталонное решение задания так же, как при импорте.
+func (l *Linter) verifySolution(ctx context.Context, task MDXTask) error {
+	t := task.contentTask()
+	return verifySolution(ctx, l.checker, &t)
+}
+
+// compile разбирает стартовый код и проверяет типы. Синтаксическая ошибка — дефект
+// задания; ошибка типов может быть намеренной (заготовка вызывает метод, который
+// ученик должен написать). Неиспользованные переменные и импорты («мягкие» ошибки)
+// не считаются. Фрагменты без объявления пакета не проверяются.
+func (l *Linter) compile(code string) (syntaxErr, typeErr error) {
+	if _, err := parser.ParseFile(l.fset, "main.go", code, parser.PackageClauseOnly); err != nil {
+		return nil, nil
+	}
+	f, err := parser.ParseFile(l.fset, "main.go", code, parser.SkipObjectResolution)
+	if err != nil {
+		return err, nil
+	}
+	for _, spec := range f.Imports {
+		path := strings.Trim(spec.Path.Value, `"`)
+		if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+			return fmt.Errorf("импорт %s: в песочнице доступна только стандартная библиотека", path), nil
+		}
+	}
+
+	conf := types.Config{
+		Importer: l.imp,
+		Error: func(err error) {
+			var te types.Error
+			if errors.As(err, &te) && te.Soft {
+				return
+			}
+			if typeErr == nil {
+				typeErr = err
+			}
+		},
+	}
+	conf.Check("main", l.fset, []*ast.File{f}, nil)
+	return nil, typeErr
+}
+
+var (
+	lintLinkRe       = regexp.MustCompile(`\[[^\]\n]*\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	lintInlineCodeRe = regexp.MustCompile("`[^`\n]*`")
+)
+
+// collectLinks запоминает ссылки на уроки из markdown-текста: /lessons/{slug}
+// и относительные пути к .md/.mdx. Блоки и inline-код пропускаются.
+func (l *Linter) collectLinks(file, slug, src, body string) {
+	fence := ""
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		line = lintInlineCodeRe.ReplaceAllString(line, "")
+		for _, match := range lintLinkRe.FindAllStringSubmatch(line, -1) {
+			target := match[1]
+			if strings.HasPrefix(target, "/lessons/") || isRelativeLessonPath(target) {
+				l.links = append(l.links, lintLink{file: file, lesson: slug, line: lineOf(src, "]("+target), target: target})
+			}
+		}
+	}
+}
+
+// checkLinks проверяет собранные ссылки, когда известны slug'и всех уроков.
+func (l *Linter) checkLinks() {
+	for _, link := range l.links {
+		issue := LintIssue{File: link.file, Line: link.line, Lesson: link.lesson, Rule: "links", Severity: LintError}
+		switch {
+		case link.requires:
+			if _, ok := l.slugs[link.target]; !ok {
+				issue.Rule = "requires"
+				issue.Message = fmt.Sprintf("requires: урок %q не найден", link.target)
+				l.add(issue)
+			}
+		case strings.HasPrefix(link.target, "/lessons/"):
+			target := strings.TrimPrefix(link.target, "/lessons/")
+			target, _, _ = strings.Cut(target, "#")
+			target, _, _ = strings.Cut(target, "?")
+			if _, ok := l.slugs[strings.TrimSuffix(target, "/")]; !ok {
+				issue.Message = fmt.Sprintf("ссылка %s: урок не найден", link.target)
+				l.add(issue)
+			}
+		default:
+			target, _, _ := strings.Cut(link.target, "#")
+			if _, err := os.Stat(filepath.Join(filepath.Dir(link.file), filepath.FromSlash(target))); err != nil {
+				issue.Message = fmt.Sprintf("ссылка %s: файл не найден", link.target)
+				l.add(issue)
+			}
+		}
+	}
+}
+
+// checkUnique проверяет, что slug курса или модуля не повторяется.
+func (l *Linter) checkUnique(seen map[string]string, slug, path, what string) {
+	if other, dup := seen[slug]; dup {
+		l.add(LintIssue{File: path, Rule: "unique", Severity: LintError,
+			Message: fmt.Sprintf("slug %s %q уже занят: %s", what, slug, other)})
+		return
+	}
+	seen[slug] = path
+}
+
+func (l *Linter) add(issue LintIssue) {
+	if issue.Severity == LintError {
+		l.report.Errors++
+	} else {
+		l.report.Warnings++
+	}
+	l.report.Issues = append(l.report.Issues, issue)
+}
+
+// isRelativeLessonPath сообщает, что ссылка ведёт на файл урока рядом с текущим.
+func isRelativeLessonPath(target string) bool {
+	if strings.Contains(target, "://") || strings.HasPrefix(target, "/") || strings.HasPrefix(target, "#") {
+		return false
+	}
+	path, _, _ := strings.Cut(target, "#")
+	return strings.HasSuffix(path, ".mdx") || strings.HasSuffix(path, ".md")
+}
+
+// lineOf возвращает номер строки первого вхождения s в src (0 — не найдено).
+func lineOf(src, s string) int {
+	i := strings.Index(src, s)
+	if i < 0 {
+		return 0
+	}
+	return strings.Count(src[:i], "\n") + 1
+}
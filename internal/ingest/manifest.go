@@ -0,0 +1,101 @@
+package ingest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/version"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golearning/internal/content"
+	"golearning/internal/practice"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile — манифест курса в корне его каталога.
+const ManifestFile = "course.yaml"
+
+// defaultCourseIcon — иконка курса без icon в манифесте.
+const defaultCourseIcon = "📚"
+
+// ErrRunnerTooOld — курсу нужна более новая версия Go, чем у runner'а заданий.
+var ErrRunnerTooOld = errors.New("runner too old")
+
+// CourseManifest — course.yaml: свойства курса, которые не выводятся из имени каталога.
+type CourseManifest struct {
+	Title       string `yaml:"title"`                 // Пустой — заголовок из имени каталога
+	Icon        string `yaml:"icon,omitempty"`        // Пустой — 📚
+	Description string `yaml:"description,omitempty"` // Markdown
+	Order       int    `yaml:"order,omitempty"`       // 0 — номер из имени каталога
+	Runner      string `yaml:"runner,omitempty"`      // Минимальная версия Go runner'а, например "1.22"
+}
+
+// readManifest читает course.yaml каталога курса. Манифеста может не быть — тогда nil.
+func readManifest(dir string) (*CourseManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m CourseManifest
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse %s: %w", ManifestFile, err)
+	}
+	if err := checkRunner(m.Runner); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// checkRunner проверяет, что runner заданий поддерживает нужную курсу версию Go.
+func checkRunner(required string) error {
+	if required == "" {
+		return nil
+	}
+	v := "go" + strings.TrimPrefix(required, "go")
+	if !version.IsValid(v) {
+		return fmt.Errorf("invalid runner version %q", required)
+	}
+	if version.Compare(v, "go"+practice.GoVersion) > 0 {
+		return fmt.Errorf("%w: course needs %s, runner has go%s", ErrRunnerTooOld, v, practice.GoVersion)
+	}
+	return nil
+}
+
+// courseFromDir собирает курс из каталога: заголовок и номер — из имени каталога,
+// остальное и переопределения — из course.yaml.
+func courseFromDir(guide DirEntry) (content.Course, error) {
+	course := content.Course{
+		Title:      guide.Title,
+		Icon:       defaultCourseIcon,
+		OrderIndex: guide.Order,
+	}
+
+	m, err := readManifest(guide.Path)
+	if err != nil {
+		return course, err
+	}
+	if m != nil {
+		if m.Title != "" {
+			course.Title = m.Title
+		}
+		if m.Icon != "" {
+			course.Icon = m.Icon
+		}
+		if m.Order != 0 {
+			course.OrderIndex = m.Order
+		}
+		course.Description = m.Description
+	}
+	course.Slug = slugify(course.Title)
+	return course, nil
+}
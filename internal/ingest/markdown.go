@@ -5,245 +5,56 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
 	"regexp"
-	"sort"
 	"strconv"
 	"strings"
 
 	"golearning/internal/content"
 )
 
-// MarkdownImporter импортирует уроки из Markdown файлов.
-type MarkdownImporter struct {
-	repo    *content.Repository
-	baseDir string
-}
-
-// NewMarkdownImporter создаёт новый импортёр.
-func NewMarkdownImporter(repo *content.Repository, baseDir string) *MarkdownImporter {
-	return &MarkdownImporter{
-		repo:    repo,
-		baseDir: baseDir,
-	}
-}
-
-// Import импортирует все уроки из директории.
-func (m *MarkdownImporter) Import(ctx context.Context) error {
-	log.Printf("Импорт уроков из: %s", m.baseDir)
-
-	// Находим все руководства (верхний уровень)
-	guides, err := m.findGuides()
-	if err != nil {
-		return fmt.Errorf("find guides: %w", err)
-	}
-
-	// Иконки для курсов
-	courseIcons := map[int]string{
-		1: "📘", // Руководство по языку Go
-		2: "🌐", // Веб-программирование
-		3: "🚀", // Продвинутое программирование
-	}
-
-	moduleIndex := 0
-	for _, guide := range guides {
-		log.Printf("📚 Руководство: %s", guide.Title)
-
-		// Создаём курс для руководства
-		icon := courseIcons[guide.Order]
-		if icon == "" {
-			icon = "📚"
-		}
-		course := &content.Course{
-			Slug:        m.slugify(guide.Title),
-			Title:       guide.Title,
-			Description: "",
-			Icon:        icon,
-			OrderIndex:  guide.Order,
-		}
-
-		if err := m.repo.CreateCourse(course); err != nil {
-			log.Printf("  ⚠️ Ошибка создания курса: %v", err)
-			continue
-		}
-		log.Printf("  📚 Курс: %s (ID=%d)", course.Title, course.ID)
-
-		// Находим главы внутри руководства
-		chapters, err := m.findChapters(guide.Path)
-		if err != nil {
-			log.Printf("  ⚠️ Ошибка поиска глав: %v", err)
-			continue
-		}
-
-		for _, chapter := range chapters {
-			// Создаём модуль для главы
-			module := &content.Module{
-				CourseID:   course.ID,
-				Slug:       m.slugify(chapter.Title),
-				Title:      chapter.Title,
-				OrderIndex: moduleIndex,
-			}
-
-			if err := m.repo.CreateModule(module); err != nil {
-				log.Printf("  ⚠️ Ошибка создания модуля: %v", err)
-				continue
-			}
-			log.Printf("  📁 Модуль: %s (ID=%d)", module.Title, module.ID)
-			moduleIndex++
-
-			// Находим и импортируем уроки
-			lessons, err := m.findLessons(chapter.Path)
-			if err != nil {
-				log.Printf("    ⚠️ Ошибка поиска уроков: %v", err)
-				continue
-			}
-
-			for _, lessonFile := range lessons {
-				if err := m.importLesson(ctx, module.ID, lessonFile); err != nil {
-					log.Printf("    ⚠️ Ошибка импорта урока %s: %v", lessonFile.Name, err)
-				}
-			}
-		}
-	}
-
-	return nil
-}
-
-// DirEntry представляет директорию или файл.
-type DirEntry struct {
-	Name  string
-	Title string
-	Path  string
-	Order int
-}
-
-// findGuides находит руководства (верхний уровень директорий).
-func (m *MarkdownImporter) findGuides() ([]DirEntry, error) {
-	entries, err := os.ReadDir(m.baseDir)
-	if err != nil {
-		return nil, err
-	}
-
-	var guides []DirEntry
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
+func init() {
+	RegisterSource("md", func(cfg SourceConfig) (Source, error) {
+		if err := requireDir(cfg); err != nil {
+			return nil, err
 		}
-
-		name := entry.Name()
-		// Служебные директории/метаданные — не считаем отдельными курсами.
-		// Например, lessons_mdx/Проекты содержит ТЗ capstone-проектов для страницы /projects.
-		if name == "Проекты" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
-			continue
-		}
-		order, title := m.parseNumberedName(name)
-
-		guides = append(guides, DirEntry{
-			Name:  name,
-			Title: title,
-			Path:  filepath.Join(m.baseDir, name),
-			Order: order,
-		})
-	}
-
-	sort.Slice(guides, func(i, j int) bool {
-		return guides[i].Order < guides[j].Order
+		return NewMarkdownSource(cfg.Dir), nil
 	})
-
-	return guides, nil
 }
 
-// findChapters находит главы внутри руководства.
-func (m *MarkdownImporter) findChapters(guidePath string) ([]DirEntry, error) {
-	entries, err := os.ReadDir(guidePath)
-	if err != nil {
-		return nil, err
-	}
-
-	var chapters []DirEntry
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		name := entry.Name()
-		order, title := m.parseNumberedName(name)
-
-		chapters = append(chapters, DirEntry{
-			Name:  name,
-			Title: title,
-			Path:  filepath.Join(guidePath, name),
-			Order: order,
-		})
-	}
-
-	sort.Slice(chapters, func(i, j int) bool {
-		return chapters[i].Order < chapters[j].Order
-	})
+// markdownExts — расширения файлов уроков каталога Markdown.
+var markdownExts = []string{".md"}
 
-	return chapters, nil
+// MarkdownSource читает уроки из Markdown файлов (lessons_ai).
+type MarkdownSource struct {
+	baseDir string
 }
 
-// findLessons находит файлы уроков в главе.
-func (m *MarkdownImporter) findLessons(chapterPath string) ([]DirEntry, error) {
-	entries, err := os.ReadDir(chapterPath)
-	if err != nil {
-		return nil, err
-	}
-
-	var lessons []DirEntry
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
-			continue
-		}
-
-		name := entry.Name()
-		order, title := m.parseNumberedName(strings.TrimSuffix(name, ".md"))
-
-		lessons = append(lessons, DirEntry{
-			Name:  name,
-			Title: title,
-			Path:  filepath.Join(chapterPath, name),
-			Order: order,
-		})
-	}
-
-	sort.Slice(lessons, func(i, j int) bool {
-		return lessons[i].Order < lessons[j].Order
-	})
-
-	return lessons, nil
+// NewMarkdownSource создаёт источник Markdown уроков из каталога baseDir.
+func NewMarkdownSource(baseDir string) *MarkdownSource {
+	return &MarkdownSource{baseDir: baseDir}
 }
 
-// parseNumberedName парсит имя вида "01_Название_темы" -> (1, "Название темы")
-func (m *MarkdownImporter) parseNumberedName(name string) (int, string) {
-	// Паттерн: "01_..." или "Глава_01_..."
-	re := regexp.MustCompile(`^(\d+)_(.+)$`)
-	if matches := re.FindStringSubmatch(name); len(matches) == 3 {
-		order, _ := strconv.Atoi(matches[1])
-		title := strings.ReplaceAll(matches[2], "_", " ")
-		return order, title
-	}
+// Name возвращает имя источника в реестре.
+func (m *MarkdownSource) Name() string { return "md" }
 
-	// Паттерн: "Глава_01_..."
-	re2 := regexp.MustCompile(`^Глава_(\d+)_(.+)$`)
-	if matches := re2.FindStringSubmatch(name); len(matches) == 3 {
-		order, _ := strconv.Atoi(matches[1])
-		title := strings.ReplaceAll(matches[2], "_", " ")
-		return order, title
-	}
+// Load разбирает все уроки каталога.
+func (m *MarkdownSource) Load(ctx context.Context) (*CourseTree, error) {
+	log.Printf("Импорт уроков из: %s", m.baseDir)
+	return loadDirTree(ctx, m.baseDir, markdownExts, m.loadLesson)
+}
 
-	// Без номера
-	title := strings.ReplaceAll(name, "_", " ")
-	return 0, title
+// LoadFile разбирает один файл урока и возвращает slug его модуля.
+func (m *MarkdownSource) LoadFile(ctx context.Context, path string) (string, *SourceLesson, error) {
+	return loadDirFile(ctx, m.baseDir, path, markdownExts, m.loadLesson)
 }
 
-// importLesson импортирует один урок из Markdown файла.
-func (m *MarkdownImporter) importLesson(ctx context.Context, moduleID int64, lessonFile DirEntry) error {
+// loadLesson разбирает один урок из Markdown файла: секции по заголовкам «##»
+// и задания из раздела практики.
+func (m *MarkdownSource) loadLesson(ctx context.Context, lessonFile DirEntry) (*SourceLesson, error) {
 	// Читаем содержимое файла
 	data, err := os.ReadFile(lessonFile.Path)
 	if err != nil {
-		return fmt.Errorf("read file: %w", err)
+		return nil, fmt.Errorf("read file: %w", err)
 	}
 
 	mdContent := string(data)
@@ -254,9 +65,6 @@ func (m *MarkdownImporter) importLesson(ctx context.Context, moduleID int64, les
 		title = h1
 	}
 
-	// Создаём slug
-	slug := m.slugify(title) + "-" + strconv.Itoa(lessonFile.Order)
-
 	// Оцениваем время чтения (примерно 200 слов в минуту)
 	wordCount := len(strings.Fields(mdContent))
 	readingTime := wordCount / 200
@@ -264,46 +72,24 @@ func (m *MarkdownImporter) importLesson(ctx context.Context, moduleID int64, les
 		readingTime = 5
 	}
 
-	// Создаём урок
-	lesson := &content.Lesson{
-		ModuleID:       moduleID,
-		Slug:           slug,
-		Title:          title,
-		OrderIndex:     lessonFile.Order,
-		SourceURL:      "",
-		BodyMD:         mdContent,
-		ReadingTimeMin: readingTime,
+	lesson := &SourceLesson{
+		Lesson: content.Lesson{
+			Slug:           defaultLessonSlug(title, lessonFile.Order),
+			Title:          title,
+			OrderIndex:     lessonFile.Order,
+			BodyMD:         mdContent,
+			ReadingTimeMin: readingTime,
+			ContentHash:    contentHash(mdContent),
+		},
+		Origin: lessonFile.Path,
 	}
 
-	if err := m.repo.CreateLesson(lesson); err != nil {
-		return fmt.Errorf("create lesson: %w", err)
-	}
-	log.Printf("    📄 Урок: %s (ID=%d, ~%d мин)", title, lesson.ID, readingTime)
-
-	// Удаляем старые секции и задания
-	m.repo.DeleteSectionsByLessonID(lesson.ID)
-	m.repo.DeleteTasksByLessonID(lesson.ID)
-
-	// Парсим и создаём секции
-	sections := m.parseSections(mdContent)
-	for i, sec := range sections {
-		section := &content.Section{
-			LessonID:   lesson.ID,
-			Kind:       sec.Kind,
-			Title:      sec.Title,
-			BodyMD:     sec.Body,
-			OrderIndex: i,
-		}
-		if err := m.repo.CreateSection(section); err != nil {
-			log.Printf("      ⚠️ Ошибка создания секции: %v", err)
-		}
+	for _, sec := range m.parseSections(mdContent) {
+		lesson.Sections = append(lesson.Sections, content.Section{Kind: sec.Kind, Title: sec.Title, BodyMD: sec.Body})
 	}
 
-	// Парсим и создаём задания
-	tasks := m.parseTasks(mdContent)
-	for i, task := range tasks {
-		t := &content.Task{
-			LessonID:         lesson.ID,
+	for _, task := range m.parseTasks(mdContent) {
+		lesson.Tasks = append(lesson.Tasks, SourceTask{Task: content.Task{
 			Title:            task.Title,
 			PromptMD:         task.Prompt,
 			StarterCode:      task.StarterCode,
@@ -311,18 +97,10 @@ func (m *MarkdownImporter) importLesson(ctx context.Context, moduleID int64, les
 			ExpectedOutput:   task.ExpectedOutput,
 			RequiredPatterns: task.RequiredPatterns,
 			Points:           task.Points,
-			OrderIndex:       i,
-		}
-		if err := m.repo.CreateTask(t); err != nil {
-			log.Printf("      ⚠️ Ошибка создания задания: %v", err)
-		}
+		}})
 	}
 
-	if len(tasks) > 0 {
-		log.Printf("      ✅ %d заданий создано", len(tasks))
-	}
-
-	return nil
+	return lesson, nil
 }
 
 // ParsedSection представляет распознанную секцию.
@@ -333,7 +111,7 @@ type ParsedSection struct {
 }
 
 // parseSections парсит секции из Markdown.
-func (m *MarkdownImporter) parseSections(md string) []ParsedSection {
+func (m *MarkdownSource) parseSections(md string) []ParsedSection {
 	var sections []ParsedSection
 
 	// Регулярка для заголовков второго уровня
@@ -381,7 +159,7 @@ func (m *MarkdownImporter) parseSections(md string) []ParsedSection {
 }
 
 // detectSectionKind определяет тип секции по заголовку.
-func (m *MarkdownImporter) detectSectionKind(title string) content.SectionKind {
+func (m *MarkdownSource) detectSectionKind(title string) content.SectionKind {
 	lower := strings.ToLower(title)
 
 	switch {
@@ -401,7 +179,7 @@ func (m *MarkdownImporter) detectSectionKind(title string) content.SectionKind {
 }
 
 // cleanSectionTitle убирает эмодзи из заголовка секции.
-func (m *MarkdownImporter) cleanSectionTitle(title string) string {
+func (m *MarkdownSource) cleanSectionTitle(title string) string {
 	// Убираем известные эмодзи
 	emojis := []string{"💡", "📋", "💻", "⚠️", "📝", "🔗", "📚", "🏋️", "📖"}
 	result := title
@@ -423,7 +201,7 @@ type ParsedTask struct {
 }
 
 // parseTasks парсит задания из секции "Практические задания".
-func (m *MarkdownImporter) parseTasks(md string) []ParsedTask {
+func (m *MarkdownSource) parseTasks(md string) []ParsedTask {
 	var tasks []ParsedTask
 
 	// Находим секцию "Практические задания" — ищем от ## 🏋️ Практические задания до следующего ## или конца
@@ -521,7 +299,7 @@ func (m *MarkdownImporter) parseTasks(md string) []ParsedTask {
 }
 
 // generateStarterCode создаёт начальный код на основе решения.
-func (m *MarkdownImporter) generateStarterCode(solution string) string {
+func (m *MarkdownSource) generateStarterCode(solution string) string {
 	if solution == "" {
 		return `package main
 
@@ -580,7 +358,7 @@ func main() {
 }
 
 // extractStarterCode извлекает начальный код из текста задания.
-func (m *MarkdownImporter) extractStarterCode(taskContent string) string {
+func (m *MarkdownSource) extractStarterCode(taskContent string) string {
 	// Ищем паттерн: **Начальный код:** + блок кода
 	patterns := []string{
 		`(?s)\*\*Начальный код[:\*]*\*\*\s*\n\s*` + "```go\n(.+?)```",
@@ -598,7 +376,7 @@ func (m *MarkdownImporter) extractStarterCode(taskContent string) string {
 }
 
 // extractPoints извлекает баллы из текста задания.
-func (m *MarkdownImporter) extractPoints(taskContent string, idx int) int {
+func (m *MarkdownSource) extractPoints(taskContent string, idx int) int {
 	// Ищем паттерн: **Баллы:** число
 	re := regexp.MustCompile(`\*\*Баллы[:\*]*\*\*\s*(\d+)`)
 	if match := re.FindStringSubmatch(taskContent); len(match) >= 2 {
@@ -611,7 +389,7 @@ func (m *MarkdownImporter) extractPoints(taskContent string, idx int) int {
 }
 
 // extractPrompt извлекает описание задания, убирая код и служебные блоки.
-func (m *MarkdownImporter) extractPrompt(taskContent, title string) string {
+func (m *MarkdownSource) extractPrompt(taskContent, title string) string {
 	prompt := taskContent
 
 	// Убираем заголовок
@@ -645,7 +423,7 @@ func (m *MarkdownImporter) extractPrompt(taskContent, title string) string {
 }
 
 // extractExpectedOutput извлекает ожидаемый вывод из текста задания.
-func (m *MarkdownImporter) extractExpectedOutput(taskContent string) string {
+func (m *MarkdownSource) extractExpectedOutput(taskContent string) string {
 	// Ищем паттерны вида:
 	// **Ожидаемый вывод:**
 	// ```
@@ -668,7 +446,7 @@ func (m *MarkdownImporter) extractExpectedOutput(taskContent string) string {
 }
 
 // extractRequiredPatterns извлекает требуемые паттерны из текста задания.
-func (m *MarkdownImporter) extractRequiredPatterns(taskContent string) string {
+func (m *MarkdownSource) extractRequiredPatterns(taskContent string) string {
 	// Ищем паттерны вида:
 	// **Используйте:** `for`, `if`
 	// **Должно быть:** fmt.Println
@@ -697,7 +475,7 @@ func (m *MarkdownImporter) extractRequiredPatterns(taskContent string) string {
 }
 
 // computeExpectedOutput вычисляет ожидаемый вывод из решения.
-func (m *MarkdownImporter) computeExpectedOutput(solutionCode string) string {
+func (m *MarkdownSource) computeExpectedOutput(solutionCode string) string {
 	// Простой парсинг: ищем fmt.Println("...") и извлекаем строки
 	re := regexp.MustCompile(`fmt\.Print(?:ln|f)?\s*\(\s*"([^"]*)"`)
 	matches := re.FindAllStringSubmatch(solutionCode, -1)
@@ -717,7 +495,7 @@ func (m *MarkdownImporter) computeExpectedOutput(solutionCode string) string {
 }
 
 // generateTests создаёт простые тесты для задания.
-func (m *MarkdownImporter) generateTests(solution string, taskNum int) string {
+func (m *MarkdownSource) generateTests(solution string, taskNum int) string {
 	// Базовый тест — просто проверяем, что код компилируется и запускается
 	return fmt.Sprintf(`package main
 
@@ -749,48 +527,10 @@ func TestTask%d(t *testing.T) {
 }
 
 // extractH1 извлекает заголовок первого уровня.
-func (m *MarkdownImporter) extractH1(md string) string {
+func (m *MarkdownSource) extractH1(md string) string {
 	re := regexp.MustCompile(`(?m)^# (.+)$`)
 	if match := re.FindStringSubmatch(md); len(match) >= 2 {
 		return strings.TrimSpace(match[1])
 	}
 	return ""
 }
-
-// slugify преобразует строку в slug.
-func (m *MarkdownImporter) slugify(s string) string {
-	// Транслитерация кириллицы
-	translit := map[rune]string{
-		'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
-		'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
-		'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
-		'ф': "f", 'х': "h", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "sch",
-		'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
-		'А': "a", 'Б': "b", 'В': "v", 'Г': "g", 'Д': "d", 'Е': "e", 'Ё': "yo",
-		'Ж': "zh", 'З': "z", 'И': "i", 'Й': "y", 'К': "k", 'Л': "l", 'М': "m",
-		'Н': "n", 'О': "o", 'П': "p", 'Р': "r", 'С': "s", 'Т': "t", 'У': "u",
-		'Ф': "f", 'Х': "h", 'Ц': "ts", 'Ч': "ch", 'Ш': "sh", 'Щ': "sch",
-		'Ъ': "", 'Ы': "y", 'Ь': "", 'Э': "e", 'Ю': "yu", 'Я': "ya",
-	}
-
-	var result strings.Builder
-	for _, r := range s {
-		if t, ok := translit[r]; ok {
-			result.WriteString(t)
-		} else if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
-			result.WriteRune(r)
-		} else if r == ' ' || r == '-' || r == '_' {
-			result.WriteRune('-')
-		}
-	}
-
-	// Убираем множественные дефисы
-	slug := result.String()
-	for strings.Contains(slug, "--") {
-		slug = strings.ReplaceAll(slug, "--", "-")
-	}
-	slug = strings.Trim(slug, "-")
-	slug = strings.ToLower(slug)
-
-	return slug
-}
@@ -0,0 +1,452 @@
+package ingest
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golearning/internal/content"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MDX-урок — Markdown с компонентами-тегами (<Overview>, <Task> и т.д.).
+// Разбор идёт в два шага: токенизатор строит дерево компонентов, не заглядывая
+// в блоки кода, inline-код и HTML-комментарии, а затем дерево проверяется по схеме
+// и превращается в типизированный урок MDXLesson.
+
+// MDXError — ошибка разбора MDX с номером строки.
+type MDXError struct {
+	Line int
+	Msg  string
+}
+
+func (e *MDXError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// MDXErrors — все ошибки разбора одного файла.
+type MDXErrors []*MDXError
+
+func (e MDXErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// MDXNode — компонент MDX в дереве разбора.
+type MDXNode struct {
+	Tag      string
+	Attrs    map[string]string
+	Body     string // Исходный текст между открывающим и закрывающим тегом
+	Children []*MDXNode
+	Line     int
+}
+
+// Child возвращает первый вложенный компонент с тегом tag или nil.
+func (n *MDXNode) Child(tag string) *MDXNode {
+	for _, c := range n.Children {
+		if c.Tag == tag {
+			return c
+		}
+	}
+	return nil
+}
+
+// mdxTagSpec — где может стоять компонент и что в нём допустимо.
+type mdxTagSpec struct {
+	parent    string   // Родительский компонент; "" — верхний уровень урока
+	attrs     []string // Допустимые атрибуты
+	container bool     // Содержит только компоненты, а не Markdown
+}
+
+var mdxSchema = map[string]mdxTagSpec{
+	"Meta":             {},
+	"Overview":         {},
+	"Theory":           {},
+	"Syntax":           {},
+	"Examples":         {},
+	"Pitfalls":         {},
+	"Links":            {},
+	"Task":             {attrs: []string{"id", "points", "mode"}, container: true},
+	"Title":            {parent: "Task"},
+	"Prompt":           {parent: "Task"},
+	"Criteria":         {parent: "Task"},
+	"Hints":            {parent: "Task"},
+	"StarterCode":      {parent: "Task"},
+	"ExpectedOutput":   {parent: "Task"},
+	"Tests":            {parent: "Task"},
+	"RequiredPatterns": {parent: "Task"},
+	"Verify":           {parent: "Task"},
+	"Solution":         {parent: "Task"},
+}
+
+// mdxSectionTags — теги секций урока в порядке вывода.
+var mdxSectionTags = []struct {
+	Tag   string
+	Kind  content.SectionKind
+	Title string
+}{
+	{"Overview", content.SectionOverview, "Ключевые идеи"},
+	{"Theory", content.SectionTheory, "Теория"},
+	{"Syntax", content.SectionSyntax, "Синтаксис"},
+	{"Examples", content.SectionExamples, "Примеры кода"},
+	{"Pitfalls", content.SectionPitfalls, "Частые ошибки"},
+	{"Links", content.SectionLinks, "Полезные ссылки"},
+}
+
+var (
+	mdxTagRe  = regexp.MustCompile(`^<(/?)([A-Z][A-Za-z0-9]*)((?:\s+[A-Za-z_][\w-]*\s*=\s*(?:"[^"]*"|'[^']*'))*)\s*(/?)>`)
+	mdxAttrRe = regexp.MustCompile(`([A-Za-z_][\w-]*)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// mdxOpen — открытый компонент при разборе.
+type mdxOpen struct {
+	node      *MDXNode
+	bodyStart int
+}
+
+// mdxTokenizer строит дерево компонентов MDX.
+type mdxTokenizer struct {
+	src   string
+	stack []mdxOpen
+	errs  MDXErrors
+	h1    string // Первый заголовок первого уровня вне компонентов и блоков кода
+}
+
+// ParseMDXTree разбирает MDX в дерево компонентов. Корень — узел без тега.
+// Теги внутри блоков кода (```/~~~), inline-кода и HTML-комментариев не распознаются.
+func ParseMDXTree(src string) (*MDXNode, MDXErrors) {
+	root, _, errs := parseMDXTree(src)
+	return root, errs
+}
+
+func parseMDXTree(src string) (*MDXNode, string, MDXErrors) {
+	t := &mdxTokenizer{src: src}
+	root := &MDXNode{Line: 1}
+	t.stack = []mdxOpen{{node: root}}
+
+	var fence string // Открывающая последовательность текущего блока кода
+	fenceLine := 0
+	inComment := false
+	offset := 0
+	for lineNo := 1; offset < len(src); lineNo++ {
+		end := strings.IndexByte(src[offset:], '\n')
+		if end < 0 {
+			end = len(src)
+		} else {
+			end += offset
+		}
+		line := src[offset:end]
+
+		trimmed := strings.TrimLeft(line, " \t")
+		switch {
+		case inComment:
+		case fence != "":
+			// Закрывающая строка — только символы ограждения, не меньше открывающих
+			if rest := strings.TrimRight(trimmed, " \t\r"); len(rest) >= len(fence) && strings.Trim(rest, fence[:1]) == "" {
+				fence = ""
+			}
+			offset = end + 1
+			continue
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, trimmed[:1]))]
+			fenceLine = lineNo
+			t.checkText(lineNo)
+			offset = end + 1
+			continue
+		}
+
+		inComment = t.scanLine(line, offset, lineNo, inComment)
+		offset = end + 1
+	}
+
+	if fence != "" {
+		// Незакрытый блок кода тянется до конца файла — так его покажет и Markdown-рендерер
+		t.errs = append(t.errs, &MDXError{Line: fenceLine, Msg: "unclosed code fence " + fence})
+	}
+	for i := len(t.stack) - 1; i > 0; i-- {
+		n := t.stack[i].node
+		t.errs = append(t.errs, &MDXError{Line: n.Line, Msg: fmt.Sprintf("unclosed <%s>", n.Tag)})
+	}
+	return root, t.h1, t.errs
+}
+
+// scanLine ищет теги в строке вне inline-кода и комментариев. Возвращает, остался ли открыт комментарий.
+func (t *mdxTokenizer) scanLine(line string, offset, lineNo int, inComment bool) bool {
+	top := t.stack[len(t.stack)-1].node
+	if len(t.stack) == 1 && t.h1 == "" && !inComment && strings.HasPrefix(line, "# ") {
+		t.h1 = strings.TrimSpace(line[2:])
+	}
+
+	textReported := false
+	for i := 0; i < len(line); {
+		if inComment {
+			end := strings.Index(line[i:], "-->")
+			if end < 0 {
+				return true
+			}
+			i += end + 3
+			inComment = false
+			continue
+		}
+
+		switch c := line[i]; {
+		case c == '`':
+			n := len(line[i:]) - len(strings.TrimLeft(line[i:], "`"))
+			if end := strings.Index(line[i+n:], line[i:i+n]); end >= 0 {
+				i += n + end + n
+			} else {
+				i += n
+			}
+			t.reportText(top, lineNo, &textReported)
+		case strings.HasPrefix(line[i:], "<!--"):
+			i += 4
+			inComment = true
+		case c == '<' && mdxTagRe.MatchString(line[i:]):
+			m := mdxTagRe.FindStringSubmatch(line[i:])
+			t.handleTag(m, offset+i, offset+i+len(m[0]), lineNo)
+			top = t.stack[len(t.stack)-1].node
+			i += len(m[0])
+		case c == '<' && i+1 < len(line) && line[i+1] >= 'A' && line[i+1] <= 'Z':
+			t.errs = append(t.errs, &MDXError{Line: lineNo, Msg: "malformed tag " + truncate(line[i:], 40)})
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		default:
+			t.reportText(top, lineNo, &textReported)
+			i++
+		}
+	}
+	return inComment
+}
+
+// checkText сообщает об открытии блока кода там, где допустимы только компоненты.
+func (t *mdxTokenizer) checkText(lineNo int) {
+	reported := false
+	t.reportText(t.stack[len(t.stack)-1].node, lineNo, &reported)
+}
+
+// reportText сообщает о тексте внутри компонента-контейнера (один раз на строку).
+func (t *mdxTokenizer) reportText(top *MDXNode, lineNo int, reported *bool) {
+	if *reported || top.Tag == "" || !mdxSchema[top.Tag].container {
+		return
+	}
+	*reported = true
+	t.errs = append(t.errs, &MDXError{Line: lineNo, Msg: fmt.Sprintf("unexpected text inside <%s>", top.Tag)})
+}
+
+// handleTag обрабатывает открывающий, закрывающий или самозакрывающийся тег.
+func (t *mdxTokenizer) handleTag(m []string, start, end, lineNo int) {
+	closing, name, rawAttrs, selfClosing := m[1] == "/", m[2], m[3], m[4] == "/"
+
+	if closing {
+		for i := len(t.stack) - 1; i > 0; i-- {
+			open := t.stack[i]
+			if open.node.Tag != name {
+				continue
+			}
+			for j := len(t.stack) - 1; j > i; j-- {
+				n := t.stack[j].node
+				t.errs = append(t.errs, &MDXError{Line: n.Line, Msg: fmt.Sprintf("<%s> is not closed before </%s> at line %d", n.Tag, name, lineNo)})
+			}
+			open.node.Body = t.src[open.bodyStart:start]
+			t.stack = t.stack[:i]
+			return
+		}
+		t.errs = append(t.errs, &MDXError{Line: lineNo, Msg: fmt.Sprintf("unexpected </%s>", name)})
+		return
+	}
+
+	parent := t.stack[len(t.stack)-1].node
+	node := &MDXNode{Tag: name, Attrs: make(map[string]string), Line: lineNo}
+
+	spec, known := mdxSchema[name]
+	switch {
+	case !known:
+		t.errs = append(t.errs, &MDXError{Line: lineNo, Msg: fmt.Sprintf("unknown tag <%s>", name)})
+	case spec.parent != parent.Tag && spec.parent == "":
+		t.errs = append(t.errs, &MDXError{Line: lineNo, Msg: fmt.Sprintf("<%s> must be at the top level, not inside <%s>", name, parent.Tag)})
+	case spec.parent != parent.Tag:
+		t.errs = append(t.errs, &MDXError{Line: lineNo, Msg: fmt.Sprintf("<%s> must be inside <%s>", name, spec.parent)})
+	}
+
+	for _, am := range mdxAttrRe.FindAllStringSubmatch(rawAttrs, -1) {
+		key, value := am[1], am[2]+am[3]
+		if known && !contains(spec.attrs, key) {
+			t.errs = append(t.errs, &MDXError{Line: lineNo, Msg: fmt.Sprintf("unknown attribute %q of <%s>", key, name)})
+			continue
+		}
+		if _, dup := node.Attrs[key]; dup {
+			t.errs = append(t.errs, &MDXError{Line: lineNo, Msg: fmt.Sprintf("duplicate attribute %q of <%s>", key, name)})
+		}
+		node.Attrs[key] = value
+	}
+
+	parent.Children = append(parent.Children, node)
+	if !selfClosing {
+		t.stack = append(t.stack, mdxOpen{node: node, bodyStart: end})
+	}
+}
+
+// MDXLesson — урок, разобранный из MDX.
+type MDXLesson struct {
+	Title    string // Заголовок первого уровня; пустой, если его нет
+	Meta     LessonMeta
+	Sections []MDXSection
+	Tasks    []MDXTask
+}
+
+// ParseMDXLesson разбирает MDX-урок и проверяет его по схеме компонентов.
+// Ошибки возвращаются все сразу (MDXErrors) с номерами строк.
+func ParseMDXLesson(src string) (*MDXLesson, error) {
+	root, h1, errs := parseMDXTree(src)
+	lesson := &MDXLesson{Title: h1}
+
+	seen := make(map[string]int)
+	taskIDs := make(map[string]int) // id задания -> строка первого <Task> с ним
+	for _, n := range root.Children {
+		if line, dup := seen[n.Tag]; dup && n.Tag != "Task" {
+			errs = append(errs, &MDXError{Line: n.Line, Msg: fmt.Sprintf("duplicate <%s> (first at line %d)", n.Tag, line)})
+			continue
+		}
+		seen[n.Tag] = n.Line
+
+		switch n.Tag {
+		case "Meta":
+			dec := yaml.NewDecoder(bytes.NewReader([]byte(n.Body)))
+			dec.KnownFields(true)
+			if err := dec.Decode(&lesson.Meta); err != nil && strings.TrimSpace(n.Body) != "" {
+				msg := strings.Join(strings.Fields(err.Error()), " ")
+				errs = append(errs, &MDXError{Line: n.Line, Msg: "invalid <Meta>: " + msg})
+			}
+		case "Task":
+			task, taskErrs := parseMDXTask(n)
+			errs = append(errs, taskErrs...)
+			// По id задания хранятся отправки: два задания с одним id слились бы в одно
+			if task.ID != "" {
+				if line, dup := taskIDs[task.ID]; dup {
+					errs = append(errs, &MDXError{Line: n.Line, Msg: fmt.Sprintf("duplicate task id %q (first at line %d)", task.ID, line)})
+				} else {
+					taskIDs[task.ID] = n.Line
+				}
+			}
+			lesson.Tasks = append(lesson.Tasks, task)
+		}
+	}
+
+	for _, st := range mdxSectionTags {
+		n := root.Child(st.Tag)
+		if n == nil {
+			continue
+		}
+		if body := strings.TrimSpace(n.Body); body != "" {
+			lesson.Sections = append(lesson.Sections, MDXSection{Kind: st.Kind, Title: st.Title, Body: body})
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool { return errs[i].Line < errs[j].Line })
+		return lesson, errs
+	}
+	return lesson, nil
+}
+
+// parseMDXTask превращает компонент <Task> в задание. Значения по умолчанию
+// (критерии, стартовый код) подставляет импортёр.
+func parseMDXTask(n *MDXNode) (MDXTask, MDXErrors) {
+	var errs MDXErrors
+	task := MDXTask{
+		ID:     strings.TrimSpace(n.Attrs["id"]),
+		Points: 10,
+		Mode:   "auto",
+		Line:   n.Line,
+	}
+
+	if v, ok := n.Attrs["points"]; ok {
+		points, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || points < 0 {
+			errs = append(errs, &MDXError{Line: n.Line, Msg: fmt.Sprintf("invalid points %q of <Task>", v)})
+		} else {
+			task.Points = points
+		}
+	}
+	if v, ok := n.Attrs["mode"]; ok {
+		switch mode := strings.TrimSpace(v); mode {
+		case "auto", "manual":
+			task.Mode = mode
+		default:
+			errs = append(errs, &MDXError{Line: n.Line, Msg: fmt.Sprintf("invalid mode %q of <Task>: want auto or manual", v)})
+		}
+	}
+
+	seen := make(map[string]int)
+	for _, c := range n.Children {
+		if line, dup := seen[c.Tag]; dup {
+			errs = append(errs, &MDXError{Line: c.Line, Msg: fmt.Sprintf("duplicate <%s> in <Task> (first at line %d)", c.Tag, line)})
+			continue
+		}
+		seen[c.Tag] = c.Line
+
+		body := strings.TrimSpace(c.Body)
+		switch c.Tag {
+		case "Title":
+			task.Title = body
+		case "Prompt":
+			task.Prompt = body
+		case "Criteria":
+			task.Criteria = body
+		case "Hints":
+			task.Hints = body
+		case "StarterCode":
+			task.StarterCode = stripCodeFence(body)
+		case "ExpectedOutput":
+			task.ExpectedOutput = body
+		case "Tests":
+			task.Tests = stripCodeFence(body)
+		case "RequiredPatterns":
+			task.RequiredPatterns = body
+		case "Verify":
+			task.Verify = body
+		case "Solution":
+			task.Solution = stripCodeFence(body)
+		}
+	}
+	if task.Title == "" {
+		errs = append(errs, &MDXError{Line: n.Line, Msg: "<Task> without <Title>"})
+	}
+	return task, errs
+}
+
+// stripCodeFence убирает обёртку ```go … ``` вокруг кода.
+func stripCodeFence(code string) string {
+	if !strings.HasPrefix(code, "```") {
+		return code
+	}
+	lines := strings.Split(code, "\n")
+	if len(lines) < 2 || strings.TrimSpace(lines[len(lines)-1]) != "```" {
+		return code
+	}
+	return strings.TrimSpace(strings.Join(lines[1:len(lines)-1], "\n"))
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return s
+}
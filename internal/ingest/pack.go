@@ -0,0 +1,319 @@
+package ingest
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Курс-пак — курсы в раскладке lessons_mdx, упакованные для распространения: архив
+// .zip или .tar.gz либо локальный git-репозиторий (в том числе bare) на заданном ref.
+//
+// Курсы пака — каталоги с course.yaml в его корне контента: lessons_mdx/, если он есть
+// (пак в раскладке этого репозитория), иначе в корне пака. Парные markdown-уроки
+// со ссылками берутся из lessons_ai/ в корне пака. Архив с единственным каталогом
+// верхнего уровня (repo-main/) разбирается так, будто корень пака — этот каталог.
+
+func init() {
+	for _, kind := range []string{"zip", "tar", "git"} {
+		RegisterSource(kind, func(cfg SourceConfig) (Source, error) {
+			return NewPackSource(kind, cfg.Pack, cfg.Ref)
+		})
+	}
+}
+
+// maxPackFile — предел размера одного файла пака: уроки — текст, большие файлы — ошибка сборки пака.
+const maxPackFile = 16 << 20
+
+// ErrUnsafePath — путь в архиве выходит за каталог распаковки.
+var ErrUnsafePath = errors.New("unsafe path in pack")
+
+// PackSource читает курс-пак. Пак распаковывается во временный каталог и разбирается
+// по правилам MDX-каталога; после Load каталог удаляется.
+type PackSource struct {
+	kind string // zip, tar, git
+	path string
+	ref  string // только для git
+}
+
+// NewPackSource создаёт источник пака вида kind (zip, tar, git) по пути path.
+// ref — ветка, тег или коммит git-репозитория; пустой — HEAD.
+func NewPackSource(kind, path, ref string) (*PackSource, error) {
+	if path == "" {
+		return nil, errors.New("pack path is required")
+	}
+	switch kind {
+	case "zip", "tar":
+		if ref != "" {
+			return nil, fmt.Errorf("ref is supported only for git packs")
+		}
+	case "git":
+		if ref == "" {
+			ref = "HEAD"
+		}
+	default:
+		return nil, fmt.Errorf("unknown pack kind %q", kind)
+	}
+	return &PackSource{kind: kind, path: path, ref: ref}, nil
+}
+
+// PackKind определяет вид пака по пути: .zip, .tar.gz/.tgz или каталог git-репозитория.
+// Пустая строка — путь не похож на пак.
+func PackKind(path string) string {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar"
+	}
+	// Bare-репозиторий содержит HEAD в корне, обычный — в .git
+	for _, head := range []string{"HEAD", filepath.Join(".git", "HEAD")} {
+		if info, err := os.Stat(filepath.Join(path, head)); err == nil && !info.IsDir() {
+			return "git"
+		}
+	}
+	return ""
+}
+
+// Name возвращает имя источника в реестре.
+func (p *PackSource) Name() string { return p.kind }
+
+// Load распаковывает пак и разбирает его курсы.
+func (p *PackSource) Load(ctx context.Context) (*CourseTree, error) {
+	if p.kind == "git" {
+		log.Printf("Курс-пак: %s @ %s", p.path, p.ref)
+	} else {
+		log.Printf("Курс-пак: %s", p.path)
+	}
+
+	tmp, err := os.MkdirTemp("", "golearning-pack-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	switch p.kind {
+	case "zip":
+		err = extractZip(p.path, tmp)
+	case "tar":
+		err = extractTarGz(p.path, tmp)
+	case "git":
+		err = extractGit(ctx, p.path, p.ref, tmp)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("extract pack: %w", err)
+	}
+
+	root, err := packRoot(tmp)
+	if err != nil {
+		return nil, err
+	}
+	contentDir := root
+	if info, err := os.Stat(filepath.Join(root, mdxContentDir)); err == nil && info.IsDir() {
+		contentDir = filepath.Join(root, mdxContentDir)
+	}
+	courses, err := findPackCourses(contentDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(courses) == 0 {
+		return nil, fmt.Errorf("no courses with %s in pack %s", ManifestFile, p.path)
+	}
+
+	src := &MDXSource{baseDir: contentDir, mdDir: filepath.Join(root, mdContentDir)}
+	tree, err := loadCourses(ctx, courses, mdxExts, src.loadLesson)
+	if err != nil {
+		return nil, err
+	}
+
+	// Пути во временном каталоге ничего не скажут автору пака — показываем путь внутри пака
+	origin := func(path string) string {
+		if rel, err := filepath.Rel(tmp, path); err == nil {
+			return p.path + ":" + filepath.ToSlash(rel)
+		}
+		return path
+	}
+	tree.Isolated = true
+	for i := range tree.Failed {
+		tree.Failed[i].Lesson = origin(tree.Failed[i].Lesson)
+	}
+	for _, c := range tree.Courses {
+		for _, m := range c.Modules {
+			for i := range m.Lessons {
+				m.Lessons[i].Origin = origin(m.Lessons[i].Origin)
+			}
+		}
+	}
+	return tree, nil
+}
+
+// packRoot возвращает корень распакованного пака: каталог распаковки или
+// единственный каталог верхнего уровня в нём.
+func packRoot(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) != 1 || !entries[0].IsDir() || entries[0].Name() == mdxContentDir {
+		return dir, nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, entries[0].Name(), ManifestFile)); err == nil {
+		return dir, nil // единственный каталог — сам курс
+	}
+	return filepath.Join(dir, entries[0].Name()), nil
+}
+
+// findPackCourses возвращает курсы пака — каталоги contentDir с course.yaml — в порядке номеров.
+func findPackCourses(contentDir string) ([]DirEntry, error) {
+	guides, err := findGuides(contentDir)
+	if err != nil {
+		return nil, fmt.Errorf("find courses: %w", err)
+	}
+	var courses []DirEntry
+	for _, guide := range guides {
+		if _, err := os.Stat(filepath.Join(guide.Path, ManifestFile)); err == nil {
+			courses = append(courses, guide)
+		}
+	}
+	return courses, nil
+}
+
+// packPath возвращает путь распаковки файла name из пака внутри dst.
+func packPath(dst, name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, name)
+	}
+	return filepath.Join(dst, clean), nil
+}
+
+// writePackFile записывает файл пака, не больше maxPackFile.
+func writePackFile(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, maxPackFile+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if n > maxPackFile {
+		return fmt.Errorf("file %s is larger than %d bytes", path, maxPackFile)
+	}
+	return nil
+}
+
+func extractZip(archive, dst string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		// Ссылки и прочие особые файлы в паке не нужны
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		path, err := packPath(dst, f.Name)
+		if err != nil {
+			return err
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("open %s: %w", f.Name, err)
+		}
+		err = writePackFile(path, rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("extract %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func extractTarGz(archive, dst string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	return extractTar(gz, dst)
+}
+
+func extractTar(r io.Reader, dst string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		path, err := packPath(dst, hdr.Name)
+		if err != nil {
+			return err
+		}
+		if err := writePackFile(path, tr); err != nil {
+			return fmt.Errorf("extract %s: %w", hdr.Name, err)
+		}
+	}
+}
+
+// extractGit распаковывает дерево коммита ref из локального репозитория через git archive.
+func extractGit(ctx context.Context, repo, ref, dst string) error {
+	var stderr bytes.Buffer
+	verify := exec.CommandContext(ctx, "git", "-C", repo, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	verify.Stderr = &stderr
+	if err := verify.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("ref %q not found in %s: %s", ref, repo, msg)
+		}
+		return fmt.Errorf("ref %q not found in %s", ref, repo)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "-C", repo, "archive", "--format=tar", ref)
+	stderr.Reset()
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git archive: %w", err)
+	}
+	extractErr := extractTar(out, dst)
+	// Дочитываем вывод, чтобы git не завис на записи в закрытый канал
+	io.Copy(io.Discard, out)
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git archive: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return extractErr
+}
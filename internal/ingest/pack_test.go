@@ -0,0 +1,143 @@
+package ingest
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeZip упаковывает files (путь в архиве → содержимое) в zip-архив path.
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPackLayouts проверяет, что пак в раскладке репозитория (lessons_mdx + lessons_ai),
+// пак с курсами в корне и пак в общем корневом каталоге дают тот же контент, что и
+// импорт каталога: курсы из lessons_ai не импортируются, ссылки из парных markdown не теряются.
+func TestPackLayouts(t *testing.T) {
+	quietLog(t)
+
+	// Как в этом репозитории, у курса в lessons_ai тоже есть манифест
+	nested := map[string]string{"lessons_ai/01_Основы/course.yaml": roundTripFixture["lessons_mdx/01_Основы/course.yaml"]}
+	rootLayout := make(map[string]string)
+	wrapped := make(map[string]string)
+	for name, data := range roundTripFixture {
+		nested[name] = data
+	}
+	for name, data := range nested {
+		rootLayout[strings.TrimPrefix(name, "lessons_mdx/")] = data
+		wrapped["repo-main/"+name] = data
+	}
+	layouts := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"nested", nested},
+		{"root", rootLayout},
+		{"wrapped", wrapped},
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, roundTripFixture)
+
+	for _, layout := range layouts {
+		t.Run(layout.name, func(t *testing.T) {
+			repo := newTestRepo(t)
+			archive := filepath.Join(t.TempDir(), "pack.zip")
+			writeZip(t, archive, layout.files)
+
+			pack, err := NewPackSource("zip", archive, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			report := importSource(t, repo, pack)
+			if len(report.Courses) != 1 || len(report.Lessons) != 2 {
+				t.Fatalf("pack imported %d courses and %d lessons, want 1 and 2: %v", len(report.Courses), len(report.Lessons), report.Lessons)
+			}
+
+			// Тот же контент из каталога не должен ничего переписать
+			report = importSource(t, repo, NewMDXSource(filepath.Join(dir, "lessons_mdx")))
+			if len(report.Changed) != 0 {
+				t.Errorf("re-import of the same content from dir changed lessons %v", report.Changed)
+			}
+		})
+	}
+}
+
+// TestPackSlugCollision проверяет, что пак другого курса не может занять модуль или урок,
+// уже принадлежащий курсу из каталога: импорт отклоняется, прежний урок не меняется.
+func TestPackSlugCollision(t *testing.T) {
+	quietLog(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, roundTripFixture)
+
+	team := func(module, lesson string) map[string]string {
+		return map[string]string{
+			"02_Команда/course.yaml": "title: Team\n",
+			"02_Команда/" + module + "/01_Урок.mdx": "# Что такое Go\n\n" + lesson +
+				"<Theory>\nЧужой текст.\n</Theory>\n",
+		}
+	}
+	cases := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"module", team("Глава_01_Первые_шаги", "")},
+		{"lesson", team("Глава_01_Своё", "<Meta>\nslug: hello-world\n</Meta>\n\n")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newTestRepo(t)
+			importSource(t, repo, NewMDXSource(filepath.Join(dir, "lessons_mdx")))
+			before, err := repo.GetLessonBySlug("hello-world")
+			if err != nil || before == nil {
+				t.Fatalf("hello-world not imported: %v", err)
+			}
+
+			archive := filepath.Join(t.TempDir(), "pack.zip")
+			writeZip(t, archive, tc.files)
+			pack, err := NewPackSource("zip", archive, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tree, err := pack.Load(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := NewWriter(repo).Write(context.Background(), tree); err == nil {
+				t.Fatal("pack took over content of another course without an error")
+			}
+
+			after, err := repo.GetLessonBySlug("hello-world")
+			if err != nil || after == nil {
+				t.Fatalf("hello-world lost: %v", err)
+			}
+			if after.ModuleID != before.ModuleID || after.BodyMD != before.BodyMD || len(after.Tasks) != len(before.Tasks) {
+				t.Errorf("hello-world changed by a rejected pack: module %d → %d, %d → %d tasks",
+					before.ModuleID, after.ModuleID, len(before.Tasks), len(after.Tasks))
+			}
+		})
+	}
+}
@@ -4,73 +4,87 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"time"
 
 	"golearning/internal/content"
 )
 
-// Pipeline — конвейер импорта контента.
+func init() {
+	RegisterSource("web", func(cfg SourceConfig) (Source, error) {
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("base url is required")
+		}
+		return NewPipeline(NewCrawler(cfg.BaseURL), NewParser(), NewLocalRewriter(), cfg.Limit), nil
+	})
+}
+
+// Pipeline — конвейер загрузки контента с сайта: оглавление, страницы, разбор.
 type Pipeline struct {
 	crawler  *Crawler
 	parser   *Parser
 	rewriter Rewriter
-	repo     *content.Repository
+	limit    int // 0 — без ограничения
 }
 
-// NewPipeline создаёт новый pipeline.
-func NewPipeline(crawler *Crawler, parser *Parser, rewriter Rewriter, repo *content.Repository) *Pipeline {
+// NewPipeline создаёт новый pipeline. limit ограничивает количество уроков (0 — без ограничения).
+func NewPipeline(crawler *Crawler, parser *Parser, rewriter Rewriter, limit int) *Pipeline {
 	return &Pipeline{
 		crawler:  crawler,
 		parser:   parser,
 		rewriter: rewriter,
-		repo:     repo,
+		limit:    limit,
 	}
 }
 
-// Run запускает импорт контента.
-func (p *Pipeline) Run(ctx context.Context, limit int) error {
+// Name возвращает имя источника в реестре.
+func (p *Pipeline) Name() string { return "web" }
+
+// Load скачивает и разбирает уроки сайта. Модули сайта не привязаны к курсу.
+// Отпечаток урока — хеш его страницы: неизменённые страницы Writer пропускает.
+func (p *Pipeline) Load(ctx context.Context) (*CourseTree, error) {
 	log.Println("Получение оглавления...")
 
 	toc, err := p.crawler.FetchTOC(ctx)
 	if err != nil {
-		return fmt.Errorf("fetch TOC: %w", err)
+		return nil, fmt.Errorf("fetch TOC: %w", err)
 	}
 
 	log.Printf("Найдено %d уроков", len(toc))
 
-	if limit > 0 && limit < len(toc) {
-		toc = toc[:limit]
-		log.Printf("Ограничение: импортируем только %d уроков", limit)
+	if p.limit > 0 && p.limit < len(toc) {
+		toc = toc[:p.limit]
+		log.Printf("Ограничение: импортируем только %d уроков", p.limit)
 	}
 
 	// Группируем по модулям
-	modules := p.groupByModules(toc)
-
-	for _, mod := range modules {
-		// Создаём или обновляем модуль
-		if err := p.repo.CreateModule(mod.Module); err != nil {
-			return fmt.Errorf("create module %s: %w", mod.Module.Slug, err)
-		}
-		log.Printf("Модуль: %s (ID=%d)", mod.Module.Title, mod.Module.ID)
-
+	tree := &CourseTree{}
+	var course SourceCourse
+	for _, mod := range p.groupByModules(toc) {
+		module := SourceModule{Module: *mod.Module}
 		for _, entry := range mod.Entries {
-			if err := p.processLesson(ctx, entry, mod.Module.ID); err != nil {
+			lesson, err := p.fetchLesson(ctx, entry)
+			if err != nil {
 				log.Printf("Ошибка обработки урока %s: %v", entry.URL, err)
-				continue
+				tree.fail(entry.URL, err)
+			} else {
+				module.Lessons = append(module.Lessons, *lesson)
 			}
 
 			// Пауза между запросами
 			select {
 			case <-ctx.Done():
-				return ctx.Err()
+				return nil, ctx.Err()
 			case <-time.After(500 * time.Millisecond):
 			}
 		}
+		course.Modules = append(course.Modules, module)
 	}
+	tree.Courses = append(tree.Courses, course)
 
-	log.Println("Импорт завершён!")
-	return nil
+	log.Println("Загрузка завершена!")
+	return tree, nil
 }
 
 // ModuleGroup — группа уроков в модуле.
@@ -147,20 +161,20 @@ func (p *Pipeline) moduleSlugToTitle(slug string) string {
 	return title
 }
 
-// processLesson обрабатывает один урок.
-func (p *Pipeline) processLesson(ctx context.Context, entry TOCEntry, moduleID int64) error {
+// fetchLesson скачивает и разбирает один урок.
+func (p *Pipeline) fetchLesson(ctx context.Context, entry TOCEntry) (*SourceLesson, error) {
 	log.Printf("  Загрузка: %s", entry.Title)
 
 	// Скачиваем страницу
 	html, err := p.crawler.FetchPage(ctx, entry.URL)
 	if err != nil {
-		return fmt.Errorf("fetch page: %w", err)
+		return nil, fmt.Errorf("fetch page: %w", err)
 	}
 
 	// Парсим HTML
 	parsed, err := p.parser.Parse(html)
 	if err != nil {
-		return fmt.Errorf("parse: %w", err)
+		return nil, fmt.Errorf("parse: %w", err)
 	}
 
 	// Если заголовок пустой, берём из оглавления
@@ -171,50 +185,24 @@ func (p *Pipeline) processLesson(ctx context.Context, entry TOCEntry, moduleID i
 	// Преобразуем в структурированный урок
 	structured, err := p.rewriter.Rewrite(ctx, parsed, entry)
 	if err != nil {
-		return fmt.Errorf("rewrite: %w", err)
-	}
-
-	// Генерируем slug
-	slug := slugify(parsed.Title)
-
-	// Сохраняем урок
-	lesson := &content.Lesson{
-		ModuleID:       moduleID,
-		Slug:           slug,
-		Title:          structured.Title,
-		OrderIndex:     entry.OrderIndex,
-		SourceURL:      entry.URL,
-		BodyMD:         structured.BodyMD,
-		ReadingTimeMin: structured.ReadingTimeMin,
-	}
-
-	if err := p.repo.CreateLesson(lesson); err != nil {
-		return fmt.Errorf("create lesson: %w", err)
-	}
-
-	log.Printf("    -> Урок сохранён: %s (ID=%d)", lesson.Slug, lesson.ID)
-
-	// Удаляем старые секции и задания
-	p.repo.DeleteSectionsByLessonID(lesson.ID)
-	p.repo.DeleteTasksByLessonID(lesson.ID)
-
-	// Сохраняем секции
-	for i := range structured.Sections {
-		structured.Sections[i].LessonID = lesson.ID
-		if err := p.repo.CreateSection(&structured.Sections[i]); err != nil {
-			log.Printf("    Ошибка сохранения секции: %v", err)
-		}
-	}
-	log.Printf("    -> Секций: %d", len(structured.Sections))
-
-	// Сохраняем задания
-	for i := range structured.Tasks {
-		structured.Tasks[i].LessonID = lesson.ID
-		if err := p.repo.CreateTask(&structured.Tasks[i]); err != nil {
-			log.Printf("    Ошибка сохранения задания: %v", err)
-		}
-	}
-	log.Printf("    -> Заданий: %d", len(structured.Tasks))
-
-	return nil
+		return nil, fmt.Errorf("rewrite: %w", err)
+	}
+
+	lesson := &SourceLesson{
+		Lesson: content.Lesson{
+			Slug:           slugify(parsed.Title),
+			Title:          structured.Title,
+			OrderIndex:     entry.OrderIndex,
+			SourceURL:      entry.URL,
+			BodyMD:         structured.BodyMD,
+			ReadingTimeMin: structured.ReadingTimeMin,
+			ContentHash:    contentHash(entry.URL, entry.ModuleSlug, strconv.Itoa(entry.OrderIndex), html),
+		},
+		Sections: structured.Sections,
+		Origin:   entry.URL,
+	}
+	for _, t := range structured.Tasks {
+		lesson.Tasks = append(lesson.Tasks, SourceTask{Task: t})
+	}
+	return lesson, nil
 }
@@ -0,0 +1,199 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golearning/internal/content"
+	"golearning/internal/practice"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectsDir — каталог с ТЗ capstone-проектов внутри каталога контента.
+const ProjectsDir = "Проекты"
+
+// ProjectMeta — front matter файла ТЗ проекта.
+type ProjectMeta struct {
+	ID         string   `yaml:"id"`
+	Title      string   `yaml:"title"`
+	Subtitle   string   `yaml:"subtitle"`
+	Difficulty string   `yaml:"difficulty"`
+	Order      int      `yaml:"order"`
+	Lessons    []string `yaml:"lessons"` // slug'и связанных уроков
+}
+
+// ProjectImporter импортирует capstone-проекты из Markdown-файлов с front matter.
+type ProjectImporter struct {
+	repo *content.Repository
+	dir  string
+}
+
+// NewProjectImporter создаёт импортёр проектов из каталога dir.
+func NewProjectImporter(repo *content.Repository, dir string) *ProjectImporter {
+	return &ProjectImporter{
+		repo: repo,
+		dir:  dir,
+	}
+}
+
+// Import импортирует все проекты из каталога в одной транзакции и удаляет из БД проекты,
+// файлов которых больше нет. Если хотя бы один файл не удалось импортировать, изменения
+// откатываются и возвращается ErrImportFailed. Внутри InTx используется текущая транзакция
+// (так проекты пишет Writer.WithProjects). Уроки должны быть импортированы раньше —
+// по ним проверяются ссылки в front matter и этапах.
+func (p *ProjectImporter) Import(ctx context.Context) error {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return fmt.Errorf("read projects dir: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".md") || strings.HasPrefix(name, "_") {
+			continue
+		}
+		files = append(files, filepath.Join(p.dir, name))
+	}
+	sort.Strings(files)
+
+	log.Printf("🧩 Проекты: %s", p.dir)
+
+	return p.repo.InTx(func(repo *content.Repository) error {
+		tx := *p
+		tx.repo = repo
+
+		var slugs []string
+		failed := 0
+		for _, path := range files {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			project, err := tx.importProject(path)
+			if err != nil {
+				log.Printf("  ❌ Ошибка импорта проекта %s: %v", filepath.Base(path), err)
+				failed++
+				continue
+			}
+			slugs = append(slugs, project.Slug)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%w: %d project(s)", ErrImportFailed, failed)
+		}
+
+		removed, err := repo.DeleteProjectsExcept(slugs)
+		if err != nil {
+			return fmt.Errorf("delete stale projects: %w", err)
+		}
+		if removed > 0 {
+			log.Printf("  🗑 Удалено устаревших проектов: %d", removed)
+		}
+		return nil
+	})
+}
+
+// importProject импортирует один проект и синхронизирует его этапы.
+func (p *ProjectImporter) importProject(path string) (*content.Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	meta, spec, err := ParseProjectFile(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	project := &content.Project{
+		Slug:        meta.ID,
+		Title:       meta.Title,
+		Subtitle:    meta.Subtitle,
+		Difficulty:  meta.Difficulty,
+		SpecMD:      spec,
+		LessonSlugs: meta.Lessons,
+		OrderIndex:  meta.Order,
+	}
+
+	milestones, err := content.ParseMilestones(project.Slug, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	p.warnUnknownLessons(project.Slug, "", project.LessonSlugs)
+	for i := range milestones {
+		ms := &milestones[i]
+		if ms.VerifyScript != "" {
+			if _, err := practice.ParseVerifyScript(ms.VerifyScript); err != nil {
+				// Этап без корректного скрипта остаётся доступным для ручной отметки
+				log.Printf("    ⚠️ Этап %s: некорректный скрипт проверки: %v", ms.Key, err)
+				ms.VerifyScript = ""
+			}
+		}
+		p.warnUnknownLessons(project.Slug, ms.Key, ms.LessonSlugs)
+	}
+
+	if err := p.repo.UpsertProject(project); err != nil {
+		return nil, err
+	}
+	if err := p.repo.SyncMilestones(project.Slug, milestones); err != nil {
+		return nil, err
+	}
+
+	log.Printf("  🧩 %s (этапов: %d)", project.Title, len(milestones))
+	return project, nil
+}
+
+// warnUnknownLessons предупреждает о ссылках на несуществующие уроки.
+func (p *ProjectImporter) warnUnknownLessons(projectSlug, milestoneKey string, slugs []string) {
+	for _, slug := range slugs {
+		lesson, err := p.repo.GetLessonBySlug(slug)
+		if err != nil || lesson != nil {
+			continue
+		}
+		if milestoneKey != "" {
+			log.Printf("    ⚠️ Этап %s: урок не найден: %s", milestoneKey, slug)
+		} else {
+			log.Printf("    ⚠️ Проект %s: урок не найден: %s", projectSlug, slug)
+		}
+	}
+}
+
+// ParseProjectFile отделяет YAML front matter (между строками ---) от текста ТЗ
+// и проверяет обязательные поля.
+func ParseProjectFile(data string) (ProjectMeta, string, error) {
+	var meta ProjectMeta
+
+	data = strings.TrimPrefix(data, "\ufeff")
+	rest, ok := strings.CutPrefix(data, "---\n")
+	if !ok {
+		return meta, "", fmt.Errorf("front matter is missing")
+	}
+	front, spec, ok := strings.Cut(rest, "\n---\n")
+	if !ok {
+		return meta, "", fmt.Errorf("front matter is not closed")
+	}
+
+	if err := yaml.Unmarshal([]byte(front), &meta); err != nil {
+		return meta, "", fmt.Errorf("parse front matter: %w", err)
+	}
+	if meta.ID == "" || meta.Title == "" {
+		return meta, "", fmt.Errorf("front matter: id and title are required")
+	}
+	if meta.ID != strings.ToLower(meta.ID) || strings.ContainsAny(meta.ID, " /?#") {
+		return meta, "", fmt.Errorf("front matter: id %q must be a lowercase slug", meta.ID)
+	}
+	switch meta.Difficulty {
+	case "", content.DifficultyBeginner, content.DifficultyIntermediate, content.DifficultyAdvanced:
+	default:
+		return meta, "", fmt.Errorf("front matter: unknown difficulty %q", meta.Difficulty)
+	}
+
+	return meta, strings.TrimLeft(spec, "\n"), nil
+}
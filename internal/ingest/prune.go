@@ -0,0 +1,59 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"golearning/internal/content"
+)
+
+// ScanSource разбирает источник и возвращает slug'и его курсов, модулей и уроков.
+// БД не меняется. Если часть уроков не разобралась, список неполон — возвращается ошибка.
+func ScanSource(ctx context.Context, src Source) (content.ContentSet, error) {
+	tree, err := src.Load(ctx)
+	if err != nil {
+		return content.ContentSet{}, fmt.Errorf("scan source: %w", err)
+	}
+	if len(tree.Failed) > 0 {
+		return content.ContentSet{}, fmt.Errorf("scan source: %w: %d lesson(s)", ErrImportFailed, len(tree.Failed))
+	}
+	return tree.Content(), nil
+}
+
+// ConfirmPrune печатает, что будет удалено и сколько записей прогресса это затронет,
+// и спрашивает подтверждение. assumeYes пропускает вопрос (флаг -yes).
+func ConfirmPrune(set *content.PruneSet, assumeYes bool, in io.Reader, out io.Writer) bool {
+	fmt.Fprintf(out, "Будет удалено: курсов %d, модулей %d, уроков %d\n",
+		len(set.Courses), len(set.Modules), len(set.Lessons))
+	for _, c := range set.Courses {
+		fmt.Fprintf(out, "  курс %s «%s»\n", c.Slug, c.Title)
+	}
+	for _, m := range set.Modules {
+		fmt.Fprintf(out, "  модуль %s «%s»\n", m.Slug, m.Title)
+	}
+	for _, l := range set.Lessons {
+		if l.ProgressRows > 0 {
+			fmt.Fprintf(out, "  урок %s «%s» — записей прогресса: %d\n", l.Slug, l.Title, l.ProgressRows)
+		} else {
+			fmt.Fprintf(out, "  урок %s «%s»\n", l.Slug, l.Title)
+		}
+	}
+	if n := set.ProgressRows(); n > 0 {
+		fmt.Fprintf(out, "⚠️ Вместе с уроками удалится записей прогресса: %d (отметки, заметки, отправки, выделения).\n", n)
+		fmt.Fprintln(out, "   Сохранить его можно заранее: go run ./cmd/progress --db <путь> --export progress.json")
+	}
+
+	if assumeYes {
+		return true
+	}
+	fmt.Fprint(out, "Удалить? [y/N]: ")
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", "д", "да":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package ingest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFindStaleScope проверяет, что -prune по каталогу удаляет только убранное из его курсов:
+// пак и демо-данные в той же БД в список на удаление не попадают.
+func TestFindStaleScope(t *testing.T) {
+	quietLog(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, roundTripFixture)
+	repo := newTestRepo(t)
+	src := NewMDXSource(filepath.Join(dir, "lessons_mdx"))
+	importSource(t, repo, src)
+
+	archive := filepath.Join(t.TempDir(), "pack.zip")
+	writeZip(t, archive, map[string]string{
+		"02_Команда/course.yaml":               "title: Team\n",
+		"02_Команда/Глава_01_Своё/01_Урок.mdx": "# Командный урок\n\n<Theory>\nТекст.\n</Theory>\n",
+	})
+	pack, err := NewPackSource("zip", archive, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	importSource(t, repo, pack)
+	importSource(t, repo, NewDemoData())
+
+	keep, err := ScanSource(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := repo.FindStale(keep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !set.Empty() {
+		t.Fatalf("nothing was removed from the dir, but prune selected %+v", set)
+	}
+
+	// Урок, убранный из каталога, — единственное, что нужно удалить
+	removed := keep.Lessons[len(keep.Lessons)-1]
+	if err := os.Remove(filepath.Join(dir, "lessons_mdx/01_Основы/Глава_01_Первые_шаги/02_Переменные.mdx")); err != nil {
+		t.Fatal(err)
+	}
+	keep, err = ScanSource(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err = repo.FindStale(keep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lessons []string
+	for _, l := range set.Lessons {
+		lessons = append(lessons, l.Slug)
+	}
+	if len(set.Courses) != 0 || len(set.Modules) != 0 || len(lessons) != 1 || lessons[0] != removed {
+		t.Errorf("prune selected courses %v, modules %v, lessons %v; want only lesson %s", set.Courses, set.Modules, lessons, removed)
+	}
+}
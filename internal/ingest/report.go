@@ -0,0 +1,119 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golearning/internal/content"
+)
+
+// ErrImportFailed — часть уроков не импортирована; все изменения прогона откатаны.
+var ErrImportFailed = errors.New("import failed")
+
+// importHashVersion входит в хеш урока. Меняйте его вместе с логикой разбора
+// уроков — тогда следующий импорт перезапишет все уроки, а не только изменённые файлы.
+const importHashVersion = "3"
+
+// LessonOutcome — результат импорта одного урока.
+type LessonOutcome int
+
+const (
+	LessonCreated LessonOutcome = iota
+	LessonUpdated
+	LessonUnchanged
+)
+
+// ImportFailure — урок, который не удалось импортировать.
+type ImportFailure struct {
+	Lesson string
+	Err    error
+}
+
+// ImportReport — итог прогона импорта.
+type ImportReport struct {
+	Created   int
+	Updated   int
+	Unchanged int
+	Failed    []ImportFailure
+	Lessons   []string          // slug'и всех импортированных уроков источника, включая неизменённые
+	Changed   []string          // slug'и созданных и обновлённых уроков
+	Renamed   map[string]string // старый slug → новый: заголовок урока в файле сменился (WriteFile)
+	Modules   []string
+	Courses   []string
+}
+
+// Source возвращает slug'и всего контента источника — то, что не должно попасть под удаление.
+func (r *ImportReport) Source() content.ContentSet {
+	return content.ContentSet{Courses: r.Courses, Modules: r.Modules, Lessons: r.Lessons}
+}
+
+func (r *ImportReport) add(slug string, outcome LessonOutcome) {
+	r.Lessons = append(r.Lessons, slug)
+	if outcome != LessonUnchanged {
+		r.Changed = append(r.Changed, slug)
+	}
+	switch outcome {
+	case LessonCreated:
+		r.Created++
+	case LessonUpdated:
+		r.Updated++
+	case LessonUnchanged:
+		r.Unchanged++
+	}
+}
+
+func (r *ImportReport) fail(lesson string, err error) {
+	r.Failed = append(r.Failed, ImportFailure{Lesson: lesson, Err: err})
+}
+
+// Err возвращает ErrImportFailed, если хотя бы один урок не импортирован.
+func (r *ImportReport) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %d lesson(s)", ErrImportFailed, len(r.Failed))
+}
+
+// Summary возвращает сводку прогона для вывода в консоль.
+func (r *ImportReport) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Уроки: создано %d, обновлено %d, без изменений %d, ошибок %d\n",
+		r.Created, r.Updated, r.Unchanged, len(r.Failed))
+	for _, f := range r.Failed {
+		fmt.Fprintf(&b, "  ❌ %s: %v\n", f.Lesson, f.Err)
+	}
+	if len(r.Failed) > 0 {
+		b.WriteString("Изменения не применены: исправьте ошибки и запустите импорт снова\n")
+	}
+	return b.String()
+}
+
+// contentHash возвращает хеш источника урока: всего, от чего зависит результат импорта.
+func contentHash(parts ...string) string {
+	h := sha256.New()
+	h.Write([]byte(importHashVersion))
+	for _, p := range parts {
+		h.Write([]byte{0})
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lessonOutcome сравнивает хеш источника с сохранённым в БД.
+func lessonOutcome(repo *content.Repository, slug, hash string) (LessonOutcome, error) {
+	stored, found, err := repo.GetLessonHash(slug)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case !found:
+		return LessonCreated, nil
+	case stored == hash:
+		return LessonUnchanged, nil
+	default:
+		return LessonUpdated, nil
+	}
+}
@@ -0,0 +1,147 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golearning/internal/content"
+)
+
+// Source — источник контента: каталог MDX или Markdown, сайт, демо-данные.
+// Источник только читает и разбирает контент; в БД дерево записывает Writer.
+type Source interface {
+	// Name — имя, под которым источник зарегистрирован (-source).
+	Name() string
+	// Load разбирает источник целиком. Уроки, которые не удалось разобрать,
+	// попадают в CourseTree.Failed; ошибка — если источник недоступен вовсе.
+	Load(ctx context.Context) (*CourseTree, error)
+}
+
+// FileSource — источник-каталог, который умеет разобрать один изменённый файл урока.
+type FileSource interface {
+	Source
+	// LoadFile разбирает файл урока и возвращает slug его модуля.
+	// Файл не на месте урока даёт ErrNotLesson.
+	LoadFile(ctx context.Context, path string) (string, *SourceLesson, error)
+}
+
+// CourseTree — нормализованное дерево контента: курсы → модули → уроки.
+type CourseTree struct {
+	Courses []SourceCourse
+	Failed  []ImportFailure // уроки, которые источник не смог разобрать
+	// Isolated — курсы дерева не могут занимать модули и уроки других курсов:
+	// так стороннему паку не переписать чужой контент совпавшим slug.
+	Isolated bool
+}
+
+// SourceCourse — курс дерева. Курс с пустым slug не записывается: его модули
+// остаются без курса (так импортируются сайт и демо-данные).
+type SourceCourse struct {
+	Course  content.Course
+	Modules []SourceModule
+}
+
+// SourceModule — модуль дерева.
+type SourceModule struct {
+	Module  content.Module
+	Lessons []SourceLesson
+}
+
+// SourceLesson — урок дерева с секциями и заданиями. Lesson.ContentHash — отпечаток
+// источника урока: по нему Writer пропускает неизменённые уроки. Пустой отпечаток
+// Writer вычисляет сам по содержимому урока.
+type SourceLesson struct {
+	Lesson   content.Lesson
+	Sections []content.Section
+	Tasks    []SourceTask
+	Requires []string // slug'и уроков, которые нужно пройти раньше
+	Origin   string   // файл или URL урока — для отчёта об ошибках
+}
+
+// SourceTask — задание урока. Пустой Key заменяется порядковым.
+type SourceTask struct {
+	Task content.Task
+	Line int // строка задания в файле источника, если она известна
+}
+
+func (t *CourseTree) fail(lesson string, err error) {
+	t.Failed = append(t.Failed, ImportFailure{Lesson: lesson, Err: err})
+}
+
+// Content возвращает slug'и всего контента дерева — то, что не должно попасть под удаление.
+func (t *CourseTree) Content() content.ContentSet {
+	var set content.ContentSet
+	for _, c := range t.Courses {
+		if c.Course.Slug != "" {
+			set.Courses = append(set.Courses, c.Course.Slug)
+		}
+		for _, m := range c.Modules {
+			set.Modules = append(set.Modules, m.Module.Slug)
+			for _, l := range m.Lessons {
+				set.Lessons = append(set.Lessons, l.Lesson.Slug)
+			}
+		}
+	}
+	return set
+}
+
+// SourceConfig — параметры, из которых фабрика собирает источник.
+// Каждый источник берёт только свои поля.
+type SourceConfig struct {
+	Dir     string // каталог контента (mdx, md)
+	BaseURL string // адрес сайта (web)
+	Limit   int    // ограничение количества уроков (web)
+	Pack    string // архив или git-репозиторий курс-пака (zip, tar, git)
+	Ref     string // ветка, тег или коммит git-пака
+}
+
+// SourceFactory создаёт источник по параметрам.
+type SourceFactory func(cfg SourceConfig) (Source, error)
+
+// ErrUnknownSource — источник с таким именем не зарегистрирован.
+var ErrUnknownSource = errors.New("unknown source")
+
+var sourceFactories = make(map[string]SourceFactory)
+
+// RegisterSource регистрирует источник под именем name. Вызывается из init();
+// повторная регистрация имени — ошибка программы.
+func RegisterSource(name string, factory SourceFactory) {
+	if _, dup := sourceFactories[name]; dup {
+		panic("ingest: source registered twice: " + name)
+	}
+	sourceFactories[name] = factory
+}
+
+// NewSource создаёт зарегистрированный источник.
+func NewSource(name string, cfg SourceConfig) (Source, error) {
+	factory, ok := sourceFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (known: %s)", ErrUnknownSource, name, strings.Join(SourceNames(), ", "))
+	}
+	src, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("source %s: %w", name, err)
+	}
+	return src, nil
+}
+
+// SourceNames возвращает имена зарегистрированных источников по алфавиту.
+func SourceNames() []string {
+	names := make([]string, 0, len(sourceFactories))
+	for name := range sourceFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// requireDir — проверка параметров для источников-каталогов.
+func requireDir(cfg SourceConfig) error {
+	if cfg.Dir == "" {
+		return errors.New("dir is required")
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package ingest
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// watchDebounce — сколько ждать тишины после последнего события: редакторы сохраняют
+// файл в несколько шагов (временный файл, переименование, запись).
+const watchDebounce = 300 * time.Millisecond
+
+// Watch следит за каталогом контента и вызывает onChange со списком изменённых
+// файлов уроков (.md/.mdx), когда запись в них затихает. Блокируется до отмены ctx.
+// На Linux используются уведомления inotify, на других системах — опрос каталога.
+func Watch(ctx context.Context, dir string, onChange func(paths []string)) error {
+	events := make(chan string, 64)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- watchFiles(ctx, dir, events)
+	}()
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errc:
+			return err
+		case path := <-events:
+			if ext := filepath.Ext(path); ext != ".md" && ext != ".mdx" {
+				continue
+			}
+			pending[path] = true
+			timer.Reset(watchDebounce)
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			pending = make(map[string]bool)
+			onChange(paths)
+		}
+	}
+}
+
+// sendEvent передаёт путь в Watch. После отмены ctx Watch больше не читает events —
+// без select горутина watchFiles навсегда зависла бы на полном канале.
+func sendEvent(ctx context.Context, events chan<- string, path string) error {
+	select {
+	case events <- path:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
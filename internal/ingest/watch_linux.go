@@ -0,0 +1,92 @@
+//go:build linux
+
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+// watchMask — события inotify, после которых файл урока готов к чтению.
+const watchMask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO | syscall.IN_CREATE
+
+// watchFiles отправляет в events пути файлов, записанных или перемещённых в dir
+// и его подкаталоги. Новые подкаталоги тоже берутся под наблюдение.
+func watchFiles(ctx context.Context, dir string, events chan<- string) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return fmt.Errorf("inotify init: %w", err)
+	}
+	// Неблокирующий дескриптор попадает в netpoller: Close прерывает ожидающий Read
+	f := os.NewFile(uintptr(fd), "inotify")
+	defer f.Close()
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+
+	dirs := make(map[int32]string)
+	addTree := func(root string, emit bool) error {
+		return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil // каталог могли удалить, пока мы его обходили
+			}
+			if !d.IsDir() {
+				if emit {
+					return sendEvent(ctx, events, path)
+				}
+				return nil
+			}
+			wd, err := syscall.InotifyAddWatch(fd, path, watchMask)
+			if err != nil {
+				return fmt.Errorf("inotify watch %s: %w", path, err)
+			}
+			dirs[int32(wd)] = path
+			return nil
+		})
+	}
+	if err := addTree(dir, false); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := f.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("inotify read: %w", err)
+		}
+
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nameBytes := buf[off+syscall.SizeofInotifyEvent : off+syscall.SizeofInotifyEvent+int(ev.Len)]
+			off += syscall.SizeofInotifyEvent + int(ev.Len)
+
+			parent, ok := dirs[ev.Wd]
+			if !ok || ev.Len == 0 {
+				continue
+			}
+			path := filepath.Join(parent, string(bytes.TrimRight(nameBytes, "\x00")))
+
+			switch {
+			case ev.Mask&syscall.IN_ISDIR != 0 && ev.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0:
+				// Новая глава: следим за ней и сообщаем об уже лежащих в ней файлах
+				if err := addTree(path, true); err != nil {
+					return err
+				}
+			case ev.Mask&(syscall.IN_CLOSE_WRITE|syscall.IN_MOVED_TO) != 0:
+				if err := sendEvent(ctx, events, path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
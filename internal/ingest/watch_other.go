@@ -0,0 +1,52 @@
+//go:build !linux
+
+package ingest
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// watchPollInterval — как часто опрашивать каталог там, где нет inotify.
+const watchPollInterval = time.Second
+
+// watchFiles отправляет в events пути файлов в dir и его подкаталогах,
+// у которых изменилось время модификации или которые появились.
+func watchFiles(ctx context.Context, dir string, events chan<- string) error {
+	seen := scanModTimes(dir)
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			current := scanModTimes(dir)
+			for path, mod := range current {
+				if prev, ok := seen[path]; !ok || !prev.Equal(mod) {
+					if err := sendEvent(ctx, events, path); err != nil {
+						return err
+					}
+				}
+			}
+			seen = current
+		}
+	}
+}
+
+// scanModTimes возвращает время модификации всех файлов каталога.
+func scanModTimes(dir string) map[string]time.Time {
+	times := make(map[string]time.Time)
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			times[path] = info.ModTime()
+		}
+		return nil
+	})
+	return times
+}
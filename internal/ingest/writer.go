@@ -0,0 +1,473 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"golearning/internal/content"
+	"golearning/internal/practice"
+)
+
+// Writer записывает в БД дерево контента любого источника.
+type Writer struct {
+	repo     *content.Repository
+	checker  *practice.Checker // Проверяет эталонные решения заданий
+	projects string            // Каталог ТЗ capstone-проектов; пусто — проекты не импортируются
+
+	verified map[*SourceLesson]error // Итог проверки решений изменённых уроков текущей записи
+}
+
+// NewWriter создаёт writer для репозитория.
+func NewWriter(repo *content.Repository) *Writer {
+	return &Writer{
+		repo:    repo,
+		checker: practice.NewChecker(practice.NewLocalRunner(), nil, nil),
+	}
+}
+
+// WithProjects включает импорт capstone-проектов из каталога dir: Write записывает их
+// в той же транзакции, что и уроки, после уроков — по ним проверяются ссылки в ТЗ.
+func (w *Writer) WithProjects(dir string) *Writer {
+	w.projects = dir
+	return w
+}
+
+// Write записывает дерево в одной транзакции. Неизменённые уроки (по отпечатку
+// источника) пропускаются. Если хотя бы один урок не разобран источником или не записан,
+// изменения откатываются и возвращается ErrImportFailed — сводка в отчёте
+// перечисляет все ошибки прогона.
+func (w *Writer) Write(ctx context.Context, tree *CourseTree) (*ImportReport, error) {
+	report := &ImportReport{}
+	for _, f := range tree.Failed {
+		report.fail(f.Lesson, f.Err)
+	}
+
+	verified := make(map[*SourceLesson]error)
+	for _, sc := range tree.Courses {
+		for _, sm := range sc.Modules {
+			module, err := w.repo.GetModuleBySlug(sm.Module.Slug)
+			if err != nil {
+				return report, err
+			}
+			var moduleID int64
+			if module != nil {
+				moduleID = module.ID
+			}
+			for i := range sm.Lessons {
+				if err := w.verifyLesson(ctx, moduleID, &sm.Lessons[i], verified); err != nil {
+					return report, err
+				}
+			}
+		}
+	}
+
+	err := w.repo.InTx(func(repo *content.Repository) error {
+		tx := *w
+		tx.repo = repo
+		tx.verified = verified
+		if err := tx.writeTree(ctx, tree, report); err != nil {
+			return err
+		}
+		if err := report.Err(); err != nil {
+			return err
+		}
+		if w.projects != "" {
+			if err := NewProjectImporter(repo, w.projects).Import(ctx); err != nil {
+				return fmt.Errorf("import projects: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	w.checkPrerequisites()
+
+	return report, nil
+}
+
+// WriteFile записывает один изменённый файл урока. Если модуля урока ещё нет
+// в БД (новая глава или курс), источник загружается и записывается целиком.
+func (w *Writer) WriteFile(ctx context.Context, src FileSource, path string) (*ImportReport, error) {
+	moduleSlug, lesson, err := src.LoadFile(ctx, path)
+	if errors.Is(err, ErrNotLesson) {
+		return nil, err
+	}
+	report := &ImportReport{}
+	if err != nil {
+		report.fail(path, err)
+		return report, report.Err()
+	}
+
+	module, err := w.repo.GetModuleBySlug(moduleSlug)
+	if err != nil {
+		return nil, err
+	}
+	if module == nil {
+		tree, err := src.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return w.Write(ctx, tree)
+	}
+
+	// Новый заголовок — новый slug. Прежний урок на том же месте модуля остаётся в БД
+	// до prune, и его открытым вкладкам нужно сообщить, куда урок переехал
+	siblings, err := w.repo.ListLessonsByModuleID(module.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range siblings {
+		if l.OrderIndex == lesson.Lesson.OrderIndex && l.Slug != lesson.Lesson.Slug {
+			if report.Renamed == nil {
+				report.Renamed = make(map[string]string)
+			}
+			report.Renamed[l.Slug] = lesson.Lesson.Slug
+		}
+	}
+
+	verified := make(map[*SourceLesson]error)
+	if err := w.verifyLesson(ctx, module.ID, lesson, verified); err != nil {
+		return nil, err
+	}
+
+	err = w.repo.InTx(func(repo *content.Repository) error {
+		tx := *w
+		tx.repo = repo
+		tx.verified = verified
+		outcome, err := tx.writeLesson(module.ID, lesson)
+		if err != nil {
+			report.fail(path, err)
+			return report.Err()
+		}
+		report.add(lesson.Lesson.Slug, outcome)
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	w.checkPrerequisites()
+
+	return report, nil
+}
+
+// writeTree записывает курсы, модули и уроки; ошибки уроков собираются в отчёт.
+func (w *Writer) writeTree(ctx context.Context, tree *CourseTree, report *ImportReport) error {
+	for _, sc := range tree.Courses {
+		var courseID int64
+		if sc.Course.Slug != "" {
+			course := sc.Course
+			if err := w.repo.CreateCourse(&course); err != nil {
+				return fmt.Errorf("create course %s: %w", course.Title, err)
+			}
+			report.Courses = append(report.Courses, course.Slug)
+			log.Printf("📚 Курс: %s (ID=%d)", course.Title, course.ID)
+			courseID = course.ID
+		}
+
+		for _, sm := range sc.Modules {
+			module := sm.Module
+			module.CourseID = courseID
+			if tree.Isolated {
+				existing, err := w.repo.GetModuleBySlug(module.Slug)
+				if err != nil {
+					return err
+				}
+				if existing != nil && existing.CourseID != courseID {
+					return fmt.Errorf("module %s already belongs to another course", module.Slug)
+				}
+			}
+			if err := w.repo.CreateModule(&module); err != nil {
+				return fmt.Errorf("create module %s: %w", module.Title, err)
+			}
+			report.Modules = append(report.Modules, module.Slug)
+			log.Printf("  📁 Модуль: %s (ID=%d)", module.Title, module.ID)
+
+			for i := range sm.Lessons {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				lesson := &sm.Lessons[i]
+				var outcome LessonOutcome
+				err := w.checkLessonOwner(tree, lesson.Lesson.Slug, courseID)
+				if err == nil {
+					outcome, err = w.writeLesson(module.ID, lesson)
+				}
+				if err != nil {
+					origin := lesson.Origin
+					if origin == "" {
+						origin = lesson.Lesson.Slug
+					}
+					log.Printf("    ❌ Ошибка импорта урока %s: %v", origin, err)
+					report.fail(origin, err)
+					continue
+				}
+				report.add(lesson.Lesson.Slug, outcome)
+			}
+		}
+	}
+	return nil
+}
+
+// checkLessonOwner не даёт изолированному дереву занять урок другого курса.
+func (w *Writer) checkLessonOwner(tree *CourseTree, slug string, courseID int64) error {
+	if !tree.Isolated {
+		return nil
+	}
+	owner, found, err := w.repo.GetLessonCourseID(slug)
+	if err != nil {
+		return err
+	}
+	if found && owner != courseID {
+		return fmt.Errorf("lesson %s already belongs to another course; set a unique slug in <Meta>", slug)
+	}
+	return nil
+}
+
+// verifyLesson проверяет эталонные решения урока, если он изменился относительно БД
+// (moduleID 0 — модуля ещё нет), и кладёт итог в verified. Вызывается до транзакции записи:
+// go run внутри неё держал бы блокировку записи SQLite, и сервер ждал бы конца проверки.
+func (w *Writer) verifyLesson(ctx context.Context, moduleID int64, sl *SourceLesson, verified map[*SourceLesson]error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if moduleID != 0 {
+		outcome, err := lessonOutcome(w.repo, sl.Lesson.Slug, lessonHash(moduleID, sl))
+		if err != nil {
+			return err
+		}
+		if outcome == LessonUnchanged {
+			return nil
+		}
+	}
+
+	// Урок с эталонным решением, которое не проходит проверки своего задания, не импортируется
+	var rejected []string
+	for _, st := range sl.Tasks {
+		if err := verifySolution(ctx, w.checker, &st.Task); err != nil {
+			if st.Line > 0 {
+				err = &MDXError{Line: st.Line, Msg: err.Error()}
+			}
+			rejected = append(rejected, err.Error())
+		}
+	}
+	verified[sl] = nil
+	if len(rejected) > 0 {
+		verified[sl] = fmt.Errorf("verify solutions: %s", strings.Join(rejected, "; "))
+	}
+	return nil
+}
+
+// lessonHash — хеш урока в модуле: отпечаток источника вместе с модулем и порядком.
+func lessonHash(moduleID int64, sl *SourceLesson) string {
+	fingerprint := sl.Lesson.ContentHash
+	if fingerprint == "" {
+		fingerprint = lessonFingerprint(sl)
+	}
+	return contentHash(strconv.FormatInt(moduleID, 10), strconv.Itoa(sl.Lesson.OrderIndex), fingerprint)
+}
+
+// writeLesson записывает урок с секциями, заданиями и пререквизитами.
+// Урок с неизменённым источником не перезаписывается.
+func (w *Writer) writeLesson(moduleID int64, sl *SourceLesson) (LessonOutcome, error) {
+	hash := lessonHash(moduleID, sl)
+	outcome, err := lessonOutcome(w.repo, sl.Lesson.Slug, hash)
+	if err != nil {
+		return 0, err
+	}
+	if outcome == LessonUnchanged {
+		return outcome, nil
+	}
+
+	// Решения проверены до транзакции; урока нет среди проверенных, только если
+	// его запись в БД поменялась, пока шла проверка
+	verifyErr, ok := w.verified[sl]
+	if !ok {
+		return 0, fmt.Errorf("lesson changed in the database during import, run the import again")
+	}
+	if verifyErr != nil {
+		return 0, verifyErr
+	}
+
+	lesson := sl.Lesson
+	lesson.ModuleID = moduleID
+	lesson.ContentHash = hash
+	if err := w.repo.CreateLesson(&lesson); err != nil {
+		return 0, fmt.Errorf("create lesson: %w", err)
+	}
+	log.Printf("    📄 Урок: %s (ID=%d, ~%d мин)", lesson.Title, lesson.ID, lesson.ReadingTimeMin)
+
+	if err := w.repo.SetLessonPrerequisites(lesson.ID, sl.Requires); err != nil {
+		return 0, err
+	}
+
+	// Секции пересоздаём целиком; задания обновляются по ключу, чтобы сохранить отправки
+	if err := w.repo.DeleteSectionsByLessonID(lesson.ID); err != nil {
+		return 0, err
+	}
+	for i, sec := range sl.Sections {
+		sec.LessonID = lesson.ID
+		sec.OrderIndex = i
+		if err := w.repo.CreateSection(&sec); err != nil {
+			return 0, err
+		}
+	}
+
+	keys := taskKeys(sl.Tasks)
+
+	// Задания, сохранённые до появления ключей, получили порядковый ключ.
+	// Если на той же позиции теперь задание с другим id, переносим ключ, а не пересоздаём задание.
+	existing, err := w.repo.GetTasksByLessonID(lesson.ID)
+	if err != nil {
+		return 0, fmt.Errorf("get existing tasks: %w", err)
+	}
+	seenKeys := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		seenKeys[key] = true
+	}
+	existingKeys := make(map[string]bool, len(existing))
+	for _, t := range existing {
+		existingKeys[t.Key] = true
+	}
+	for _, t := range existing {
+		// Переносим только настоящий порядковый ключ: задание с другим явным id на той же
+		// позиции — это новое задание, и чужие отправки и отметка о решении ему не достаются
+		if t.Key != strconv.Itoa(t.OrderIndex+1) {
+			continue
+		}
+		if seenKeys[t.Key] || t.OrderIndex >= len(keys) || existingKeys[keys[t.OrderIndex]] {
+			continue
+		}
+		if err := w.repo.RenameTaskKey(lesson.ID, t.Key, keys[t.OrderIndex]); err != nil {
+			return 0, err
+		}
+		existingKeys[keys[t.OrderIndex]] = true
+	}
+
+	for i, st := range sl.Tasks {
+		t := st.Task
+		t.LessonID = lesson.ID
+		t.Key = keys[i]
+		t.OrderIndex = i
+		if err := w.repo.CreateTask(&t); err != nil {
+			return 0, fmt.Errorf("task %s: %w", t.Key, err)
+		}
+	}
+
+	if len(sl.Tasks) > 0 {
+		log.Printf("      ✅ %d заданий создано", len(sl.Tasks))
+	}
+
+	// Удаляем задания, которых больше нет в источнике
+	removed, err := w.repo.DeleteTasksExcept(lesson.ID, keys)
+	if err != nil {
+		return 0, err
+	}
+	if removed > 0 {
+		log.Printf("      🗑 Удалено устаревших заданий: %d", removed)
+	}
+
+	// Очки урока в прогрессе — сумма решений его нынешних заданий
+	if err := w.repo.RecalculateLessonPoints(lesson.ID); err != nil {
+		return 0, err
+	}
+
+	return outcome, nil
+}
+
+// taskKeys возвращает ключи заданий урока: заданный источником или порядковый,
+// если ключа нет или он повторяется. Порядковый ключ не совпадает ни с одним
+// заданным: иначе upsert по ключу слил бы два задания с их отправками.
+func taskKeys(tasks []SourceTask) []string {
+	keys := make([]string, len(tasks))
+	used := make(map[string]bool, len(tasks))
+	for i, st := range tasks {
+		key := st.Task.Key
+		if key == "" {
+			continue
+		}
+		if used[key] {
+			log.Printf("      ⚠️ Повторяющийся id задания %q, используем порядковый ключ", key)
+			continue
+		}
+		used[key] = true
+		keys[i] = key
+	}
+	for i := range keys {
+		if keys[i] != "" {
+			continue
+		}
+		n := i + 1
+		for used[strconv.Itoa(n)] {
+			n++
+		}
+		keys[i] = strconv.Itoa(n)
+		used[keys[i]] = true
+	}
+	return keys
+}
+
+// lessonFingerprint — отпечаток урока для источников, которые не считают его сами:
+// хеш всего содержимого урока.
+func lessonFingerprint(sl *SourceLesson) string {
+	// В уроке нет каналов и функций — Marshal не возвращает ошибку
+	data, _ := json.Marshal(sl)
+	return contentHash(string(data))
+}
+
+// verifySolution прогоняет эталонное решение auto-задания через проверки самого
+// задания: обязательные паттерны, ожидаемый вывод и тесты. Решения manual-заданий
+// хранятся как образец и не запускаются.
+func verifySolution(ctx context.Context, checker *practice.Checker, task *content.Task) error {
+	if task.Solution == "" || task.Mode == "manual" {
+		return nil
+	}
+
+	result, err := checker.Grade(ctx, &content.Task{
+		Mode:             task.Mode,
+		TestsGo:          task.TestsGo,
+		ExpectedOutput:   task.ExpectedOutput,
+		RequiredPatterns: task.RequiredPatterns,
+	}, task.Solution)
+	if err != nil {
+		return fmt.Errorf("run solution of %q: %w", task.Title, err)
+	}
+	if !result.Success {
+		reason := result.Error
+		if result.Expected != "" {
+			reason += fmt.Sprintf(": got %q, want %q", strings.TrimSpace(result.Output), result.Expected)
+		} else if len(result.Hints) > 0 {
+			reason += ": " + strings.Join(result.Hints, "; ")
+		}
+		return fmt.Errorf("solution of %q rejected: %s", task.Title, truncate(strings.Join(strings.Fields(reason), " "), 300))
+	}
+	return nil
+}
+
+// checkPrerequisites предупреждает о ссылках на несуществующие уроки и циклах в requires.
+func (w *Writer) checkPrerequisites() {
+	unresolved, err := w.repo.ListUnresolvedPrerequisites()
+	if err != nil {
+		log.Printf("⚠️ Ошибка проверки пререквизитов: %v", err)
+		return
+	}
+	for _, ref := range unresolved {
+		log.Printf("⚠️ requires: урок не найден: %s", ref)
+	}
+
+	edges, err := w.repo.ListPrerequisites()
+	if err != nil {
+		log.Printf("⚠️ Ошибка проверки пререквизитов: %v", err)
+		return
+	}
+	if cycle := content.NewGraph(edges).FindCycle(); cycle != nil {
+		log.Printf("⚠️ requires: цикл зависимостей между уроками (ID): %v", cycle)
+	}
+}
@@ -0,0 +1,203 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golearning/internal/content"
+	"golearning/internal/practice"
+	"golearning/internal/progress"
+)
+
+// taskTree — дерево из одного урока с заданиями с ключами keys ("" — без id).
+func taskTree(keys ...string) *CourseTree {
+	lesson := SourceLesson{Lesson: content.Lesson{Slug: "lesson", Title: "Урок"}}
+	for _, key := range keys {
+		lesson.Tasks = append(lesson.Tasks, SourceTask{Task: content.Task{
+			Key: key, Title: "Задание " + key, PromptMD: "Условие " + key, Mode: "manual", Points: 10,
+		}})
+	}
+	return &CourseTree{Courses: []SourceCourse{{
+		Modules: []SourceModule{{Module: content.Module{Slug: "module", Title: "Модуль"}, Lessons: []SourceLesson{lesson}}},
+	}}}
+}
+
+// lessonTasks возвращает задания урока по ключам.
+func lessonTasks(t *testing.T, repo *content.Repository) map[string]content.Task {
+	t.Helper()
+	lesson, err := repo.GetLessonBySlug("lesson")
+	if err != nil || lesson == nil {
+		t.Fatalf("get lesson: %v", err)
+	}
+	tasks, err := repo.GetTasksByLessonID(lesson.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byKey := make(map[string]content.Task, len(tasks))
+	for _, task := range tasks {
+		byKey[task.Key] = task
+	}
+	return byKey
+}
+
+// TestWriteTaskKeys проверяет перенос ключей заданий при повторном импорте: порядковый
+// ключ задания, сохранённого до появления id, переходит к его id вместе с решением,
+// а задание, заменённое другим явным id, удаляется вместе со своими записями.
+func TestWriteTaskKeys(t *testing.T) {
+	quietLog(t)
+	ctx := context.Background()
+	database := newTestDB(t)
+	repo := content.NewRepository(database)
+	progressRepo := progress.NewRepository(database)
+	writer := NewWriter(repo)
+
+	write := func(keys ...string) map[string]content.Task {
+		t.Helper()
+		if _, err := writer.Write(ctx, taskTree(keys...)); err != nil {
+			t.Fatalf("write %v: %v", keys, err)
+		}
+		return lessonTasks(t, repo)
+	}
+
+	// Задания без id получают порядковые ключи; оба решены
+	legacy := write("", "")
+	for _, key := range []string{"1", "2"} {
+		if _, err := progressRepo.CompleteTask(legacy[key].ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Заданиям добавили id: порядковые ключи переносятся, задания и решения сохраняются
+	keyed := write("intro", "loops")
+	if keyed["intro"].ID != legacy["1"].ID || keyed["loops"].ID != legacy["2"].ID {
+		t.Fatalf("ordinal keys not renamed: before %v, after %v", legacy, keyed)
+	}
+
+	// loops заменили заданием maps на той же позиции: это новое задание, оно не решено
+	replaced := write("intro", "maps")
+	if _, ok := replaced["loops"]; ok {
+		t.Errorf("replaced task loops is still in the lesson")
+	}
+	if replaced["maps"].ID == keyed["loops"].ID {
+		t.Errorf("task maps took over the id of replaced task loops")
+	}
+
+	var completions, orphans int
+	if err := database.QueryRow(`SELECT COUNT(*) FROM task_completions`).Scan(&completions); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.QueryRow(`SELECT COUNT(*) FROM task_completions WHERE task_id NOT IN (SELECT id FROM tasks)`).Scan(&orphans); err != nil {
+		t.Fatal(err)
+	}
+	if completions != 1 || orphans != 0 {
+		t.Errorf("completions = %d (orphaned %d), want only the one of task intro", completions, orphans)
+	}
+
+	// Очки урока и статистика не учитывают решение удалённого задания
+	var earned int
+	if err := database.QueryRow(`SELECT points_earned FROM progress WHERE lesson_id = ?`, replaced["intro"].LessonID).Scan(&earned); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := progressRepo.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if earned != 10 || stats.EarnedPoints != 10 {
+		t.Errorf("lesson points = %d, earned points = %d, want 10", earned, stats.EarnedPoints)
+	}
+}
+
+// writingRunner — runner, который во время каждого прогона решения пишет в БД:
+// если импорт держит открытую транзакцию записи, запись не пройдёт.
+type writingRunner struct {
+	db     *sql.DB
+	errors []error
+}
+
+func (r *writingRunner) Run(ctx context.Context, code string) (*practice.RunResult, error) {
+	if _, err := r.db.ExecContext(ctx, `INSERT OR REPLACE INTO notes (lesson_id, note_md) VALUES (0, 'runner')`); err != nil {
+		r.errors = append(r.errors, err)
+	}
+	return &practice.RunResult{Success: true, Stdout: strings.TrimSpace(code)}, nil
+}
+
+func (r *writingRunner) Check(ctx context.Context, code, testsGo string) (*practice.RunResult, error) {
+	return r.Run(ctx, code)
+}
+
+// TestWriteVerifiesOutsideTx проверяет, что эталонные решения прогоняются до транзакции
+// записи (сервер может писать в БД, пока идёт go run), а урок с неверным решением
+// по-прежнему не импортируется.
+func TestWriteVerifiesOutsideTx(t *testing.T) {
+	quietLog(t)
+	ctx := context.Background()
+	database := newTestDB(t)
+	repo := content.NewRepository(database)
+	runner := &writingRunner{db: database}
+	writer := NewWriter(repo)
+	writer.checker = practice.NewChecker(runner, nil, nil)
+
+	tree := func(solution string) *CourseTree {
+		tree := taskTree("")
+		task := &tree.Courses[0].Modules[0].Lessons[0].Tasks[0].Task
+		task.Mode, task.ExpectedOutput, task.Solution = "auto", "ok", solution
+		return tree
+	}
+
+	if _, err := writer.Write(ctx, tree("ok")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	before, _, err := repo.GetLessonHash("lesson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Write(ctx, tree("wrong")); err == nil {
+		t.Error("lesson with a wrong solution was imported")
+	}
+	if len(runner.errors) != 0 {
+		t.Errorf("database was locked while solutions ran: %v", runner.errors)
+	}
+	after, _, err := repo.GetLessonHash("lesson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after != before {
+		t.Error("lesson was rewritten by an import with a wrong solution")
+	}
+}
+
+// TestWriteProjectsInTx проверяет, что проекты пишутся в одной транзакции с уроками:
+// ошибка в ТЗ проекта откатывает и уроки, а исправленный каталог импортируется целиком.
+func TestWriteProjectsInTx(t *testing.T) {
+	quietLog(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeFiles(t, dir, roundTripFixture)
+	projects := filepath.Join(dir, "lessons_mdx", ProjectsDir)
+	writeFiles(t, projects, map[string]string{"01_api.md": "---\ntitle: Без id\n---\nТЗ\n"})
+
+	repo := newTestRepo(t)
+	tree, err := NewMDXSource(filepath.Join(dir, "lessons_mdx")).Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewWriter(repo).WithProjects(projects).Write(ctx, tree); !errors.Is(err, ErrImportFailed) {
+		t.Fatalf("write with a broken project: err = %v, want ErrImportFailed", err)
+	}
+	if lesson, err := repo.GetLessonBySlug("hello-world"); err != nil || lesson != nil {
+		t.Fatalf("lessons were committed although the project failed: %v, %v", lesson, err)
+	}
+
+	writeFiles(t, projects, map[string]string{"01_api.md": "---\nid: api\ntitle: API\nlessons: [hello-world]\n---\nТЗ\n"})
+	if _, err := NewWriter(repo).WithProjects(projects).Write(ctx, tree); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	project, err := repo.GetProjectBySlug("api")
+	if err != nil || project == nil {
+		t.Fatalf("project not imported: %v", err)
+	}
+}
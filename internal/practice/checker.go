@@ -53,7 +53,7 @@ func (c *Checker) Check(ctx context.Context, taskID int64, code string) (*CheckR
 	if strings.TrimSpace(task.Mode) == "manual" {
 		return &CheckResult{
 			Success: false,
-			Error:   "Это ручное задание. Выполните его в IDE и нажмите «Отметить выполненным».",
+			Error:   "Это ручное задание. Выполните его в IDE и отправьте результат на проверку.",
 		}, nil
 	}
 
@@ -67,9 +67,60 @@ func (c *Checker) Check(ctx context.Context, taskID int64, code string) (*CheckR
 		return nil, fmt.Errorf("create submission: %w", err)
 	}
 
+	v, err := c.evaluate(ctx, task, code)
+	if err != nil {
+		submission.Status = "error"
+		submission.Stderr = err.Error()
+		c.progressRepo.UpdateSubmission(submission)
+		return nil, err
+	}
+
+	submission.Status = v.status
+	submission.Stdout = v.stdout
+	submission.Stderr = v.stderr
+
+	// Начисляем очки только при первом успешном решении
+	if v.result.Success {
+		if awarded, err := c.progressRepo.CompleteTask(taskID); err == nil {
+			v.result.PointsAwarded = awarded
+		}
+	}
+
+	c.progressRepo.UpdateSubmission(submission)
+	return v.result, nil
+}
+
+// Grade проверяет решение так же, как Check, но ничего не записывает:
+// ни отправку, ни отметку о решении, ни очки.
+func (c *Checker) Grade(ctx context.Context, task *content.Task, code string) (*CheckResult, error) {
+	if strings.TrimSpace(task.Mode) == "manual" {
+		return &CheckResult{
+			Success: false,
+			Error:   "Ручные задания не проверяются автоматически",
+		}, nil
+	}
+
+	v, err := c.evaluate(ctx, task, code)
+	if err != nil {
+		return nil, err
+	}
+	return v.result, nil
+}
+
+// verdict — итог прогона решения: результат для пользователя и поля для отправки.
+type verdict struct {
+	result *CheckResult
+	status string
+	stdout string
+	stderr string
+}
+
+// evaluate прогоняет решение через все шаги проверки, не трогая базу.
+func (c *Checker) evaluate(ctx context.Context, task *content.Task, code string) (*verdict, error) {
 	checkResult := &CheckResult{
 		Hints: []string{},
 	}
+	v := &verdict{result: checkResult, status: "error"}
 
 	// Шаг 1: Проверяем обязательные паттерны в коде
 	if task.RequiredPatterns != "" {
@@ -82,36 +133,29 @@ func (c *Checker) Check(ctx context.Context, taskID int64, code string) (*CheckR
 			}
 		}
 		if len(missingPatterns) > 0 {
-			submission.Status = "error"
 			checkResult.Success = false
 			checkResult.Error = "В коде отсутствуют необходимые конструкции"
 			checkResult.Hints = append(checkResult.Hints, fmt.Sprintf("Используйте: %s", strings.Join(missingPatterns, ", ")))
-			c.progressRepo.UpdateSubmission(submission)
-			return checkResult, nil
+			return v, nil
 		}
 	}
 
 	// Шаг 2: Запускаем код
 	runResult, err := c.runner.Run(ctx, code)
 	if err != nil {
-		submission.Status = "error"
-		submission.Stderr = err.Error()
-		c.progressRepo.UpdateSubmission(submission)
 		return nil, fmt.Errorf("run code: %w", err)
 	}
 
 	// Если код не компилируется
 	if !runResult.Success {
-		submission.Status = "error"
-		submission.Stderr = runResult.Error
+		v.stderr = runResult.Error
 		checkResult.Success = false
 		checkResult.Output = runResult.Stdout
 		checkResult.Error = runResult.Error
-		c.progressRepo.UpdateSubmission(submission)
-		return checkResult, nil
+		return v, nil
 	}
 
-	submission.Stdout = runResult.Stdout
+	v.stdout = runResult.Stdout
 	checkResult.Output = runResult.Stdout
 
 	// Шаг 3: Проверяем ожидаемый вывод
@@ -121,12 +165,10 @@ func (c *Checker) Check(ctx context.Context, taskID int64, code string) (*CheckR
 		checkResult.Expected = expectedOutput
 
 		if !c.compareOutput(actualOutput, expectedOutput) {
-			submission.Status = "error"
 			checkResult.Success = false
 			checkResult.Error = "Вывод программы не соответствует ожидаемому"
 			checkResult.Hints = append(checkResult.Hints, fmt.Sprintf("Ожидалось:\n%s", expectedOutput))
-			c.progressRepo.UpdateSubmission(submission)
-			return checkResult, nil
+			return v, nil
 		}
 	}
 
@@ -134,42 +176,24 @@ func (c *Checker) Check(ctx context.Context, taskID int64, code string) (*CheckR
 	if task.TestsGo != "" {
 		testResult, err := c.runner.Check(ctx, code, task.TestsGo)
 		if err != nil {
-			submission.Status = "error"
-			submission.Stderr = err.Error()
-			c.progressRepo.UpdateSubmission(submission)
 			return nil, fmt.Errorf("run tests: %w", err)
 		}
 
 		if !testResult.Success {
-			submission.Status = "error"
-			submission.Stderr = testResult.Error
+			v.stderr = testResult.Error
 			checkResult.Success = false
 			checkResult.Error = "Тесты не пройдены"
 			if testResult.Error != "" {
 				checkResult.Hints = append(checkResult.Hints, testResult.Error)
 			}
-			c.progressRepo.UpdateSubmission(submission)
-			return checkResult, nil
+			return v, nil
 		}
 	}
 
 	// Все проверки пройдены!
 	checkResult.Success = true
-	submission.Status = "success"
-
-	// Проверяем, было ли задание уже решено ранее
-	alreadySolved, _ := c.progressRepo.IsTaskSolvedSuccessfully(taskID)
-
-	if !alreadySolved {
-		// Начисляем очки только при первом успешном решении
-		checkResult.PointsAwarded = task.Points
-		if err := c.progressRepo.SetPracticeDone(task.LessonID, task.Points); err != nil {
-			// Не критично, продолжаем
-		}
-	}
-
-	c.progressRepo.UpdateSubmission(submission)
-	return checkResult, nil
+	v.status = "success"
+	return v, nil
 }
 
 // compareOutput сравнивает фактический и ожидаемый вывод.
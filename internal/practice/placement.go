@@ -0,0 +1,168 @@
+package practice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golearning/internal/content"
+)
+
+// PlacementTasksPerModule — сколько заданий вступительного теста приходится на модуль.
+const PlacementTasksPerModule = 2
+
+// PlacementModule — часть вступительного теста, относящаяся к одному модулю.
+type PlacementModule struct {
+	Module  content.Module
+	Lessons []content.Lesson
+	Tasks   []content.Task
+}
+
+// PlacementResult — итог проверки модуля во вступительном тесте.
+type PlacementResult struct {
+	Passed        bool
+	Results       map[int64]*CheckResult // по ID задания
+	LessonsMarked int
+}
+
+// PlacementExam собирает вступительный тест по курсу: до PlacementTasksPerModule
+// автоматически проверяемых заданий на модуль. Модули без подходящих заданий пропускаются.
+func (c *Checker) PlacementExam(courseID int64) ([]PlacementModule, error) {
+	modules, err := c.contentRepo.ListModulesByCourseID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("list modules: %w", err)
+	}
+
+	var exam []PlacementModule
+	for _, m := range modules {
+		pm, err := c.placementModule(m)
+		if err != nil {
+			return nil, err
+		}
+		if len(pm.Tasks) > 0 {
+			exam = append(exam, *pm)
+		}
+	}
+	return exam, nil
+}
+
+// GradePlacementModule проверяет ответы на задания модуля. Если решены все задания,
+// уроки модуля отмечаются пройденными по тесту (без начисления очков).
+// Возвращает nil, если модуль не найден или в нём нет заданий для теста.
+func (c *Checker) GradePlacementModule(ctx context.Context, moduleID int64, answers map[int64]string) (*PlacementResult, error) {
+	modules, err := c.contentRepo.ListModules()
+	if err != nil {
+		return nil, fmt.Errorf("list modules: %w", err)
+	}
+	var module *content.Module
+	for i := range modules {
+		if modules[i].ID == moduleID {
+			module = &modules[i]
+			break
+		}
+	}
+	if module == nil {
+		return nil, nil
+	}
+
+	pm, err := c.placementModule(*module)
+	if err != nil {
+		return nil, err
+	}
+	if len(pm.Tasks) == 0 {
+		return nil, nil
+	}
+
+	result := &PlacementResult{
+		Passed:  true,
+		Results: make(map[int64]*CheckResult, len(pm.Tasks)),
+	}
+	for i := range pm.Tasks {
+		task := &pm.Tasks[i]
+		code := answers[task.ID]
+		if strings.TrimSpace(code) == "" {
+			result.Passed = false
+			result.Results[task.ID] = &CheckResult{Error: "Решение не отправлено", Hints: []string{}}
+			continue
+		}
+		res, err := c.Grade(ctx, task, code)
+		if err != nil {
+			return nil, fmt.Errorf("grade task %d: %w", task.ID, err)
+		}
+		result.Results[task.ID] = res
+		if !res.Success {
+			result.Passed = false
+		}
+	}
+
+	if !result.Passed {
+		return result, nil
+	}
+
+	ids := make([]int64, len(pm.Lessons))
+	for i, l := range pm.Lessons {
+		ids[i] = l.ID
+	}
+	result.LessonsMarked, err = c.progressRepo.MarkLessonsPlaced(ids)
+	if err != nil {
+		return nil, fmt.Errorf("mark lessons placed: %w", err)
+	}
+	return result, nil
+}
+
+// placementModule подбирает задания теста для модуля. Берутся только задания
+// с проверкой вывода или тестами, не больше одного на урок; предпочтение —
+// самым «дорогим» и более поздним урокам. Выбор детерминирован, поэтому
+// при проверке модуль собирается заново и совпадает с показанным.
+func (c *Checker) placementModule(m content.Module) (*PlacementModule, error) {
+	lessons, err := c.contentRepo.ListLessonsByModuleID(m.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list lessons: %w", err)
+	}
+
+	type candidate struct {
+		task     content.Task
+		position int
+	}
+	var candidates []candidate
+	for i, l := range lessons {
+		tasks, err := c.contentRepo.GetTasksByLessonID(l.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get tasks: %w", err)
+		}
+		var best *content.Task
+		for j := range tasks {
+			t := &tasks[j]
+			if strings.TrimSpace(t.Mode) == "manual" || (t.ExpectedOutput == "" && t.TestsGo == "") {
+				continue
+			}
+			if best == nil || t.Points > best.Points {
+				best = t
+			}
+		}
+		if best != nil {
+			candidates = append(candidates, candidate{task: *best, position: i})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].task.Points != candidates[j].task.Points {
+			return candidates[i].task.Points > candidates[j].task.Points
+		}
+		return candidates[i].position > candidates[j].position
+	})
+	if len(candidates) > PlacementTasksPerModule {
+		candidates = candidates[:PlacementTasksPerModule]
+	}
+	// Показываем задания в порядке уроков
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].position < candidates[j].position
+	})
+
+	pm := &PlacementModule{Module: m, Lessons: lessons}
+	for _, cand := range candidates {
+		pm.Tasks = append(pm.Tasks, cand.task)
+	}
+	return pm, nil
+}
@@ -0,0 +1,298 @@
+package practice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golearning/internal/progress"
+)
+
+// ProjectStepTimeout — таймаут одного шага проверки проекта (go build/vet/test).
+const ProjectStepTimeout = 3 * time.Minute
+
+// maxStepOutput — сколько последних байт вывода команды попадает в отчёт.
+const maxStepOutput = 4096
+
+// VerifyStep — шаг скрипта проверки проекта.
+//
+// Скрипт задаётся в теге <Verify> manual-задания, по шагу на строку:
+//
+//	build                      — go build ./...
+//	vet                        — go vet ./...
+//	test [пакеты]              — go test -count=1 ./... (или указанные пакеты)
+//	file <путь>                — файл существует
+//	dir <путь>                 — каталог существует
+//	contains <маска> <текст>   — какой-то файл с именем по маске содержит текст
+//	route <путь>               — путь эндпоинта встречается в .go-файлах
+//
+// Пустые строки и строки с # пропускаются. Пути — относительно корня проекта.
+type VerifyStep struct {
+	Line int
+	Op   string
+	Args []string
+}
+
+// String возвращает шаг в виде строки скрипта.
+func (s VerifyStep) String() string {
+	return strings.TrimSpace(s.Op + " " + strings.Join(s.Args, " "))
+}
+
+// StepResult — результат одного шага проверки.
+type StepResult struct {
+	Step   string
+	Passed bool
+	Output string
+}
+
+// ProjectResult — итог проверки локального проекта.
+type ProjectResult struct {
+	Success bool
+	Dir     string
+	Steps   []StepResult
+	Review  *progress.TaskReview // Сдача на проверку, к которой приложен отчёт успешной проверки
+}
+
+// Report возвращает текстовый отчёт о проверке.
+func (r *ProjectResult) Report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Проект: %s\n", r.Dir)
+	for _, s := range r.Steps {
+		mark := "✅"
+		if !s.Passed {
+			mark = "❌"
+		}
+		fmt.Fprintf(&b, "%s %s\n", mark, s.Step)
+		if !s.Passed && s.Output != "" {
+			for _, line := range strings.Split(strings.TrimRight(s.Output, "\n"), "\n") {
+				fmt.Fprintf(&b, "    %s\n", line)
+			}
+		}
+	}
+	return b.String()
+}
+
+// ParseVerifyScript разбирает скрипт проверки проекта.
+func ParseVerifyScript(script string) ([]VerifyStep, error) {
+	var steps []VerifyStep
+	for i, raw := range strings.Split(script, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		step := VerifyStep{Line: i + 1, Op: fields[0], Args: fields[1:]}
+
+		switch step.Op {
+		case "build", "vet":
+			if len(step.Args) != 0 {
+				return nil, fmt.Errorf("line %d: %s takes no arguments", step.Line, step.Op)
+			}
+		case "test":
+		case "file", "dir", "route":
+			if len(step.Args) != 1 {
+				return nil, fmt.Errorf("line %d: %s needs exactly one argument", step.Line, step.Op)
+			}
+		case "contains":
+			if len(step.Args) < 2 {
+				return nil, fmt.Errorf("line %d: contains needs a file mask and a text", step.Line)
+			}
+			// Текст — всё после маски, вместе с пробелами
+			rest := strings.TrimSpace(strings.TrimPrefix(line, step.Op))
+			text := strings.TrimSpace(strings.TrimPrefix(rest, step.Args[0]))
+			step.Args = []string{step.Args[0], text}
+		default:
+			return nil, fmt.Errorf("line %d: unknown step %q", step.Line, step.Op)
+		}
+
+		if step.Op == "file" || step.Op == "dir" {
+			if !filepath.IsLocal(step.Args[0]) {
+				return nil, fmt.Errorf("line %d: path must be relative to the project", step.Line)
+			}
+		}
+		steps = append(steps, step)
+	}
+
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("verify script is empty")
+	}
+	return steps, nil
+}
+
+// VerifyProject выполняет шаги проверки в каталоге проекта. Выполняются все шаги,
+// даже если какой-то не прошёл, — так отчёт показывает полную картину.
+func VerifyProject(ctx context.Context, dir string, steps []VerifyStep) (*ProjectResult, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve dir: %w", err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("stat dir: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", abs)
+	}
+
+	result := &ProjectResult{Success: true, Dir: abs}
+	for _, step := range steps {
+		sr := runVerifyStep(ctx, abs, step)
+		if !sr.Passed {
+			result.Success = false
+		}
+		result.Steps = append(result.Steps, sr)
+	}
+	return result, nil
+}
+
+func runVerifyStep(ctx context.Context, dir string, step VerifyStep) StepResult {
+	sr := StepResult{Step: step.String()}
+
+	switch step.Op {
+	case "build":
+		sr.Passed, sr.Output = runGo(ctx, dir, "build", "./...")
+	case "vet":
+		sr.Passed, sr.Output = runGo(ctx, dir, "vet", "./...")
+	case "test":
+		pkgs := step.Args
+		if len(pkgs) == 0 {
+			pkgs = []string{"./..."}
+		}
+		sr.Passed, sr.Output = runGo(ctx, dir, append([]string{"test", "-count=1"}, pkgs...)...)
+	case "file":
+		info, err := os.Stat(filepath.Join(dir, step.Args[0]))
+		sr.Passed = err == nil && !info.IsDir()
+		if !sr.Passed {
+			sr.Output = "файл не найден"
+		}
+	case "dir":
+		info, err := os.Stat(filepath.Join(dir, step.Args[0]))
+		sr.Passed = err == nil && info.IsDir()
+		if !sr.Passed {
+			sr.Output = "каталог не найден"
+		}
+	case "contains":
+		sr.Passed = projectContains(dir, step.Args[0], step.Args[1])
+		if !sr.Passed {
+			sr.Output = fmt.Sprintf("ни один файл %s не содержит %q", step.Args[0], step.Args[1])
+		}
+	case "route":
+		sr.Passed = projectContains(dir, "*.go", step.Args[0])
+		if !sr.Passed {
+			sr.Output = fmt.Sprintf("эндпоинт %s не найден в .go-файлах", step.Args[0])
+		}
+	}
+	return sr
+}
+
+// runGo запускает команду go в каталоге проекта и возвращает успех и хвост вывода.
+func runGo(ctx context.Context, dir string, args ...string) (bool, string) {
+	ctx, cancel := context.WithTimeout(ctx, ProjectStepTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	output := out.String()
+	if len(output) > maxStepOutput {
+		output = "…" + output[len(output)-maxStepOutput:]
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return false, fmt.Sprintf("превышено время выполнения (%s)\n%s", ProjectStepTimeout, output)
+	}
+	return err == nil, output
+}
+
+// projectContains ищет текст в файлах проекта, имя которых подходит под маску.
+// Каталоги .git, vendor и node_modules пропускаются.
+func projectContains(dir, mask, text string) bool {
+	found := false
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || found {
+			return nil
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "vendor", "node_modules":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ok, _ := filepath.Match(mask, d.Name()); !ok {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err == nil && bytes.Contains(data, []byte(text)) {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+// CheckProject проверяет локальный проект manual-задания по его скрипту <Verify>
+// и записывает результат как отправку. Успешный отчёт прикладывается к сдаче на проверку:
+// очки за manual-задание начисляются только при её одобрении.
+func (c *Checker) CheckProject(ctx context.Context, taskID int64, dir string) (*ProjectResult, error) {
+	task, err := c.contentRepo.GetTaskByID(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("get task: %w", err)
+	}
+	if task == nil {
+		return nil, fmt.Errorf("task %d not found", taskID)
+	}
+	if strings.TrimSpace(task.VerifyScript) == "" {
+		return nil, fmt.Errorf("task %d has no verify script", taskID)
+	}
+
+	steps, err := ParseVerifyScript(task.VerifyScript)
+	if err != nil {
+		return nil, fmt.Errorf("parse verify script: %w", err)
+	}
+
+	result, err := VerifyProject(ctx, dir, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	submission := &progress.Submission{
+		TaskID: taskID,
+		Code:   "[project] " + result.Dir,
+		Status: "error",
+	}
+	if result.Success {
+		submission.Status = "success"
+		submission.Stdout = result.Report()
+	} else {
+		submission.Stderr = result.Report()
+	}
+	if err := c.progressRepo.CreateSubmission(submission); err != nil {
+		return nil, fmt.Errorf("create submission: %w", err)
+	}
+
+	if !result.Success {
+		return result, nil
+	}
+	solved, err := c.progressRepo.IsTaskSolvedSuccessfully(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !solved {
+		if result.Review, err = c.progressRepo.SubmitVerifiedProject(taskID, result.Dir, result.Report()); err != nil {
+			return nil, fmt.Errorf("submit for review: %w", err)
+		}
+	}
+	return result, nil
+}
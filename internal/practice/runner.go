@@ -15,6 +15,9 @@ const (
 	MaxCodeSize = 100 * 1024
 	// RunTimeout — таймаут выполнения (15 секунд).
 	RunTimeout = 15 * time.Second
+	// GoVersion — версия языка Go в go.mod, с которым runner запускает код.
+	// Курсы, которым нужна более новая версия (runner в course.yaml), не импортируются.
+	GoVersion = "1.22"
 )
 
 // RunResult — результат выполнения кода.
@@ -65,7 +68,7 @@ func (r *LocalRunner) Run(ctx context.Context, code string) (*RunResult, error)
 	}
 
 	// Создаём go.mod
-	goMod := "module runner\n\ngo 1.22\n"
+	goMod := "module runner\n\ngo " + GoVersion + "\n"
 	if err := os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte(goMod), 0644); err != nil {
 		return nil, fmt.Errorf("write go.mod: %w", err)
 	}
@@ -139,7 +142,7 @@ func (r *LocalRunner) Check(ctx context.Context, code string, testsGo string) (*
 	}
 
 	// Создаём go.mod
-	goMod := "module runner\n\ngo 1.22\n"
+	goMod := "module runner\n\ngo " + GoVersion + "\n"
 	if err := os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte(goMod), 0644); err != nil {
 		return nil, fmt.Errorf("write go.mod: %w", err)
 	}
@@ -0,0 +1,223 @@
+package progress
+
+import (
+	"fmt"
+	"time"
+)
+
+// ModuleCompletion — прохождение одного модуля (главы).
+type ModuleCompletion struct {
+	ModuleTitle  string
+	LessonsTotal int
+	LessonsDone  int
+	TasksTotal   int
+	TasksSolved  int
+	PointsTotal  int
+	PointsEarned int
+}
+
+// Percent возвращает долю пройденных уроков в процентах.
+func (m ModuleCompletion) Percent() int {
+	return percent(m.LessonsDone, m.LessonsTotal)
+}
+
+// CourseCompletion — прохождение курса с разбивкой по модулям.
+type CourseCompletion struct {
+	CourseTitle string
+	CourseIcon  string
+	ModuleCompletion
+	Modules []ModuleCompletion
+}
+
+// TaskDifficulty — задание и статистика отправок по нему.
+type TaskDifficulty struct {
+	LessonSlug  string
+	LessonTitle string
+	TaskTitle   string
+	Attempts    int
+	Failed      int
+	Solved      bool
+}
+
+// Activity — событие в ленте недавней активности.
+type Activity struct {
+	Kind        string // submission / lesson / note
+	Status      string // статус отправки или урока
+	LessonSlug  string
+	LessonTitle string
+	TaskTitle   string
+	At          time.Time
+}
+
+func percent(part, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return part * 100 / total
+}
+
+// GetCourseCompletion возвращает прохождение курсов и их модулей в порядке курса.
+func (r *Repository) GetCourseCompletion() ([]CourseCompletion, error) {
+	rows, err := r.db.Query(
+		`SELECT m.course_id, COALESCE(c.title, ''), COALESCE(c.icon, ''), m.title,
+		        COUNT(l.id),
+		        COALESCE(SUM(CASE WHEN p.status = 'done' THEN 1 ELSE 0 END), 0),
+		        COALESCE(SUM((SELECT COUNT(*) FROM tasks t WHERE t.lesson_id = l.id)), 0),
+		        COALESCE(SUM((SELECT COUNT(*) FROM task_completions tc
+		                       JOIN tasks t ON t.id = tc.task_id WHERE t.lesson_id = l.id)), 0),
+		        COALESCE(SUM((SELECT SUM(t.points) FROM tasks t WHERE t.lesson_id = l.id)), 0),
+		        COALESCE(SUM((SELECT SUM(tc.points) FROM task_completions tc
+		                       JOIN tasks t ON t.id = tc.task_id WHERE t.lesson_id = l.id)), 0)
+		 FROM modules m
+		 LEFT JOIN courses c ON c.id = m.course_id
+		 JOIN lessons l ON l.module_id = m.id
+		 LEFT JOIN progress p ON p.lesson_id = l.id
+		 GROUP BY m.id
+		 ORDER BY c.order_index, m.order_index`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get course completion: %w", err)
+	}
+	defer rows.Close()
+
+	var result []CourseCompletion
+	var lastCourse *int64
+	for rows.Next() {
+		var courseID *int64
+		var courseTitle, courseIcon string
+		var m ModuleCompletion
+		if err := rows.Scan(&courseID, &courseTitle, &courseIcon, &m.ModuleTitle,
+			&m.LessonsTotal, &m.LessonsDone, &m.TasksTotal, &m.TasksSolved,
+			&m.PointsTotal, &m.PointsEarned); err != nil {
+			return nil, fmt.Errorf("scan module completion: %w", err)
+		}
+
+		// Модули приходят сгруппированными по курсу
+		if len(result) == 0 || !sameCourse(lastCourse, courseID) {
+			result = append(result, CourseCompletion{CourseTitle: courseTitle, CourseIcon: courseIcon})
+			lastCourse = courseID
+		}
+		cc := &result[len(result)-1]
+		cc.Modules = append(cc.Modules, m)
+		cc.LessonsTotal += m.LessonsTotal
+		cc.LessonsDone += m.LessonsDone
+		cc.TasksTotal += m.TasksTotal
+		cc.TasksSolved += m.TasksSolved
+		cc.PointsTotal += m.PointsTotal
+		cc.PointsEarned += m.PointsEarned
+	}
+	return result, rows.Err()
+}
+
+func sameCourse(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// GetHardestTasks возвращает задания с наибольшим числом неудачных отправок.
+func (r *Repository) GetHardestTasks(limit int) ([]TaskDifficulty, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	rows, err := r.db.Query(
+		`SELECT l.slug, l.title, t.title,
+		        COUNT(*),
+		        SUM(CASE WHEN s.status IN ('error', 'timeout') THEN 1 ELSE 0 END) AS failed,
+		        EXISTS (SELECT 1 FROM task_completions tc WHERE tc.task_id = t.id)
+		 FROM submissions s
+		 JOIN tasks t ON t.id = s.task_id
+		 JOIN lessons l ON l.id = t.lesson_id
+		 WHERE s.status != 'pending'
+		 GROUP BY t.id
+		 HAVING failed > 0
+		 ORDER BY failed DESC, COUNT(*) DESC
+		 LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get hardest tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var result []TaskDifficulty
+	for rows.Next() {
+		var td TaskDifficulty
+		if err := rows.Scan(&td.LessonSlug, &td.LessonTitle, &td.TaskTitle,
+			&td.Attempts, &td.Failed, &td.Solved); err != nil {
+			return nil, fmt.Errorf("scan task difficulty: %w", err)
+		}
+		result = append(result, td)
+	}
+	return result, rows.Err()
+}
+
+// GetAverageAttemptsToSuccess возвращает среднее число отправок до первого
+// успешного решения и количество заданий, по которым оно посчитано.
+func (r *Repository) GetAverageAttemptsToSuccess() (float64, int, error) {
+	var avg float64
+	var solved int
+	err := r.db.QueryRow(
+		`SELECT COALESCE(AVG(attempts), 0), COUNT(*) FROM (
+		   SELECT COUNT(*) AS attempts
+		   FROM submissions s
+		   JOIN (SELECT task_id, MIN(id) AS first_ok FROM submissions
+		         WHERE status = 'success' GROUP BY task_id) f
+		     ON f.task_id = s.task_id AND s.id <= f.first_ok
+		   WHERE s.status != 'pending'
+		   GROUP BY s.task_id
+		 )`,
+	).Scan(&avg, &solved)
+	if err != nil {
+		return 0, 0, fmt.Errorf("get attempts to success: %w", err)
+	}
+	return avg, solved, nil
+}
+
+// GetRecentActivity возвращает последние события: отправки решений,
+// смену статуса уроков и правки заметок — новые первыми.
+func (r *Repository) GetRecentActivity(limit int) ([]Activity, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	rows, err := r.db.Query(
+		`SELECT kind, status, slug, lesson_title, task_title, at FROM (
+		   SELECT 'submission' AS kind, s.status AS status, l.slug AS slug, l.title AS lesson_title,
+		          t.title AS task_title, s.created_at AS at
+		   FROM submissions s
+		   JOIN tasks t ON t.id = s.task_id
+		   JOIN lessons l ON l.id = t.lesson_id
+		   WHERE s.status != 'pending'
+		   UNION ALL
+		   SELECT 'lesson', p.status, l.slug, l.title, '', p.updated_at
+		   FROM progress p
+		   JOIN lessons l ON l.id = p.lesson_id
+		   WHERE p.status != 'new'
+		   UNION ALL
+		   SELECT 'note', '', l.slug, l.title, '', n.updated_at
+		   FROM notes n
+		   JOIN lessons l ON l.id = n.lesson_id
+		   WHERE n.note_md != ''
+		 )
+		 ORDER BY at DESC
+		 LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get recent activity: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Activity
+	for rows.Next() {
+		var a Activity
+		if err := rows.Scan(&a.Kind, &a.Status, &a.LessonSlug, &a.LessonTitle, &a.TaskTitle, &a.At); err != nil {
+			return nil, fmt.Errorf("scan activity: %w", err)
+		}
+		result = append(result, a)
+	}
+	return result, rows.Err()
+}
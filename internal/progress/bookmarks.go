@@ -0,0 +1,89 @@
+package progress
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Bookmark — закладка на урок или задание.
+type Bookmark struct {
+	ID          int64
+	LessonID    int64
+	TaskID      int64 // 0 — закладка на весь урок
+	LessonSlug  string
+	LessonTitle string
+	TaskTitle   string
+	CreatedAt   time.Time
+}
+
+// ToggleBookmark ставит закладку или снимает уже поставленную.
+// Возвращает true, если закладка теперь есть.
+func (r *Repository) ToggleBookmark(lessonID, taskID int64) (bool, error) {
+	res, err := r.db.Exec(
+		`DELETE FROM bookmarks WHERE lesson_id = ? AND COALESCE(task_id, 0) = ?`,
+		lessonID, taskID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("delete bookmark: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return false, nil
+	}
+
+	_, err = r.db.Exec(
+		`INSERT INTO bookmarks (lesson_id, task_id) VALUES (?, ?)`,
+		lessonID, sql.NullInt64{Int64: taskID, Valid: taskID > 0},
+	)
+	if err != nil {
+		return false, fmt.Errorf("create bookmark: %w", err)
+	}
+	return true, nil
+}
+
+// GetLessonBookmarks возвращает закладки урока: ключ 0 — сам урок, иначе ID задания.
+func (r *Repository) GetLessonBookmarks(lessonID int64) (map[int64]bool, error) {
+	rows, err := r.db.Query(
+		`SELECT COALESCE(task_id, 0) FROM bookmarks WHERE lesson_id = ?`,
+		lessonID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get lesson bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int64]bool)
+	for rows.Next() {
+		var taskID int64
+		if err := rows.Scan(&taskID); err != nil {
+			return nil, fmt.Errorf("scan bookmark: %w", err)
+		}
+		result[taskID] = true
+	}
+	return result, rows.Err()
+}
+
+// ListBookmarks возвращает все закладки, новые первыми.
+func (r *Repository) ListBookmarks() ([]Bookmark, error) {
+	rows, err := r.db.Query(
+		`SELECT b.id, b.lesson_id, COALESCE(b.task_id, 0), l.slug, l.title, COALESCE(t.title, ''), b.created_at
+		 FROM bookmarks b
+		 JOIN lessons l ON l.id = b.lesson_id
+		 LEFT JOIN tasks t ON t.id = b.task_id
+		 ORDER BY b.created_at DESC, b.id DESC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	var bookmarks []Bookmark
+	for rows.Next() {
+		var b Bookmark
+		if err := rows.Scan(&b.ID, &b.LessonID, &b.TaskID, &b.LessonSlug, &b.LessonTitle, &b.TaskTitle, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan bookmark: %w", err)
+		}
+		bookmarks = append(bookmarks, b)
+	}
+	return bookmarks, rows.Err()
+}
@@ -0,0 +1,186 @@
+package progress
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// TaskStats — сколько заданий урока решено из общего числа.
+type TaskStats struct {
+	Solved int
+	Total  int
+}
+
+// RecalcReport — итог пересчёта очков.
+type RecalcReport struct {
+	CompletionsRestored int
+	LessonsUpdated      int
+	PointsBefore        int
+	PointsAfter         int
+}
+
+// lessonPointsSQL пересчитывает очки и флаг практики урока по решённым заданиям.
+const lessonPointsSQL = `
+	INSERT INTO progress (lesson_id, practice_done, points_earned, updated_at)
+	SELECT t.lesson_id, 1, SUM(c.points), CURRENT_TIMESTAMP
+	FROM task_completions c
+	JOIN tasks t ON t.id = c.task_id
+	WHERE t.lesson_id = ?
+	GROUP BY t.lesson_id
+	ON CONFLICT(lesson_id) DO UPDATE SET
+	  practice_done = excluded.practice_done,
+	  points_earned = excluded.points_earned,
+	  updated_at = CURRENT_TIMESTAMP`
+
+// CompleteTask фиксирует первое решение задания и возвращает начисленные очки
+// (меньше, если эталонное решение было открыто заранее). Повторное решение ничего
+// не начисляет; очки урока пересчитываются из task_completions.
+func (r *Repository) CompleteTask(taskID int64) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin complete task: %w", err)
+	}
+	defer tx.Rollback()
+
+	points, err := completeTaskTx(tx, taskID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit complete task: %w", err)
+	}
+	return points, nil
+}
+
+// completeTaskTx — CompleteTask внутри уже открытой транзакции.
+func completeTaskTx(tx *sql.Tx, taskID int64) (int, error) {
+	var lessonID int64
+	var points int
+	err := tx.QueryRow(`SELECT t.lesson_id, `+taskPointsSQL+` FROM tasks t WHERE t.id = ?`, taskID).Scan(&lessonID, &points)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("task %d not found", taskID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get task: %w", err)
+	}
+
+	res, err := tx.Exec(
+		`INSERT OR IGNORE INTO task_completions (task_id, points) VALUES (?, ?)`,
+		taskID, points,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert completion: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.Exec(lessonPointsSQL, lessonID); err != nil {
+		return 0, fmt.Errorf("update lesson points: %w", err)
+	}
+	return points, nil
+}
+
+// GetLessonTaskStats возвращает число решённых и всех заданий урока.
+func (r *Repository) GetLessonTaskStats(lessonID int64) (*TaskStats, error) {
+	ts := &TaskStats{}
+	err := r.db.QueryRow(
+		`SELECT COUNT(c.task_id), COUNT(t.id)
+		 FROM tasks t
+		 LEFT JOIN task_completions c ON c.task_id = t.id
+		 WHERE t.lesson_id = ?`,
+		lessonID,
+	).Scan(&ts.Solved, &ts.Total)
+	if err != nil {
+		return nil, fmt.Errorf("get lesson task stats: %w", err)
+	}
+	return ts, nil
+}
+
+// RecalculatePoints восстанавливает отметки о решении по успешным отправкам
+// и заново выводит очки всех уроков из task_completions.
+func (r *Repository) RecalculatePoints() (*RecalcReport, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin recalc: %w", err)
+	}
+	defer tx.Rollback()
+
+	report := &RecalcReport{}
+
+	if err := tx.QueryRow(`SELECT COALESCE(SUM(points_earned), 0) FROM progress`).Scan(&report.PointsBefore); err != nil {
+		return nil, fmt.Errorf("sum points before: %w", err)
+	}
+
+	// Успешные отправки без отметки о решении (например, после импорта прогресса)
+	res, err := tx.Exec(
+		`INSERT OR IGNORE INTO task_completions (task_id, points, solved_at)
+		 SELECT s.task_id, ` + taskPointsSQL + `, MIN(s.created_at)
+		 FROM submissions s
+		 JOIN tasks t ON t.id = s.task_id
+		 WHERE s.status = 'success'
+		 GROUP BY s.task_id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("restore completions: %w", err)
+	}
+	restored, _ := res.RowsAffected()
+	report.CompletionsRestored = int(restored)
+
+	// Уроки без решённых заданий
+	res, err = tx.Exec(
+		`UPDATE progress SET practice_done = 0, points_earned = 0, updated_at = CURRENT_TIMESTAMP
+		 WHERE (practice_done != 0 OR points_earned != 0)
+		   AND lesson_id NOT IN (
+		     SELECT t.lesson_id FROM task_completions c JOIN tasks t ON t.id = c.task_id
+		   )`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("reset lesson points: %w", err)
+	}
+	cleared, _ := res.RowsAffected()
+	report.LessonsUpdated += int(cleared)
+
+	// Уроки с решёнными заданиями, у которых сумма разошлась
+	rows, err := tx.Query(
+		`SELECT t.lesson_id
+		 FROM task_completions c
+		 JOIN tasks t ON t.id = c.task_id
+		 LEFT JOIN progress p ON p.lesson_id = t.lesson_id
+		 GROUP BY t.lesson_id
+		 HAVING COALESCE(MAX(p.points_earned), -1) != SUM(c.points) OR COALESCE(MAX(p.practice_done), 0) = 0`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("find drifted lessons: %w", err)
+	}
+	var drifted []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan lesson id: %w", err)
+		}
+		drifted = append(drifted, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("find drifted lessons: %w", err)
+	}
+
+	for _, id := range drifted {
+		if _, err := tx.Exec(lessonPointsSQL, id); err != nil {
+			return nil, fmt.Errorf("update lesson points: %w", err)
+		}
+	}
+	report.LessonsUpdated += len(drifted)
+
+	if err := tx.QueryRow(`SELECT COALESCE(SUM(points_earned), 0) FROM progress`).Scan(&report.PointsAfter); err != nil {
+		return nil, fmt.Errorf("sum points after: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit recalc: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,430 @@
+package progress
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ExportVersion — текущая версия формата файла экспорта прогресса.
+const ExportVersion = 1
+
+// ErrUnsupportedExport — файл экспорта имеет неизвестную версию формата.
+var ErrUnsupportedExport = errors.New("unsupported export version")
+
+// Export — переносимый снимок прогресса, заметок и отправок.
+// Уроки и задания адресуются по стабильным slug/ключам, а не по автоинкрементным ID,
+// поэтому файл можно импортировать в другую базу или после повторного импорта контента.
+type Export struct {
+	Version     int                `json:"version"`
+	ExportedAt  time.Time          `json:"exported_at"`
+	Progress    []ExportProgress   `json:"progress"`
+	Notes       []ExportNote       `json:"notes"`
+	Submissions []ExportSubmission `json:"submissions"`
+	Reveals     []ExportReveal     `json:"solution_reveals"`
+}
+
+// ExportProgress — прогресс по уроку в файле экспорта.
+type ExportProgress struct {
+	LessonSlug   string    `json:"lesson"`
+	Status       Status    `json:"status"`
+	PracticeDone bool      `json:"practice_done"`
+	PointsEarned int       `json:"points_earned"`
+	Placement    bool      `json:"placement,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ExportNote — заметка к уроку в файле экспорта.
+type ExportNote struct {
+	LessonSlug string    `json:"lesson"`
+	NoteMD     string    `json:"note_md"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ExportSubmission — отправка решения в файле экспорта.
+type ExportSubmission struct {
+	LessonSlug string    `json:"lesson"`
+	TaskKey    string    `json:"task"`
+	Code       string    `json:"code"`
+	Status     string    `json:"status"`
+	Stdout     string    `json:"stdout,omitempty"`
+	Stderr     string    `json:"stderr,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ExportReveal — эталонное решение, открытое до решения задания: очки за задание уменьшены.
+type ExportReveal struct {
+	LessonSlug string    `json:"lesson"`
+	TaskKey    string    `json:"task"`
+	RevealedAt time.Time `json:"revealed_at"`
+}
+
+// ImportReport — итог слияния файла экспорта с текущей базой.
+type ImportReport struct {
+	ProgressMerged     int      `json:"progress_merged"`
+	NotesImported      int      `json:"notes_imported"`
+	NotesKept          int      `json:"notes_kept"`
+	SubmissionsAdded   int      `json:"submissions_added"`
+	SubmissionsSkipped int      `json:"submissions_skipped"`
+	RevealsImported    int      `json:"reveals_imported"`
+	UnknownLessons     []string `json:"unknown_lessons,omitempty"`
+	UnknownTasks       []string `json:"unknown_tasks,omitempty"`
+}
+
+// statusRank задаёт порядок статусов: при слиянии побеждает более «продвинутый».
+var statusRank = map[Status]int{
+	StatusNew:     0,
+	StatusReading: 1,
+	StatusDone:    2,
+}
+
+// taskKeySQL — выражение для стабильного ключа задания внутри урока.
+const taskKeySQL = `t.task_key`
+
+// Export собирает весь прогресс, заметки и отправки в переносимый снимок.
+func (r *Repository) Export() (*Export, error) {
+	e := &Export{
+		Version:     ExportVersion,
+		ExportedAt:  time.Now().UTC(),
+		Progress:    []ExportProgress{},
+		Notes:       []ExportNote{},
+		Submissions: []ExportSubmission{},
+		Reveals:     []ExportReveal{},
+	}
+
+	rows, err := r.db.Query(
+		`SELECT l.slug, p.status, p.practice_done, p.points_earned, p.placement, p.updated_at
+		 FROM progress p
+		 JOIN lessons l ON l.id = p.lesson_id
+		 ORDER BY l.slug`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("export progress: %w", err)
+	}
+	for rows.Next() {
+		var p ExportProgress
+		if err := rows.Scan(&p.LessonSlug, &p.Status, &p.PracticeDone, &p.PointsEarned, &p.Placement, &p.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan progress: %w", err)
+		}
+		e.Progress = append(e.Progress, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("export progress: %w", err)
+	}
+
+	rows, err = r.db.Query(
+		`SELECT l.slug, n.note_md, n.updated_at
+		 FROM notes n
+		 JOIN lessons l ON l.id = n.lesson_id
+		 WHERE n.note_md != ''
+		 ORDER BY l.slug`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("export notes: %w", err)
+	}
+	for rows.Next() {
+		var n ExportNote
+		if err := rows.Scan(&n.LessonSlug, &n.NoteMD, &n.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan note: %w", err)
+		}
+		e.Notes = append(e.Notes, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("export notes: %w", err)
+	}
+
+	rows, err = r.db.Query(
+		`SELECT l.slug, ` + taskKeySQL + `, s.code, s.status,
+		        COALESCE(s.stdout, ''), COALESCE(s.stderr, ''), s.created_at
+		 FROM submissions s
+		 JOIN tasks t ON t.id = s.task_id
+		 JOIN lessons l ON l.id = t.lesson_id
+		 WHERE s.status != 'pending'
+		 ORDER BY s.created_at, s.id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("export submissions: %w", err)
+	}
+	for rows.Next() {
+		var s ExportSubmission
+		if err := rows.Scan(&s.LessonSlug, &s.TaskKey, &s.Code, &s.Status, &s.Stdout, &s.Stderr, &s.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan submission: %w", err)
+		}
+		e.Submissions = append(e.Submissions, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("export submissions: %w", err)
+	}
+
+	rows, err = r.db.Query(
+		`SELECT l.slug, ` + taskKeySQL + `, sr.revealed_at
+		 FROM solution_reveals sr
+		 JOIN tasks t ON t.id = sr.task_id
+		 JOIN lessons l ON l.id = t.lesson_id
+		 ORDER BY sr.revealed_at, sr.task_id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("export solution reveals: %w", err)
+	}
+	for rows.Next() {
+		var rv ExportReveal
+		if err := rows.Scan(&rv.LessonSlug, &rv.TaskKey, &rv.RevealedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan solution reveal: %w", err)
+		}
+		e.Reveals = append(e.Reveals, rv)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("export solution reveals: %w", err)
+	}
+
+	return e, nil
+}
+
+// Import сливает снимок с текущей базой в одной транзакции.
+//
+// Правила разрешения конфликтов:
+//   - статус урока: побеждает более продвинутый (done > reading > new);
+//   - заметка: побеждает более свежая по updated_at, пустая локальная всегда заменяется;
+//   - отправки: добавляются, если такой же (задание, время, код) ещё нет;
+//   - открытые решения переносятся до отправок, если задание ещё не решено локально;
+//   - очки не переносятся как есть: успешные отправки отмечают задания решёнными
+//     (со штрафом за открытое решение), и очки уроков заново выводятся из task_completions.
+//
+// Уроки и задания, которых нет в текущей базе, пропускаются и перечисляются в отчёте.
+func (r *Repository) Import(e *Export) (*ImportReport, error) {
+	if e.Version < 1 || e.Version > ExportVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedExport, e.Version)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin import: %w", err)
+	}
+	defer tx.Rollback()
+
+	report := &ImportReport{}
+	unknownLessons := map[string]bool{}
+	unknownTasks := map[string]bool{}
+
+	lessonID := func(slug string) (int64, bool, error) {
+		var id int64
+		err := tx.QueryRow(`SELECT id FROM lessons WHERE slug = ?`, slug).Scan(&id)
+		if err == sql.ErrNoRows {
+			if !unknownLessons[slug] {
+				unknownLessons[slug] = true
+				report.UnknownLessons = append(report.UnknownLessons, slug)
+			}
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, fmt.Errorf("lookup lesson %s: %w", slug, err)
+		}
+		return id, true, nil
+	}
+
+	lookupTask := func(lessonSlug, key string) (id, taskLessonID int64, ok bool, err error) {
+		err = tx.QueryRow(
+			`SELECT t.id, t.lesson_id FROM tasks t
+			 JOIN lessons l ON l.id = t.lesson_id
+			 WHERE l.slug = ? AND `+taskKeySQL+` = ?`,
+			lessonSlug, key,
+		).Scan(&id, &taskLessonID)
+		if err == sql.ErrNoRows {
+			ref := lessonSlug + "#" + key
+			if !unknownTasks[ref] {
+				unknownTasks[ref] = true
+				report.UnknownTasks = append(report.UnknownTasks, ref)
+			}
+			return 0, 0, false, nil
+		}
+		if err != nil {
+			return 0, 0, false, fmt.Errorf("lookup task %s#%s: %w", lessonSlug, key, err)
+		}
+		return id, taskLessonID, true, nil
+	}
+
+	for _, p := range e.Progress {
+		id, ok, err := lessonID(p.LessonSlug)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if _, known := statusRank[p.Status]; !known {
+			p.Status = StatusNew
+		}
+
+		status := StatusNew
+		placement := false
+		err = tx.QueryRow(`SELECT status, placement FROM progress WHERE lesson_id = ?`, id).Scan(&status, &placement)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("get progress %s: %w", p.LessonSlug, err)
+		}
+		// Отметка placement идёт вместе со статусом, который победил
+		if statusRank[p.Status] > statusRank[status] {
+			status, placement = p.Status, p.Placement
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO progress (lesson_id, status, placement, updated_at)
+			 VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+			 ON CONFLICT(lesson_id) DO UPDATE SET
+			   status = excluded.status,
+			   placement = excluded.placement,
+			   updated_at = CURRENT_TIMESTAMP`,
+			id, status, placement,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("merge progress %s: %w", p.LessonSlug, err)
+		}
+		report.ProgressMerged++
+	}
+
+	for _, n := range e.Notes {
+		id, ok, err := lessonID(n.LessonSlug)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		var localMD string
+		var localUpdated time.Time
+		err = tx.QueryRow(`SELECT note_md, updated_at FROM notes WHERE lesson_id = ?`, id).Scan(&localMD, &localUpdated)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("get note %s: %w", n.LessonSlug, err)
+		}
+		if localMD != "" && (localMD == n.NoteMD || !n.UpdatedAt.After(localUpdated)) {
+			report.NotesKept++
+			continue
+		}
+
+		// Локальная версия не теряется — она остаётся в истории заметки
+		if err := snapshotNote(tx, id, n.NoteMD, true); err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO notes (lesson_id, note_md, updated_at)
+			 VALUES (?, ?, ?)
+			 ON CONFLICT(lesson_id) DO UPDATE SET
+			   note_md = excluded.note_md,
+			   updated_at = excluded.updated_at`,
+			id, n.NoteMD, sqlTime(n.UpdatedAt),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("import note %s: %w", n.LessonSlug, err)
+		}
+		report.NotesImported++
+	}
+
+	// Открытые решения переносятся раньше отправок: от них зависят очки за решение
+	for _, rv := range e.Reveals {
+		if _, ok, err := lessonID(rv.LessonSlug); err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+		id, _, ok, err := lookupTask(rv.LessonSlug, rv.TaskKey)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		res, err := tx.Exec(
+			`INSERT OR IGNORE INTO solution_reveals (task_id, revealed_at)
+			 SELECT ?, ? WHERE NOT EXISTS (SELECT 1 FROM task_completions WHERE task_id = ?)`,
+			id, sqlTime(rv.RevealedAt), id,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("import solution reveal: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			report.RevealsImported++
+		}
+	}
+
+	solvedLessons := map[int64]bool{}
+	for _, s := range e.Submissions {
+		if _, ok, err := lessonID(s.LessonSlug); err != nil {
+			return nil, err
+		} else if !ok {
+			report.SubmissionsSkipped++
+			continue
+		}
+
+		taskID, taskLessonID, ok, err := lookupTask(s.LessonSlug, s.TaskKey)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			report.SubmissionsSkipped++
+			continue
+		}
+
+		var exists int
+		err = tx.QueryRow(
+			`SELECT COUNT(*) FROM submissions WHERE task_id = ? AND created_at = ? AND code = ?`,
+			taskID, sqlTime(s.CreatedAt), s.Code,
+		).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("check submission: %w", err)
+		}
+		if exists > 0 {
+			report.SubmissionsSkipped++
+			continue
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO submissions (task_id, code, status, stdout, stderr, created_at)
+			 VALUES (?, ?, ?, ?, ?, ?)`,
+			taskID, s.Code, s.Status, s.Stdout, s.Stderr, sqlTime(s.CreatedAt),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("import submission: %w", err)
+		}
+		report.SubmissionsAdded++
+
+		if s.Status == "success" {
+			_, err = tx.Exec(
+				`INSERT OR IGNORE INTO task_completions (task_id, points, solved_at)
+				 SELECT t.id, `+taskPointsSQL+`, ? FROM tasks t WHERE t.id = ?`,
+				sqlTime(s.CreatedAt), taskID,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("import completion: %w", err)
+			}
+			solvedLessons[taskLessonID] = true
+		}
+	}
+
+	for id := range solvedLessons {
+		if _, err := tx.Exec(lessonPointsSQL, id); err != nil {
+			return nil, fmt.Errorf("update lesson points: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit import: %w", err)
+	}
+
+	return report, nil
+}
+
+// sqlTime форматирует время так же, как CURRENT_TIMESTAMP в SQLite (UTC, без долей секунды),
+// чтобы импортированные значения корректно сравнивались и сортировались с локальными.
+func sqlTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,75 @@
+package progress
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"golearning/internal/db"
+)
+
+// newExportDB открывает чистую БД с уроком и заданием на 10 очков; возвращает ID задания.
+func newExportDB(t *testing.T) (*sql.DB, int64) {
+	t.Helper()
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := db.Migrate(database); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, q := range []string{
+		`INSERT INTO modules (id, slug, title) VALUES (1, 'module', 'Модуль')`,
+		`INSERT INTO lessons (id, module_id, slug, title) VALUES (1, 1, 'lesson', 'Урок')`,
+		`INSERT INTO tasks (id, lesson_id, title, prompt_md, points, task_key) VALUES (1, 1, 'Задание', 'Условие', 10, 'task')`,
+	} {
+		if _, err := database.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return database, 1
+}
+
+// TestImportKeepsRevealPenalty проверяет, что открытое до решения эталонное решение
+// переносится вместе с отправками и за задание после слияния начисляется столько же очков.
+func TestImportKeepsRevealPenalty(t *testing.T) {
+	srcDB, taskID := newExportDB(t)
+	src := NewRepository(srcDB)
+	if _, err := src.RevealSolution(taskID); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.CreateSubmission(&Submission{TaskID: taskID, Code: "package main", Status: "success"}); err != nil {
+		t.Fatal(err)
+	}
+	points, err := src.CompleteTask(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	export, err := src.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(export.Reveals) != 1 {
+		t.Fatalf("exported %d solution reveals, want 1", len(export.Reveals))
+	}
+
+	dstDB, _ := newExportDB(t)
+	dst := NewRepository(dstDB)
+	report, err := dst.Import(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.RevealsImported != 1 {
+		t.Errorf("imported %d solution reveals, want 1", report.RevealsImported)
+	}
+	stats, err := dst.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.EarnedPoints != points || points != 10-10*SolutionRevealPenalty/100 {
+		t.Errorf("earned %d points after import, %d before, want %d", stats.EarnedPoints, points, 10-10*SolutionRevealPenalty/100)
+	}
+}
@@ -0,0 +1,184 @@
+package progress
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HighlightColors — допустимые цвета выделений.
+var HighlightColors = map[string]bool{
+	"yellow": true,
+	"green":  true,
+	"blue":   true,
+	"pink":   true,
+}
+
+// Highlight — выделение фрагмента урока с необязательным комментарием.
+// Якорь: секция (вид и порядковый номер), смещение в её тексте и цитата с контекстом.
+type Highlight struct {
+	ID           int64     `json:"id"`
+	LessonID     int64     `json:"lesson_id"`
+	SectionKind  string    `json:"section_kind"`
+	SectionIndex int       `json:"section_index"`
+	StartOffset  int       `json:"start_offset"`
+	Quote        string    `json:"quote"`
+	Prefix       string    `json:"prefix"`
+	Suffix       string    `json:"suffix"`
+	Color        string    `json:"color"`
+	Comment      string    `json:"comment"`
+	Orphaned     bool      `json:"orphaned"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// HighlightWithLesson — выделение вместе с уроком для страницы «Мои выделения».
+type HighlightWithLesson struct {
+	Highlight
+	LessonSlug  string
+	LessonTitle string
+	ModuleTitle string
+}
+
+const highlightColumns = `h.id, h.lesson_id, h.section_kind, h.section_index, h.start_offset,
+	h.quote, h.prefix, h.suffix, h.color, h.comment, h.orphaned, h.created_at, h.updated_at`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanHighlight(row scanner, h *Highlight, extra ...interface{}) error {
+	dest := []interface{}{&h.ID, &h.LessonID, &h.SectionKind, &h.SectionIndex, &h.StartOffset,
+		&h.Quote, &h.Prefix, &h.Suffix, &h.Color, &h.Comment, &h.Orphaned, &h.CreatedAt, &h.UpdatedAt}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// CreateHighlight сохраняет новое выделение.
+func (r *Repository) CreateHighlight(h *Highlight) error {
+	if strings.TrimSpace(h.Quote) == "" {
+		return fmt.Errorf("create highlight: empty quote")
+	}
+	if !HighlightColors[h.Color] {
+		h.Color = "yellow"
+	}
+
+	result, err := r.db.Exec(
+		`INSERT INTO highlights (lesson_id, section_kind, section_index, start_offset, quote, prefix, suffix, color, comment)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		h.LessonID, h.SectionKind, h.SectionIndex, h.StartOffset, h.Quote, h.Prefix, h.Suffix, h.Color, h.Comment,
+	)
+	if err != nil {
+		return fmt.Errorf("create highlight: %w", err)
+	}
+	h.ID, _ = result.LastInsertId()
+	h.CreatedAt = time.Now()
+	h.UpdatedAt = h.CreatedAt
+	return nil
+}
+
+// GetHighlight возвращает выделение по ID.
+func (r *Repository) GetHighlight(id int64) (*Highlight, error) {
+	h := &Highlight{}
+	err := scanHighlight(r.db.QueryRow(`SELECT `+highlightColumns+` FROM highlights h WHERE h.id = ?`, id), h)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get highlight: %w", err)
+	}
+	return h, nil
+}
+
+// UpdateHighlight обновляет комментарий и цвет выделения.
+func (r *Repository) UpdateHighlight(id int64, comment, color string) error {
+	if !HighlightColors[color] {
+		color = "yellow"
+	}
+	_, err := r.db.Exec(
+		`UPDATE highlights SET comment = ?, color = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		comment, color, id,
+	)
+	if err != nil {
+		return fmt.Errorf("update highlight: %w", err)
+	}
+	return nil
+}
+
+// UpdateHighlightAnchor сохраняет якорь, заново найденный в обновлённом тексте урока.
+// orphaned — цитату найти не удалось; якорь при этом не меняется.
+func (r *Repository) UpdateHighlightAnchor(id int64, sectionKind string, sectionIndex, startOffset int, orphaned bool) error {
+	var err error
+	if orphaned {
+		_, err = r.db.Exec(`UPDATE highlights SET orphaned = 1 WHERE id = ?`, id)
+	} else {
+		_, err = r.db.Exec(
+			`UPDATE highlights SET section_kind = ?, section_index = ?, start_offset = ?, orphaned = 0
+			 WHERE id = ?`,
+			sectionKind, sectionIndex, startOffset, id,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("update highlight anchor: %w", err)
+	}
+	return nil
+}
+
+// DeleteHighlight удаляет выделение.
+func (r *Repository) DeleteHighlight(id int64) error {
+	if _, err := r.db.Exec(`DELETE FROM highlights WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete highlight: %w", err)
+	}
+	return nil
+}
+
+// ListHighlightsByLesson возвращает выделения урока в порядке текста.
+func (r *Repository) ListHighlightsByLesson(lessonID int64) ([]Highlight, error) {
+	rows, err := r.db.Query(
+		`SELECT `+highlightColumns+` FROM highlights h
+		 WHERE h.lesson_id = ?
+		 ORDER BY h.section_index, h.start_offset`,
+		lessonID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list highlights: %w", err)
+	}
+	defer rows.Close()
+
+	highlights := []Highlight{}
+	for rows.Next() {
+		var h Highlight
+		if err := scanHighlight(rows, &h); err != nil {
+			return nil, fmt.Errorf("scan highlight: %w", err)
+		}
+		highlights = append(highlights, h)
+	}
+	return highlights, rows.Err()
+}
+
+// ListAllHighlights возвращает все выделения в порядке курса, модуля и урока.
+func (r *Repository) ListAllHighlights() ([]HighlightWithLesson, error) {
+	rows, err := r.db.Query(
+		`SELECT ` + highlightColumns + `, l.slug, l.title, m.title
+		 FROM highlights h
+		 JOIN lessons l ON l.id = h.lesson_id
+		 JOIN modules m ON m.id = l.module_id
+		 LEFT JOIN courses c ON c.id = m.course_id
+		 ORDER BY COALESCE(c.order_index, 0), c.id, m.order_index, m.id, l.order_index, l.id,
+		          h.section_index, h.start_offset`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list all highlights: %w", err)
+	}
+	defer rows.Close()
+
+	var highlights []HighlightWithLesson
+	for rows.Next() {
+		var h HighlightWithLesson
+		if err := scanHighlight(rows, &h.Highlight, &h.LessonSlug, &h.LessonTitle, &h.ModuleTitle); err != nil {
+			return nil, fmt.Errorf("scan highlight: %w", err)
+		}
+		highlights = append(highlights, h)
+	}
+	return highlights, rows.Err()
+}
@@ -0,0 +1,94 @@
+package progress
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MilestoneState — отметка о выполнении этапа capstone-проекта.
+type MilestoneState struct {
+	MilestoneID  int64
+	Done         bool
+	Verified     bool // Этап подтверждён автопроверкой проекта
+	VerifyReport string
+	UpdatedAt    time.Time
+}
+
+// GetMilestoneStates возвращает отметки по всем этапам проекта.
+func (r *Repository) GetMilestoneStates(projectSlug string) (map[int64]*MilestoneState, error) {
+	rows, err := r.db.Query(
+		`SELECT mp.milestone_id, mp.done, mp.verified, mp.verify_report, mp.updated_at
+		 FROM milestone_progress mp
+		 JOIN project_milestones pm ON pm.id = mp.milestone_id
+		 WHERE pm.project_slug = ?`,
+		projectSlug,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get milestone states: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int64]*MilestoneState)
+	for rows.Next() {
+		st := &MilestoneState{}
+		if err := rows.Scan(&st.MilestoneID, &st.Done, &st.Verified, &st.VerifyReport, &st.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan milestone state: %w", err)
+		}
+		result[st.MilestoneID] = st
+	}
+	return result, rows.Err()
+}
+
+// GetMilestoneState возвращает отметку по этапу или nil, если её нет.
+func (r *Repository) GetMilestoneState(milestoneID int64) (*MilestoneState, error) {
+	st := &MilestoneState{}
+	err := r.db.QueryRow(
+		`SELECT milestone_id, done, verified, verify_report, updated_at FROM milestone_progress WHERE milestone_id = ?`,
+		milestoneID,
+	).Scan(&st.MilestoneID, &st.Done, &st.Verified, &st.VerifyReport, &st.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get milestone state: %w", err)
+	}
+	return st, nil
+}
+
+// SetMilestoneDone отмечает этап выполненным вручную или снимает отметку.
+// Снятие отметки сбрасывает и подтверждение автопроверкой.
+func (r *Repository) SetMilestoneDone(milestoneID int64, done bool) error {
+	_, err := r.db.Exec(
+		`INSERT INTO milestone_progress (milestone_id, done, verified, updated_at)
+		 VALUES (?, ?, 0, CURRENT_TIMESTAMP)
+		 ON CONFLICT(milestone_id) DO UPDATE SET
+		 done = excluded.done,
+		 verified = CASE WHEN excluded.done THEN milestone_progress.verified ELSE 0 END,
+		 updated_at = CURRENT_TIMESTAMP`,
+		milestoneID, done,
+	)
+	if err != nil {
+		return fmt.Errorf("set milestone done: %w", err)
+	}
+	return nil
+}
+
+// SaveMilestoneVerification сохраняет отчёт автопроверки этапа.
+// Успешная проверка отмечает этап выполненным; неуспешная не снимает ручную отметку.
+func (r *Repository) SaveMilestoneVerification(milestoneID int64, passed bool, report string) error {
+	_, err := r.db.Exec(
+		`INSERT INTO milestone_progress (milestone_id, done, verified, verify_report, updated_at)
+		 VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+		 ON CONFLICT(milestone_id) DO UPDATE SET
+		 done = milestone_progress.done OR excluded.done,
+		 verified = excluded.verified,
+		 verify_report = excluded.verify_report,
+		 updated_at = CURRENT_TIMESTAMP`,
+		milestoneID, passed, passed, report,
+	)
+	if err != nil {
+		return fmt.Errorf("save milestone verification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+package progress
+
+import (
+	"archive/zip"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// revisionInterval — не чаще одного снимка за этот интервал,
+	// чтобы автосохранение каждые пару секунд не засоряло историю.
+	revisionInterval = 10 * time.Minute
+	// maxRevisionsPerNote — сколько последних версий храним для одной заметки.
+	maxRevisionsPerNote = 50
+)
+
+// NoteRevision — сохранённая версия заметки.
+type NoteRevision struct {
+	ID        int64     `json:"id"`
+	LessonID  int64     `json:"lesson_id"`
+	NoteMD    string    `json:"note_md"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NoteSearchResult — заметка, найденная полнотекстовым поиском.
+type NoteSearchResult struct {
+	LessonID int64
+	Slug     string
+	Title    string
+	Snippet  string // Фрагмент текста заметки; совпадения — между SnippetMarkOpen и SnippetMarkClose
+	Rank     float64
+}
+
+// NotebookEntry — заметка урока с его положением в курсе.
+type NotebookEntry struct {
+	CourseTitle string
+	ModuleTitle string
+	LessonSlug  string
+	LessonTitle string
+	NoteMD      string
+	UpdatedAt   time.Time
+}
+
+// snapshotNote сохраняет текущую версию заметки в историю, если она не пустая и отличается
+// от newMD. Без force снимок пропускается, если последний был сделан недавно.
+func snapshotNote(tx *sql.Tx, lessonID int64, newMD string, force bool) error {
+	var current string
+	err := tx.QueryRow(`SELECT note_md FROM notes WHERE lesson_id = ?`, lessonID).Scan(&current)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get note: %w", err)
+	}
+	if strings.TrimSpace(current) == "" || current == newMD {
+		return nil
+	}
+
+	if !force {
+		var recent int
+		err := tx.QueryRow(
+			`SELECT COUNT(*) FROM note_revisions
+			 WHERE lesson_id = ? AND created_at > datetime('now', ?)`,
+			lessonID, fmt.Sprintf("-%d seconds", int(revisionInterval.Seconds())),
+		).Scan(&recent)
+		if err != nil {
+			return fmt.Errorf("check recent revision: %w", err)
+		}
+		if recent > 0 {
+			return nil
+		}
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO note_revisions (lesson_id, note_md) VALUES (?, ?)`,
+		lessonID, current,
+	); err != nil {
+		return fmt.Errorf("insert note revision: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`DELETE FROM note_revisions
+		 WHERE lesson_id = ? AND id NOT IN (
+		   SELECT id FROM note_revisions WHERE lesson_id = ? ORDER BY id DESC LIMIT ?
+		 )`,
+		lessonID, lessonID, maxRevisionsPerNote,
+	)
+	if err != nil {
+		return fmt.Errorf("trim note revisions: %w", err)
+	}
+	return nil
+}
+
+// ListNoteRevisions возвращает версии заметки, новые первыми.
+func (r *Repository) ListNoteRevisions(lessonID int64) ([]NoteRevision, error) {
+	rows, err := r.db.Query(
+		`SELECT id, lesson_id, note_md, created_at FROM note_revisions
+		 WHERE lesson_id = ? ORDER BY id DESC`,
+		lessonID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list note revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []NoteRevision
+	for rows.Next() {
+		var rev NoteRevision
+		if err := rows.Scan(&rev.ID, &rev.LessonID, &rev.NoteMD, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan note revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// RestoreNoteRevision делает версию текущей заметкой. Текущая версия при этом
+// сама попадает в историю, так что восстановление можно отменить.
+func (r *Repository) RestoreNoteRevision(lessonID, revisionID int64) (*Note, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin restore note: %w", err)
+	}
+	defer tx.Rollback()
+
+	var noteMD string
+	err = tx.QueryRow(
+		`SELECT note_md FROM note_revisions WHERE id = ? AND lesson_id = ?`,
+		revisionID, lessonID,
+	).Scan(&noteMD)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get note revision: %w", err)
+	}
+
+	if err := snapshotNote(tx, lessonID, noteMD, true); err != nil {
+		return nil, err
+	}
+	if err := upsertNote(tx, lessonID, noteMD); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit restore note: %w", err)
+	}
+	return r.GetNote(lessonID)
+}
+
+// upsertNote записывает заметку урока.
+func upsertNote(tx *sql.Tx, lessonID int64, noteMD string) error {
+	_, err := tx.Exec(
+		`INSERT INTO notes (lesson_id, note_md, updated_at)
+		 VALUES (?, ?, CURRENT_TIMESTAMP)
+		 ON CONFLICT(lesson_id) DO UPDATE SET 
+		   note_md = excluded.note_md,
+		   updated_at = CURRENT_TIMESTAMP`,
+		lessonID, noteMD,
+	)
+	if err != nil {
+		return fmt.Errorf("save note: %w", err)
+	}
+	return nil
+}
+
+// SearchNotes выполняет полнотекстовый поиск по заметкам.
+func (r *Repository) SearchNotes(query string, limit int) ([]NoteSearchResult, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	rows, err := r.db.Query(
+		`SELECT l.id, l.slug, l.title, snippet(notes_fts, 0, ?, ?, '...', 32) as snippet,
+		        bm25(notes_fts) as rank
+		 FROM notes_fts
+		 JOIN lessons l ON l.id = notes_fts.rowid
+		 WHERE notes_fts MATCH ?
+		 ORDER BY rank
+		 LIMIT ?`,
+		SnippetMarkOpen, SnippetMarkClose, query, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("search notes: %w", err)
+	}
+	defer rows.Close()
+
+	var results []NoteSearchResult
+	for rows.Next() {
+		var n NoteSearchResult
+		if err := rows.Scan(&n.LessonID, &n.Slug, &n.Title, &n.Snippet, &n.Rank); err != nil {
+			return nil, fmt.Errorf("scan note search result: %w", err)
+		}
+		results = append(results, n)
+	}
+	return results, rows.Err()
+}
+
+// Notebook возвращает все непустые заметки в порядке курса, модуля и урока.
+func (r *Repository) Notebook() ([]NotebookEntry, error) {
+	rows, err := r.db.Query(
+		`SELECT COALESCE(c.title, ''), m.title, l.slug, l.title, n.note_md, n.updated_at
+		 FROM notes n
+		 JOIN lessons l ON l.id = n.lesson_id
+		 JOIN modules m ON m.id = l.module_id
+		 LEFT JOIN courses c ON c.id = m.course_id
+		 WHERE TRIM(n.note_md) != ''
+		 ORDER BY COALESCE(c.order_index, 0), c.id, m.order_index, m.id, l.order_index, l.id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get notebook: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []NotebookEntry
+	for rows.Next() {
+		var e NotebookEntry
+		if err := rows.Scan(&e.CourseTitle, &e.ModuleTitle, &e.LessonSlug, &e.LessonTitle, &e.NoteMD, &e.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan notebook entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// WriteNotebookMarkdown пишет заметки одним Markdown-документом:
+// курс — заголовок первого уровня, модуль — второго, урок — третьего.
+func WriteNotebookMarkdown(w io.Writer, entries []NotebookEntry) error {
+	var b strings.Builder
+	b.WriteString("# 📒 Мои заметки\n")
+
+	var course, module string
+	for i, e := range entries {
+		if i == 0 || e.CourseTitle != course {
+			course, module = e.CourseTitle, ""
+			if course != "" {
+				fmt.Fprintf(&b, "\n# %s\n", course)
+			}
+		}
+		if e.ModuleTitle != module {
+			module = e.ModuleTitle
+			fmt.Fprintf(&b, "\n## %s\n", module)
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", e.LessonTitle)
+		b.WriteString(strings.TrimSpace(e.NoteMD))
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteNotebookZip пишет заметки ZIP-архивом: по файлу на урок в каталогах
+// «курс/модуль», плюс общий notebook.md. Номера в именах сохраняют порядок курса.
+func WriteNotebookZip(w io.Writer, entries []NotebookEntry) error {
+	zw := zip.NewWriter(w)
+
+	var course, module string
+	courseN, moduleN, lessonN := 0, 0, 0
+	for i, e := range entries {
+		if i == 0 || e.CourseTitle != course {
+			course, module = e.CourseTitle, ""
+			courseN++
+			moduleN = 0
+		}
+		if e.ModuleTitle != module {
+			module = e.ModuleTitle
+			moduleN++
+			lessonN = 0
+		}
+		lessonN++
+
+		name := fmt.Sprintf("%02d-%s/%02d-%s/%02d-%s.md",
+			courseN, fileName(course), moduleN, fileName(module), lessonN, e.LessonSlug)
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: e.UpdatedAt})
+		if err != nil {
+			return fmt.Errorf("create %s: %w", name, err)
+		}
+		if _, err := fmt.Fprintf(f, "# %s\n\n%s\n", e.LessonTitle, strings.TrimSpace(e.NoteMD)); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: "notebook.md", Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return fmt.Errorf("create notebook.md: %w", err)
+	}
+	if err := WriteNotebookMarkdown(f, entries); err != nil {
+		return fmt.Errorf("write notebook.md: %w", err)
+	}
+
+	return zw.Close()
+}
+
+// fileName делает из заголовка безопасное имя каталога.
+func fileName(title string) string {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "без-названия"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '-'
+		}
+		return r
+	}, title)
+}
+
+// Границы совпадений в NoteSearchResult.Snippet: управляющие символы, которых не бывает
+// в тексте заметок. Сам текст заметки не экранирован — это дело того, кто его выводит.
+const (
+	SnippetMarkOpen  = "\x02"
+	SnippetMarkClose = "\x03"
+)
@@ -0,0 +1,265 @@
+package progress
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LearningPath — пользовательский учебный путь: уроки в собственном порядке.
+type LearningPath struct {
+	ID          int64
+	Title       string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// Прогресс (заполняется в ListPaths и GetPath)
+	LessonsTotal int
+	LessonsDone  int
+	TasksTotal   int
+	TasksSolved  int
+}
+
+// PathLesson — урок внутри пути.
+type PathLesson struct {
+	LessonID    int64
+	Slug        string
+	Title       string
+	ModuleTitle string
+	Position    int
+	Status      Status
+	TasksTotal  int
+	TasksSolved int
+}
+
+// pathProgressSQL считает прогресс пути; ожидает path_id.
+const pathProgressSQL = `
+	SELECT COUNT(*),
+	       COALESCE(SUM(CASE WHEN p.status = 'done' THEN 1 ELSE 0 END), 0),
+	       COALESCE(SUM((SELECT COUNT(*) FROM tasks t WHERE t.lesson_id = pl.lesson_id)), 0),
+	       COALESCE(SUM((SELECT COUNT(*) FROM tasks t JOIN task_completions c ON c.task_id = t.id
+	                     WHERE t.lesson_id = pl.lesson_id)), 0)
+	FROM learning_path_lessons pl
+	LEFT JOIN progress p ON p.lesson_id = pl.lesson_id
+	WHERE pl.path_id = ?`
+
+// CreatePath создаёт пустой путь.
+func (r *Repository) CreatePath(title, description string) (*LearningPath, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, fmt.Errorf("create path: empty title")
+	}
+
+	result, err := r.db.Exec(
+		`INSERT INTO learning_paths (title, description) VALUES (?, ?)`,
+		title, strings.TrimSpace(description),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create path: %w", err)
+	}
+	id, _ := result.LastInsertId()
+	return r.GetPath(id)
+}
+
+// GetPath возвращает путь с прогрессом или nil, если его нет.
+func (r *Repository) GetPath(id int64) (*LearningPath, error) {
+	p := &LearningPath{}
+	err := r.db.QueryRow(
+		`SELECT id, title, description, created_at, updated_at FROM learning_paths WHERE id = ?`,
+		id,
+	).Scan(&p.ID, &p.Title, &p.Description, &p.CreatedAt, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get path: %w", err)
+	}
+
+	if err := r.fillPathProgress(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListPaths возвращает все пути с прогрессом.
+func (r *Repository) ListPaths() ([]LearningPath, error) {
+	rows, err := r.db.Query(
+		`SELECT id, title, description, created_at, updated_at FROM learning_paths ORDER BY id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list paths: %w", err)
+	}
+
+	var paths []LearningPath
+	for rows.Next() {
+		var p LearningPath
+		if err := rows.Scan(&p.ID, &p.Title, &p.Description, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan path: %w", err)
+		}
+		paths = append(paths, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list paths: %w", err)
+	}
+
+	for i := range paths {
+		if err := r.fillPathProgress(&paths[i]); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+func (r *Repository) fillPathProgress(p *LearningPath) error {
+	err := r.db.QueryRow(pathProgressSQL, p.ID).Scan(&p.LessonsTotal, &p.LessonsDone, &p.TasksTotal, &p.TasksSolved)
+	if err != nil {
+		return fmt.Errorf("get path progress: %w", err)
+	}
+	return nil
+}
+
+// DeletePath удаляет путь вместе с его списком уроков.
+func (r *Repository) DeletePath(id int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin delete path: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM learning_path_lessons WHERE path_id = ?`, id); err != nil {
+		return fmt.Errorf("delete path lessons: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM learning_paths WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete path: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListPathLessons возвращает уроки пути в его порядке.
+func (r *Repository) ListPathLessons(pathID int64) ([]PathLesson, error) {
+	rows, err := r.db.Query(
+		`SELECT l.id, l.slug, l.title, m.title, pl.position, COALESCE(p.status, 'new'),
+		        (SELECT COUNT(*) FROM tasks t WHERE t.lesson_id = l.id),
+		        (SELECT COUNT(*) FROM tasks t JOIN task_completions c ON c.task_id = t.id WHERE t.lesson_id = l.id)
+		 FROM learning_path_lessons pl
+		 JOIN lessons l ON l.id = pl.lesson_id
+		 JOIN modules m ON m.id = l.module_id
+		 LEFT JOIN progress p ON p.lesson_id = l.id
+		 WHERE pl.path_id = ?
+		 ORDER BY pl.position, l.id`,
+		pathID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list path lessons: %w", err)
+	}
+	defer rows.Close()
+
+	var lessons []PathLesson
+	for rows.Next() {
+		var pl PathLesson
+		if err := rows.Scan(&pl.LessonID, &pl.Slug, &pl.Title, &pl.ModuleTitle, &pl.Position, &pl.Status,
+			&pl.TasksTotal, &pl.TasksSolved); err != nil {
+			return nil, fmt.Errorf("scan path lesson: %w", err)
+		}
+		lessons = append(lessons, pl)
+	}
+	return lessons, rows.Err()
+}
+
+// AddLessonToPath добавляет урок в конец пути (повторное добавление ничего не меняет).
+func (r *Repository) AddLessonToPath(pathID, lessonID int64) error {
+	_, err := r.db.Exec(
+		`INSERT OR IGNORE INTO learning_path_lessons (path_id, lesson_id, position)
+		 SELECT ?, ?, COALESCE(MAX(position), -1) + 1 FROM learning_path_lessons WHERE path_id = ?`,
+		pathID, lessonID, pathID,
+	)
+	if err != nil {
+		return fmt.Errorf("add lesson to path: %w", err)
+	}
+	return r.touchPath(pathID)
+}
+
+// RemoveLessonFromPath убирает урок из пути.
+func (r *Repository) RemoveLessonFromPath(pathID, lessonID int64) error {
+	_, err := r.db.Exec(
+		`DELETE FROM learning_path_lessons WHERE path_id = ? AND lesson_id = ?`,
+		pathID, lessonID,
+	)
+	if err != nil {
+		return fmt.Errorf("remove lesson from path: %w", err)
+	}
+	return r.touchPath(pathID)
+}
+
+// ReorderPath задаёт новый порядок уроков пути. Уроки, которых нет в lessonIDs,
+// остаются в пути и идут следом в прежнем порядке.
+func (r *Repository) ReorderPath(pathID int64, lessonIDs []int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin reorder path: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, id := range lessonIDs {
+		_, err := tx.Exec(
+			`UPDATE learning_path_lessons SET position = ? WHERE path_id = ? AND lesson_id = ?`,
+			i, pathID, id,
+		)
+		if err != nil {
+			return fmt.Errorf("reorder path: %w", err)
+		}
+	}
+
+	// Неупомянутые уроки — после перечисленных
+	_, err = tx.Exec(
+		`UPDATE learning_path_lessons SET position = position + ?
+		 WHERE path_id = ? AND lesson_id NOT IN (SELECT value FROM json_each(?))`,
+		len(lessonIDs), pathID, int64sJSON(lessonIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("reorder path: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE learning_paths SET updated_at = CURRENT_TIMESTAMP WHERE id = ?`, pathID); err != nil {
+		return fmt.Errorf("touch path: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetLessonPaths возвращает ID путей, в которые входит урок.
+func (r *Repository) GetLessonPaths(lessonID int64) (map[int64]bool, error) {
+	rows, err := r.db.Query(`SELECT path_id FROM learning_path_lessons WHERE lesson_id = ?`, lessonID)
+	if err != nil {
+		return nil, fmt.Errorf("get lesson paths: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan path id: %w", err)
+		}
+		result[id] = true
+	}
+	return result, rows.Err()
+}
+
+func (r *Repository) touchPath(pathID int64) error {
+	if _, err := r.db.Exec(`UPDATE learning_paths SET updated_at = CURRENT_TIMESTAMP WHERE id = ?`, pathID); err != nil {
+		return fmt.Errorf("touch path: %w", err)
+	}
+	return nil
+}
+
+func int64sJSON(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprint(id)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
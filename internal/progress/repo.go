@@ -21,6 +21,7 @@ type Progress struct {
 	Status       Status
 	PracticeDone bool
 	PointsEarned int
+	Placement    bool // Засчитан по вступительному тесту
 	UpdatedAt    time.Time
 }
 
@@ -67,10 +68,10 @@ func NewRepository(db *sql.DB) *Repository {
 func (r *Repository) GetProgress(lessonID int64) (*Progress, error) {
 	p := &Progress{}
 	err := r.db.QueryRow(
-		`SELECT lesson_id, status, practice_done, points_earned, updated_at 
+		`SELECT lesson_id, status, practice_done, points_earned, placement, updated_at 
 		 FROM progress WHERE lesson_id = ?`,
 		lessonID,
-	).Scan(&p.LessonID, &p.Status, &p.PracticeDone, &p.PointsEarned, &p.UpdatedAt)
+	).Scan(&p.LessonID, &p.Status, &p.PracticeDone, &p.PointsEarned, &p.Placement, &p.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		// Возвращаем дефолтный прогресс
@@ -107,37 +108,58 @@ func (r *Repository) UpdateProgress(p *Progress) error {
 	return nil
 }
 
-// SetStatus устанавливает статус урока.
+// SetStatus устанавливает статус урока. Явно выставленный статус снимает отметку placement.
 func (r *Repository) SetStatus(lessonID int64, status Status) error {
 	_, err := r.db.Exec(
 		`INSERT INTO progress (lesson_id, status, updated_at)
 		 VALUES (?, ?, CURRENT_TIMESTAMP)
 		 ON CONFLICT(lesson_id) DO UPDATE SET 
 		   status = excluded.status,
+		   placement = 0,
 		   updated_at = CURRENT_TIMESTAMP`,
 		lessonID, status,
 	)
 	return err
 }
 
-// SetPracticeDone отмечает практику как выполненную.
-func (r *Repository) SetPracticeDone(lessonID int64, points int) error {
-	_, err := r.db.Exec(
-		`INSERT INTO progress (lesson_id, practice_done, points_earned, updated_at)
-		 VALUES (?, 1, ?, CURRENT_TIMESTAMP)
-		 ON CONFLICT(lesson_id) DO UPDATE SET 
-		   practice_done = 1,
-		   points_earned = points_earned + excluded.points_earned,
-		   updated_at = CURRENT_TIMESTAMP`,
-		lessonID, points,
-	)
-	return err
+// MarkLessonsPlaced отмечает уроки пройденными по вступительному тесту.
+// Уже пройденные уроки не трогаются; очки не начисляются. Возвращает число отмеченных уроков.
+func (r *Repository) MarkLessonsPlaced(lessonIDs []int64) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin mark placed: %w", err)
+	}
+	defer tx.Rollback()
+
+	marked := 0
+	for _, id := range lessonIDs {
+		res, err := tx.Exec(
+			`INSERT INTO progress (lesson_id, status, placement, updated_at)
+			 VALUES (?, 'done', 1, CURRENT_TIMESTAMP)
+			 ON CONFLICT(lesson_id) DO UPDATE SET
+			   status = 'done',
+			   placement = 1,
+			   updated_at = CURRENT_TIMESTAMP
+			 WHERE progress.status != 'done'`,
+			id,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("mark lesson placed: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		marked += int(n)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit mark placed: %w", err)
+	}
+	return marked, nil
 }
 
 // GetAllProgress возвращает прогресс по всем урокам.
 func (r *Repository) GetAllProgress() (map[int64]*Progress, error) {
 	rows, err := r.db.Query(
-		`SELECT lesson_id, status, practice_done, points_earned, updated_at FROM progress`,
+		`SELECT lesson_id, status, practice_done, points_earned, placement, updated_at FROM progress`,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("get all progress: %w", err)
@@ -147,7 +169,7 @@ func (r *Repository) GetAllProgress() (map[int64]*Progress, error) {
 	result := make(map[int64]*Progress)
 	for rows.Next() {
 		p := &Progress{}
-		if err := rows.Scan(&p.LessonID, &p.Status, &p.PracticeDone, &p.PointsEarned, &p.UpdatedAt); err != nil {
+		if err := rows.Scan(&p.LessonID, &p.Status, &p.PracticeDone, &p.PointsEarned, &p.Placement, &p.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan progress: %w", err)
 		}
 		result[p.LessonID] = p
@@ -176,17 +198,21 @@ func (r *Repository) GetNote(lessonID int64) (*Note, error) {
 	return n, nil
 }
 
-// SaveNote сохраняет заметку к уроку.
+// SaveNote сохраняет заметку к уроку, откладывая прежнюю версию в историю.
 func (r *Repository) SaveNote(lessonID int64, noteMD string) error {
-	_, err := r.db.Exec(
-		`INSERT INTO notes (lesson_id, note_md, updated_at)
-		 VALUES (?, ?, CURRENT_TIMESTAMP)
-		 ON CONFLICT(lesson_id) DO UPDATE SET 
-		   note_md = excluded.note_md,
-		   updated_at = CURRENT_TIMESTAMP`,
-		lessonID, noteMD,
-	)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin save note: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := snapshotNote(tx, lessonID, noteMD, false); err != nil {
+		return err
+	}
+	if err := upsertNote(tx, lessonID, noteMD); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // --- Submissions ---
@@ -218,7 +244,7 @@ func (r *Repository) UpdateSubmission(s *Submission) error {
 func (r *Repository) IsTaskSolvedSuccessfully(taskID int64) (bool, error) {
 	var count int
 	err := r.db.QueryRow(
-		`SELECT COUNT(*) FROM submissions WHERE task_id = ? AND status = 'success'`,
+		`SELECT COUNT(*) FROM task_completions WHERE task_id = ?`,
 		taskID,
 	).Scan(&count)
 	if err != nil {
@@ -257,12 +283,30 @@ func (r *Repository) GetSubmissionsByTaskID(taskID int64, limit int) ([]Submissi
 
 // --- Stats ---
 
-// ResetAllProgress сбрасывает весь прогресс (очки, статусы, отправки).
+// ResetAllProgress сбрасывает весь прогресс (очки, статусы, отправки, учёт времени, этапы проектов).
 func (r *Repository) ResetAllProgress() error {
-	// Удаляем все отправки
+	// Удаляем все отправки, отметки о решении и учтённое время
 	if _, err := r.db.Exec(`DELETE FROM submissions`); err != nil {
 		return fmt.Errorf("delete submissions: %w", err)
 	}
+	if _, err := r.db.Exec(`DELETE FROM task_completions`); err != nil {
+		return fmt.Errorf("delete task completions: %w", err)
+	}
+	if _, err := r.db.Exec(`DELETE FROM task_reviews`); err != nil {
+		return fmt.Errorf("delete task reviews: %w", err)
+	}
+	if _, err := r.db.Exec(`DELETE FROM solution_reveals`); err != nil {
+		return fmt.Errorf("delete solution reveals: %w", err)
+	}
+	if _, err := r.db.Exec(`DELETE FROM milestone_progress`); err != nil {
+		return fmt.Errorf("delete milestone progress: %w", err)
+	}
+	if _, err := r.db.Exec(`DELETE FROM task_time`); err != nil {
+		return fmt.Errorf("delete task time: %w", err)
+	}
+	if _, err := r.db.Exec(`DELETE FROM lesson_time`); err != nil {
+		return fmt.Errorf("delete lesson time: %w", err)
+	}
 	// Удаляем весь прогресс
 	if _, err := r.db.Exec(`DELETE FROM progress`); err != nil {
 		return fmt.Errorf("delete progress: %w", err)
@@ -299,8 +343,10 @@ func (r *Repository) GetStats() (*Stats, error) {
 		return nil, fmt.Errorf("sum total points: %w", err)
 	}
 
-	// Заработанные очки
-	err = r.db.QueryRow(`SELECT COALESCE(SUM(points_earned), 0) FROM progress`).Scan(&stats.EarnedPoints)
+	// Заработанные очки (по решённым заданиям, которые ещё есть в курсе)
+	err = r.db.QueryRow(
+		`SELECT COALESCE(SUM(c.points), 0) FROM task_completions c JOIN tasks t ON t.id = c.task_id`,
+	).Scan(&stats.EarnedPoints)
 	if err != nil {
 		return nil, fmt.Errorf("sum earned points: %w", err)
 	}
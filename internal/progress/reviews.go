@@ -0,0 +1,301 @@
+package progress
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrReviewPending — по заданию уже есть сдача, ожидающая проверки.
+var ErrReviewPending = errors.New("review already pending")
+
+// ErrReviewClosed — сдача уже проверена.
+var ErrReviewClosed = errors.New("review already closed")
+
+// Виды артефактов сдачи manual-задания.
+const (
+	ArtifactPath    = "path"    // Путь к репозиторию на сервере
+	ArtifactArchive = "archive" // Загруженный архив
+	ArtifactLink    = "link"    // Ссылка (PR, репозиторий) с описанием
+)
+
+// Статусы сдачи на проверку.
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// TaskReview — сдача manual-задания на проверку.
+type TaskReview struct {
+	ID           int64
+	TaskID       int64
+	ArtifactKind string
+	Artifact     string // Путь, ссылка или имя архива
+	ArchiveSize  int
+	Description  string
+	VerifyReport string // Отчёт автопроверки проекта, если сдача пришла из неё
+	Status       string
+	Feedback     string
+	CreatedAt    time.Time
+	ReviewedAt   *time.Time
+}
+
+// ReviewWithTask — сдача вместе с заданием и уроком (для очереди проверки).
+type ReviewWithTask struct {
+	TaskReview
+	TaskTitle   string
+	Criteria    string
+	Points      int
+	LessonSlug  string
+	LessonTitle string
+}
+
+const reviewColumns = `rv.id, rv.task_id, rv.artifact_kind, rv.artifact, COALESCE(LENGTH(rv.archive), 0),
+	rv.description, rv.verify_report, rv.status, rv.feedback, rv.created_at, rv.reviewed_at`
+
+func scanReview(s scanner, rv *TaskReview, extra ...interface{}) error {
+	var reviewedAt sql.NullTime
+	dest := []interface{}{&rv.ID, &rv.TaskID, &rv.ArtifactKind, &rv.Artifact, &rv.ArchiveSize,
+		&rv.Description, &rv.VerifyReport, &rv.Status, &rv.Feedback, &rv.CreatedAt, &reviewedAt}
+	if err := s.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	if reviewedAt.Valid {
+		rv.ReviewedAt = &reviewedAt.Time
+	}
+	return nil
+}
+
+// CreateReview отправляет manual-задание на проверку.
+// Возвращает ErrReviewPending, если предыдущая сдача ещё не проверена.
+func (r *Repository) CreateReview(rv *TaskReview, archive []byte) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin create review: %w", err)
+	}
+	defer tx.Rollback()
+
+	var pending int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FROM task_reviews WHERE task_id = ? AND status = 'pending'`,
+		rv.TaskID,
+	).Scan(&pending)
+	if err != nil {
+		return fmt.Errorf("check pending review: %w", err)
+	}
+	if pending > 0 {
+		return ErrReviewPending
+	}
+
+	result, err := tx.Exec(
+		`INSERT INTO task_reviews (task_id, artifact_kind, artifact, archive, description, verify_report)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		rv.TaskID, rv.ArtifactKind, rv.Artifact, archive, rv.Description, rv.VerifyReport,
+	)
+	if err != nil {
+		return fmt.Errorf("create review: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit create review: %w", err)
+	}
+
+	rv.ID, _ = result.LastInsertId()
+	rv.ArchiveSize = len(archive)
+	rv.Status = ReviewPending
+	rv.CreatedAt = time.Now()
+	return nil
+}
+
+// SubmitVerifiedProject прикладывает отчёт успешной автопроверки проекта dir к ожидающей
+// сдаче задания, а если её нет — создаёт сдачу с путём к проекту. Очки не начисляются:
+// их, как и за любую сдачу manual-задания, даёт только одобрение (DecideReview).
+func (r *Repository) SubmitVerifiedProject(taskID int64, dir, report string) (*TaskReview, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin submit verified project: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRow(
+		`SELECT id FROM task_reviews WHERE task_id = ? AND status = 'pending' ORDER BY id DESC LIMIT 1`,
+		taskID,
+	).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		res, err := tx.Exec(
+			`INSERT INTO task_reviews (task_id, artifact_kind, artifact, verify_report) VALUES (?, 'path', ?, ?)`,
+			taskID, dir, report,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("create review: %w", err)
+		}
+		id, _ = res.LastInsertId()
+	case err != nil:
+		return nil, fmt.Errorf("get pending review: %w", err)
+	default:
+		if _, err := tx.Exec(`UPDATE task_reviews SET verify_report = ? WHERE id = ?`, report, id); err != nil {
+			return nil, fmt.Errorf("attach verify report: %w", err)
+		}
+	}
+
+	rv := &TaskReview{}
+	if err := scanReview(tx.QueryRow(`SELECT `+reviewColumns+` FROM task_reviews rv WHERE rv.id = ?`, id), rv); err != nil {
+		return nil, fmt.Errorf("get review: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit submit verified project: %w", err)
+	}
+	return rv, nil
+}
+
+// GetReview возвращает сдачу по ID или nil, если её нет.
+func (r *Repository) GetReview(id int64) (*TaskReview, error) {
+	rv := &TaskReview{}
+	err := scanReview(r.db.QueryRow(
+		`SELECT `+reviewColumns+` FROM task_reviews rv WHERE rv.id = ?`, id,
+	), rv)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get review: %w", err)
+	}
+	return rv, nil
+}
+
+// GetReviewArchive возвращает загруженный архив сдачи и его имя.
+func (r *Repository) GetReviewArchive(id int64) (string, []byte, error) {
+	var name string
+	var data []byte
+	err := r.db.QueryRow(
+		`SELECT artifact, archive FROM task_reviews WHERE id = ? AND artifact_kind = 'archive'`, id,
+	).Scan(&name, &data)
+	if err == sql.ErrNoRows {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("get review archive: %w", err)
+	}
+	return name, data, nil
+}
+
+// GetLatestReviews возвращает последнюю сдачу по каждому заданию урока.
+func (r *Repository) GetLatestReviews(lessonID int64) (map[int64]*TaskReview, error) {
+	rows, err := r.db.Query(
+		`SELECT `+reviewColumns+`
+		 FROM task_reviews rv
+		 JOIN tasks t ON t.id = rv.task_id
+		 WHERE t.lesson_id = ?
+		   AND rv.id = (SELECT MAX(id) FROM task_reviews WHERE task_id = rv.task_id)`,
+		lessonID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get latest reviews: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int64]*TaskReview)
+	for rows.Next() {
+		rv := &TaskReview{}
+		if err := scanReview(rows, rv); err != nil {
+			return nil, fmt.Errorf("scan review: %w", err)
+		}
+		result[rv.TaskID] = rv
+	}
+	return result, rows.Err()
+}
+
+// ListReviews возвращает сдачи с заданным статусом (пустой — все): ожидающие — старые первыми,
+// остальные — новые первыми.
+func (r *Repository) ListReviews(status string) ([]ReviewWithTask, error) {
+	order := "rv.id DESC"
+	if status == ReviewPending {
+		order = "rv.id"
+	}
+
+	rows, err := r.db.Query(
+		`SELECT `+reviewColumns+`, t.title, t.criteria, t.points, l.slug, l.title
+		 FROM task_reviews rv
+		 JOIN tasks t ON t.id = rv.task_id
+		 JOIN lessons l ON l.id = t.lesson_id
+		 WHERE ? = '' OR rv.status = ?
+		 ORDER BY `+order,
+		status, status,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var result []ReviewWithTask
+	for rows.Next() {
+		var rw ReviewWithTask
+		if err := scanReview(rows, &rw.TaskReview,
+			&rw.TaskTitle, &rw.Criteria, &rw.Points, &rw.LessonSlug, &rw.LessonTitle); err != nil {
+			return nil, fmt.Errorf("scan review: %w", err)
+		}
+		result = append(result, rw)
+	}
+	return result, rows.Err()
+}
+
+// DecideReview одобряет или отклоняет сдачу с отзывом. При одобрении создаётся
+// успешная отправка и начисляются очки (один раз на задание); возвращает начисленные очки.
+func (r *Repository) DecideReview(id int64, approve bool, feedback string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin decide review: %w", err)
+	}
+	defer tx.Rollback()
+
+	var taskID int64
+	var status, kind, artifact string
+	err = tx.QueryRow(
+		`SELECT task_id, status, artifact_kind, artifact FROM task_reviews WHERE id = ?`, id,
+	).Scan(&taskID, &status, &kind, &artifact)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("review %d not found", id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get review: %w", err)
+	}
+	if status != ReviewPending {
+		return 0, ErrReviewClosed
+	}
+
+	newStatus := ReviewRejected
+	if approve {
+		newStatus = ReviewApproved
+	}
+	_, err = tx.Exec(
+		`UPDATE task_reviews SET status = ?, feedback = ?, reviewed_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		newStatus, feedback, id,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("update review: %w", err)
+	}
+
+	points := 0
+	if approve {
+		// Успешная отправка — для истории и для пересчёта очков
+		_, err = tx.Exec(
+			`INSERT INTO submissions (task_id, code, status, stdout, stderr) VALUES (?, ?, 'success', ?, '')`,
+			taskID, fmt.Sprintf("[review #%d] %s: %s", id, kind, artifact), feedback,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("create submission: %w", err)
+		}
+		if points, err = completeTaskTx(tx, taskID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit decide review: %w", err)
+	}
+	return points, nil
+}
@@ -0,0 +1,61 @@
+package progress
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// SolutionRevealPenalty — сколько процентов очков задания теряется, если открыть
+// эталонное решение до того, как задание решено.
+const SolutionRevealPenalty = 50
+
+// taskPointsSQL — очки за решение задания t с учётом открытого заранее решения.
+var taskPointsSQL = `t.points - CASE WHEN EXISTS (SELECT 1 FROM solution_reveals sr WHERE sr.task_id = t.id)
+	THEN t.points * ` + strconv.Itoa(SolutionRevealPenalty) + ` / 100 ELSE 0 END`
+
+// RevealSolution отмечает, что эталонное решение открыто. Для ещё не решённого задания
+// это уменьшает будущие очки; возвращает, сколько очков теряется (0 — задание уже
+// решено или решение было открыто раньше).
+func (r *Repository) RevealSolution(taskID int64) (int, error) {
+	res, err := r.db.Exec(
+		`INSERT OR IGNORE INTO solution_reveals (task_id)
+		 SELECT ? WHERE NOT EXISTS (SELECT 1 FROM task_completions WHERE task_id = ?)`,
+		taskID, taskID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("reveal solution: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, nil
+	}
+
+	var points int
+	if err := r.db.QueryRow(`SELECT points FROM tasks WHERE id = ?`, taskID).Scan(&points); err != nil {
+		return 0, fmt.Errorf("get task points: %w", err)
+	}
+	return points * SolutionRevealPenalty / 100, nil
+}
+
+// GetRevealedSolutions возвращает задания урока, решения которых открыты до решения.
+func (r *Repository) GetRevealedSolutions(lessonID int64) (map[int64]bool, error) {
+	rows, err := r.db.Query(
+		`SELECT sr.task_id FROM solution_reveals sr
+		 JOIN tasks t ON t.id = sr.task_id
+		 WHERE t.lesson_id = ?`,
+		lessonID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get revealed solutions: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int64]bool)
+	for rows.Next() {
+		var taskID int64
+		if err := rows.Scan(&taskID); err != nil {
+			return nil, fmt.Errorf("scan revealed solution: %w", err)
+		}
+		result[taskID] = true
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,163 @@
+package progress
+
+import "fmt"
+
+// MaxHeartbeatSec — верхняя граница одного heartbeat-события.
+// Защищает от накрутки, если клиент долго не отправлял события (сон, потеря сети).
+const MaxHeartbeatSec = 60
+
+// ChapterTime — ожидаемое и фактическое время чтения главы (модуля).
+type ChapterTime struct {
+	CourseTitle    string
+	ModuleTitle    string
+	LessonsTotal   int
+	LessonsVisited int
+	EstimatedMin   int // Оценка по всем урокам главы
+	VisitedEstMin  int // Оценка только по открытым урокам — база для сравнения
+	ActualSec      int
+}
+
+// PercentOfEstimate возвращает фактическое время в процентах от оценки открытых уроков.
+func (c ChapterTime) PercentOfEstimate() int {
+	if c.VisitedEstMin == 0 {
+		return 0
+	}
+	return c.ActualSec * 100 / (c.VisitedEstMin * 60)
+}
+
+// TaskTime — время, затраченное на задание.
+type TaskTime struct {
+	LessonSlug  string
+	LessonTitle string
+	TaskTitle   string
+	ActiveSec   int
+	Solved      bool
+}
+
+// AddActiveTime добавляет активное время к уроку и, если указано, к заданию.
+// Время задания перестаёт копиться после его первого успешного решения.
+func (r *Repository) AddActiveTime(lessonID, taskID int64, seconds int) error {
+	if seconds <= 0 {
+		return nil
+	}
+	if seconds > MaxHeartbeatSec {
+		seconds = MaxHeartbeatSec
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin add time: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`INSERT INTO lesson_time (lesson_id, active_sec, updated_at)
+		 SELECT id, ?, CURRENT_TIMESTAMP FROM lessons WHERE id = ?
+		 ON CONFLICT(lesson_id) DO UPDATE SET
+		   active_sec = active_sec + excluded.active_sec,
+		   updated_at = CURRENT_TIMESTAMP`,
+		seconds, lessonID,
+	)
+	if err != nil {
+		return fmt.Errorf("add lesson time: %w", err)
+	}
+
+	if taskID > 0 {
+		_, err = tx.Exec(
+			`INSERT INTO task_time (task_id, active_sec, updated_at)
+			 SELECT t.id, ?, CURRENT_TIMESTAMP FROM tasks t
+			 WHERE t.id = ? AND t.lesson_id = ?
+			   AND NOT EXISTS (SELECT 1 FROM task_completions c WHERE c.task_id = t.id)
+			 ON CONFLICT(task_id) DO UPDATE SET
+			   active_sec = active_sec + excluded.active_sec,
+			   updated_at = CURRENT_TIMESTAMP`,
+			seconds, taskID, lessonID,
+		)
+		if err != nil {
+			return fmt.Errorf("add task time: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit add time: %w", err)
+	}
+	return nil
+}
+
+// GetLessonActiveSec возвращает фактическое время на уроке в секундах.
+func (r *Repository) GetLessonActiveSec(lessonID int64) (int, error) {
+	var sec int
+	err := r.db.QueryRow(
+		`SELECT COALESCE(SUM(active_sec), 0) FROM lesson_time WHERE lesson_id = ?`,
+		lessonID,
+	).Scan(&sec)
+	if err != nil {
+		return 0, fmt.Errorf("get lesson time: %w", err)
+	}
+	return sec, nil
+}
+
+// GetChapterTimeStats возвращает ожидаемое и фактическое время по главам в порядке курса.
+func (r *Repository) GetChapterTimeStats() ([]ChapterTime, error) {
+	rows, err := r.db.Query(
+		`SELECT COALESCE(c.title, ''), m.title,
+		        COUNT(l.id),
+		        COUNT(lt.lesson_id),
+		        COALESCE(SUM(l.reading_time_min), 0),
+		        COALESCE(SUM(CASE WHEN lt.lesson_id IS NOT NULL THEN l.reading_time_min END), 0),
+		        COALESCE(SUM(lt.active_sec), 0)
+		 FROM modules m
+		 LEFT JOIN courses c ON c.id = m.course_id
+		 JOIN lessons l ON l.module_id = m.id
+		 LEFT JOIN lesson_time lt ON lt.lesson_id = l.id
+		 GROUP BY m.id
+		 ORDER BY c.order_index, m.order_index`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get chapter time stats: %w", err)
+	}
+	defer rows.Close()
+
+	var result []ChapterTime
+	for rows.Next() {
+		var ct ChapterTime
+		if err := rows.Scan(&ct.CourseTitle, &ct.ModuleTitle, &ct.LessonsTotal, &ct.LessonsVisited,
+			&ct.EstimatedMin, &ct.VisitedEstMin, &ct.ActualSec); err != nil {
+			return nil, fmt.Errorf("scan chapter time: %w", err)
+		}
+		result = append(result, ct)
+	}
+	return result, rows.Err()
+}
+
+// GetTaskTimeStats возвращает задания, на которые тратилось время, — самые долгие первыми.
+func (r *Repository) GetTaskTimeStats(limit int) ([]TaskTime, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	rows, err := r.db.Query(
+		`SELECT l.slug, l.title, t.title, tt.active_sec, c.task_id IS NOT NULL
+		 FROM task_time tt
+		 JOIN tasks t ON t.id = tt.task_id
+		 JOIN lessons l ON l.id = t.lesson_id
+		 LEFT JOIN task_completions c ON c.task_id = t.id
+		 ORDER BY tt.active_sec DESC
+		 LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get task time stats: %w", err)
+	}
+	defer rows.Close()
+
+	var result []TaskTime
+	for rows.Next() {
+		var tt TaskTime
+		if err := rows.Scan(&tt.LessonSlug, &tt.LessonTitle, &tt.TaskTitle, &tt.ActiveSec, &tt.Solved); err != nil {
+			return nil, fmt.Errorf("scan task time: %w", err)
+		}
+		result = append(result, tt)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,125 @@
+package web
+
+import (
+	"net/http"
+
+	"golearning/internal/content"
+	"golearning/internal/progress"
+)
+
+// lessonGraph — граф зависимостей вместе с уроками курса и отметками о прохождении.
+type lessonGraph struct {
+	graph   *content.Graph
+	order   []int64
+	lessons map[int64]content.Lesson
+	done    map[int64]bool
+}
+
+// loadLessonGraph собирает граф зависимостей и состояние прохождения уроков.
+func (s *Server) loadLessonGraph() (*lessonGraph, error) {
+	edges, err := s.contentRepo.ListPrerequisites()
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.contentRepo.ListAllLessons()
+	if err != nil {
+		return nil, err
+	}
+	progressMap, err := s.progressRepo.GetAllProgress()
+	if err != nil {
+		return nil, err
+	}
+
+	lg := &lessonGraph{
+		graph:   content.NewGraph(edges),
+		order:   make([]int64, 0, len(all)),
+		lessons: make(map[int64]content.Lesson, len(all)),
+		done:    make(map[int64]bool),
+	}
+	for _, l := range all {
+		lg.order = append(lg.order, l.ID)
+		lg.lessons[l.ID] = l
+	}
+	for id, p := range progressMap {
+		if p.Status == progress.StatusDone {
+			lg.done[id] = true
+		}
+	}
+	return lg, nil
+}
+
+// missing возвращает непройденные пререквизиты урока.
+func (lg *lessonGraph) missing(lessonID int64) []content.Lesson {
+	var result []content.Lesson
+	for _, id := range lg.graph.Missing(lessonID, lg.done) {
+		result = append(result, lg.lessons[id])
+	}
+	return result
+}
+
+// recommend возвращает рекомендуемый следующий урок или nil.
+// Текущий урок считается пройденным: рекомендация — это «что читать после него».
+func (lg *lessonGraph) recommend(current int64) *content.Lesson {
+	done := lg.done
+	if current != 0 && !done[current] {
+		done = make(map[int64]bool, len(lg.done)+1)
+		for id := range lg.done {
+			done[id] = true
+		}
+		done[current] = true
+	}
+
+	id := lg.graph.Recommend(current, lg.order, done)
+	if id == 0 {
+		return nil
+	}
+	l := lg.lessons[id]
+	return &l
+}
+
+// handleGraph — SVG-схема зависимостей между уроками.
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	lg, err := s.loadLessonGraph()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	progressMap, _ := s.progressRepo.GetAllProgress()
+
+	type Node struct {
+		content.GraphNode
+		Lesson content.Lesson
+		Status progress.Status
+		Ready  bool
+	}
+
+	layout := lg.graph.Layout(lg.order)
+	nodes := make([]Node, 0, len(layout.Nodes))
+	for _, n := range layout.Nodes {
+		status := progress.StatusNew
+		if p, ok := progressMap[n.LessonID]; ok {
+			status = p.Status
+		}
+		nodes = append(nodes, Node{
+			GraphNode: n,
+			Lesson:    lg.lessons[n.LessonID],
+			Status:    status,
+			Ready:     lg.graph.Ready(n.LessonID, lg.done),
+		})
+	}
+
+	// Загружаем статистику для шапки
+	stats, _ := s.progressRepo.GetStats()
+
+	data := map[string]interface{}{
+		"Layout":      layout,
+		"Nodes":       nodes,
+		"NodeWidth":   content.GraphNodeWidth,
+		"NodeHeight":  content.GraphNodeHeight,
+		"Recommended": lg.recommend(0),
+		"Stats":       stats,
+	}
+
+	s.render(w, "graph.html", data)
+}
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"embed"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"html/template"
 	"io/fs"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -35,6 +38,7 @@ type Server struct {
 	progressRepo *progress.Repository
 	checker      *practice.Checker
 	templates    *template.Template
+	live         *liveHub // nil, если live reload выключен
 }
 
 // NewServer создаёт новый сервер.
@@ -57,6 +61,7 @@ func NewServer(contentRepo *content.Repository, progressRepo *progress.Repositor
 		"safeHTML": func(s string) template.HTML {
 			return template.HTML(s)
 		},
+		"highlight": highlightSnippet,
 		"markdown": func(s string) template.HTML {
 			var buf bytes.Buffer
 			if err := md.Convert([]byte(s), &buf); err != nil {
@@ -113,6 +118,14 @@ func NewServer(contentRepo *content.Repository, progressRepo *progress.Repositor
 			}
 			return float64(a) / float64(b)
 		},
+		"duration": formatDuration,
+		"truncate": func(s string, n int) string {
+			runes := []rune(s)
+			if len(runes) <= n {
+				return s
+			}
+			return string(runes[:n-1]) + "…"
+		},
 	}
 
 	tmpl, err := template.New("").Funcs(funcMap).ParseFS(templatesFS, "templates/*.html")
@@ -146,14 +159,48 @@ func (s *Server) Router() http.Handler {
 	r.Get("/lessons/{slug}", s.handleLesson)
 	r.Get("/search", s.handleSearch)
 	r.Get("/projects", s.handleProjects)
+	r.Get("/projects/{slug}", s.handleProject)
+	r.Get("/stats", s.handleStats)
+	r.Get("/dashboard", s.handleDashboard)
+	r.Get("/highlights", s.handleHighlights)
+	r.Get("/paths", s.handlePaths)
+	r.Get("/paths/{id}", s.handlePath)
+	r.Get("/graph", s.handleGraph)
+	r.Get("/placement", s.handlePlacement)
+	r.Get("/reviews", s.handleReviews)
 
 	// API
 	r.Post("/api/progress/lesson/{id}", s.handleUpdateProgress)
 	r.Post("/api/progress/reset", s.handleResetProgress)
+	r.Get("/api/progress/export", s.handleExportProgress)
+	r.Post("/api/progress/import", s.handleImportProgress)
 	r.Post("/api/notes/lesson/{id}", s.handleSaveNote)
+	r.Get("/api/notes/lesson/{id}/revisions", s.handleNoteRevisions)
+	r.Post("/api/notes/lesson/{id}/revisions/{rev}/restore", s.handleRestoreNote)
+	r.Get("/api/notes/export", s.handleExportNotes)
+	r.Get("/api/highlights/lesson/{id}", s.handleLessonHighlights)
+	r.Post("/api/highlights", s.handleCreateHighlight)
+	r.Post("/api/highlights/{id}", s.handleUpdateHighlight)
+	r.Post("/api/highlights/{id}/anchor", s.handleReanchorHighlight)
+	r.Delete("/api/highlights/{id}", s.handleDeleteHighlight)
 	r.Post("/api/run", s.handleRun)
 	r.Post("/api/check", s.handleCheck)
-	r.Post("/api/tasks/{id}/complete", s.handleCompleteTask)
+	r.Post("/api/placement/module", s.handleGradePlacement)
+	r.Post("/api/tasks/{id}/review", s.handleSubmitReview)
+	r.Post("/api/tasks/{id}/verify", s.handleVerifyProject)
+	r.Post("/api/tasks/{id}/solution", s.handleRevealSolution)
+	r.Post("/api/projects/{id}/milestones/{mid}", s.handleSetMilestone)
+	r.Post("/api/projects/{id}/milestones/{mid}/verify", s.handleVerifyMilestone)
+	r.Post("/api/reviews/{id}", s.handleDecideReview)
+	r.Get("/api/reviews/{id}/archive", s.handleReviewArchive)
+	r.Post("/api/time/heartbeat", s.handleHeartbeat)
+	r.Get("/api/live", s.handleLive)
+	r.Post("/api/bookmarks", s.handleToggleBookmark)
+	r.Post("/api/paths", s.handleCreatePath)
+	r.Delete("/api/paths/{id}", s.handleDeletePath)
+	r.Post("/api/paths/{id}/lessons", s.handleAddPathLesson)
+	r.Delete("/api/paths/{id}/lessons/{lessonID}", s.handleRemovePathLesson)
+	r.Post("/api/paths/{id}/order", s.handleReorderPath)
 
 	return r
 }
@@ -214,10 +261,17 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	progressMap, _ := s.progressRepo.GetAllProgress()
 	stats, _ := s.progressRepo.GetStats()
 
+	// Рекомендуемый урок с учётом пререквизитов
+	var recommended *content.Lesson
+	if lg, err := s.loadLessonGraph(); err == nil {
+		recommended = lg.recommend(0)
+	}
+
 	data := map[string]interface{}{
-		"Courses":  coursesWithModules,
-		"Progress": progressMap,
-		"Stats":    stats,
+		"Courses":     coursesWithModules,
+		"Progress":    progressMap,
+		"Stats":       stats,
+		"Recommended": recommended,
 	}
 
 	s.render(w, "index.html", data)
@@ -247,25 +301,67 @@ func (s *Server) handleLesson(w http.ResponseWriter, r *http.Request) {
 		prog.Status = progress.StatusReading
 	}
 
-	// Загружаем соседние уроки для навигации
-	allLessons, _ := s.contentRepo.ListAllLessons()
+	// Загружаем соседние уроки для навигации: внутри учебного пути (?path=ID) или по курсу
 	var prevLesson, nextLesson *content.Lesson
-	for i, l := range allLessons {
-		if l.ID == lesson.ID {
-			if i > 0 {
-				prevLesson = &allLessons[i-1]
+	var activePath *progress.LearningPath
+	var pathPosition int
+	if pathID, err := strconv.ParseInt(r.URL.Query().Get("path"), 10, 64); err == nil {
+		activePath, _ = s.progressRepo.GetPath(pathID)
+		if activePath != nil {
+			pathLessons, _ := s.progressRepo.ListPathLessons(pathID)
+			for i, pl := range pathLessons {
+				if pl.LessonID == lesson.ID {
+					pathPosition = i + 1
+					if i > 0 {
+						prevLesson = &content.Lesson{ID: pathLessons[i-1].LessonID, Slug: pathLessons[i-1].Slug, Title: pathLessons[i-1].Title}
+					}
+					if i < len(pathLessons)-1 {
+						nextLesson = &content.Lesson{ID: pathLessons[i+1].LessonID, Slug: pathLessons[i+1].Slug, Title: pathLessons[i+1].Title}
+					}
+					break
+				}
 			}
-			if i < len(allLessons)-1 {
-				nextLesson = &allLessons[i+1]
+			// Урок не из этого пути — обычная навигация
+			if pathPosition == 0 {
+				activePath = nil
 			}
-			break
 		}
 	}
+	if activePath == nil {
+		allLessons, _ := s.contentRepo.ListAllLessons()
+		for i, l := range allLessons {
+			if l.ID == lesson.ID {
+				if i > 0 {
+					prevLesson = &allLessons[i-1]
+				}
+				if i < len(allLessons)-1 {
+					nextLesson = &allLessons[i+1]
+				}
+				break
+			}
+		}
+	}
+
+	// Пререквизиты и рекомендация по графу зависимостей
+	var missingPrereqs []content.Lesson
+	var recommended *content.Lesson
+	if lg, err := s.loadLessonGraph(); err == nil {
+		missingPrereqs = lg.missing(lesson.ID)
+		recommended = lg.recommend(lesson.ID)
+	} else {
+		log.Printf("Lesson graph error: %v", err)
+	}
+
+	// Закладки и пути, в которые входит урок
+	bookmarks, _ := s.progressRepo.GetLessonBookmarks(lesson.ID)
+	paths, _ := s.progressRepo.ListPaths()
+	lessonPaths, _ := s.progressRepo.GetLessonPaths(lesson.ID)
 
 	// Загружаем статистику для шапки
 	stats, _ := s.progressRepo.GetStats()
 
 	// Загружаем список выполненных заданий
+	taskStats, _ := s.progressRepo.GetLessonTaskStats(lesson.ID)
 	completedTasks := make(map[int64]bool)
 	if lesson.Tasks != nil {
 		for _, task := range lesson.Tasks {
@@ -275,6 +371,15 @@ func (s *Server) handleLesson(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Фактическое время на уроке
+	activeSec, _ := s.progressRepo.GetLessonActiveSec(lesson.ID)
+
+	// Последние сдачи manual-заданий на проверку
+	reviews, _ := s.progressRepo.GetLatestReviews(lesson.ID)
+
+	// Эталонные решения, открытые до решения задания
+	revealed, _ := s.progressRepo.GetRevealedSolutions(lesson.ID)
+
 	data := map[string]interface{}{
 		"Lesson":         lesson,
 		"Progress":       prog,
@@ -283,6 +388,19 @@ func (s *Server) handleLesson(w http.ResponseWriter, r *http.Request) {
 		"NextLesson":     nextLesson,
 		"Stats":          stats,
 		"CompletedTasks": completedTasks,
+		"TaskStats":      taskStats,
+		"ActiveSec":      activeSec,
+		"Reviews":        reviews,
+		"Revealed":       revealed,
+		"RevealPenalty":  progress.SolutionRevealPenalty,
+		"Bookmarks":      bookmarks,
+		"Paths":          paths,
+		"LessonPaths":    lessonPaths,
+		"ActivePath":     activePath,
+		"PathPosition":   pathPosition,
+		"MissingPrereqs": missingPrereqs,
+		"Recommended":    recommended,
+		"LiveReload":     s.live != nil,
 	}
 
 	s.render(w, "lesson.html", data)
@@ -293,6 +411,7 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 
 	var results []content.SearchResult
+	var noteResults []progress.NoteSearchResult
 	var err error
 
 	if query != "" {
@@ -301,20 +420,100 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 			log.Printf("Search error: %v", err)
 			// Не показываем ошибку пользователю, просто пустые результаты
 		}
+		noteResults, err = s.progressRepo.SearchNotes(query, 20)
+		if err != nil {
+			log.Printf("Notes search error: %v", err)
+		}
 	}
 
 	// Загружаем статистику для шапки
 	stats, _ := s.progressRepo.GetStats()
 
 	data := map[string]interface{}{
-		"Query":   query,
-		"Results": results,
-		"Stats":   stats,
+		"Query":       query,
+		"Results":     results,
+		"NoteResults": noteResults,
+		"Stats":       stats,
 	}
 
 	s.render(w, "search.html", data)
 }
 
+// handleStats — страница статистики: ожидаемое и фактическое время по главам.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	chapters, err := s.progressRepo.GetChapterTimeStats()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	tasks, err := s.progressRepo.GetTaskTimeStats(20)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	var estimatedMin, actualSec int
+	for _, c := range chapters {
+		estimatedMin += c.EstimatedMin
+		actualSec += c.ActualSec
+	}
+
+	// Загружаем статистику для шапки
+	stats, _ := s.progressRepo.GetStats()
+
+	data := map[string]interface{}{
+		"Chapters":     chapters,
+		"Tasks":        tasks,
+		"EstimatedMin": estimatedMin,
+		"ActualSec":    actualSec,
+		"Stats":        stats,
+	}
+
+	s.render(w, "stats.html", data)
+}
+
+// handleDashboard — дашборд ученика: прохождение курсов и модулей, трудные задания, активность.
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	courses, err := s.progressRepo.GetCourseCompletion()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	hardest, err := s.progressRepo.GetHardestTasks(10)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	avgAttempts, solvedCount, err := s.progressRepo.GetAverageAttemptsToSuccess()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	activity, err := s.progressRepo.GetRecentActivity(20)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	// Загружаем статистику для шапки
+	stats, _ := s.progressRepo.GetStats()
+
+	data := map[string]interface{}{
+		"Courses":     courses,
+		"Hardest":     hardest,
+		"AvgAttempts": avgAttempts,
+		"SolvedCount": solvedCount,
+		"Activity":    activity,
+		"Stats":       stats,
+	}
+
+	s.render(w, "dashboard.html", data)
+}
+
 // --- API Handlers ---
 
 // handleUpdateProgress обновляет прогресс урока.
@@ -357,6 +556,43 @@ func (s *Server) handleResetProgress(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleExportProgress отдаёт прогресс, заметки и отправки в виде JSON-файла.
+func (s *Server) handleExportProgress(w http.ResponseWriter, r *http.Request) {
+	export, err := s.progressRepo.Export()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	filename := "golearning-progress-" + export.ExportedAt.Format("2006-01-02") + ".json"
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	s.jsonResponse(w, export)
+}
+
+// handleImportProgress сливает загруженный файл экспорта с текущим прогрессом.
+func (s *Server) handleImportProgress(w http.ResponseWriter, r *http.Request) {
+	var export progress.Export
+	if err := json.NewDecoder(r.Body).Decode(&export); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
+	}
+
+	report, err := s.progressRepo.Import(&export)
+	if errors.Is(err, progress.ErrUnsupportedExport) {
+		s.badRequest(w, err.Error())
+		return
+	}
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{
+		"success": true,
+		"report":  report,
+	})
+}
+
 // handleSaveNote сохраняет заметку.
 func (s *Server) handleSaveNote(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
@@ -383,6 +619,80 @@ func (s *Server) handleSaveNote(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, map[string]interface{}{"success": true})
 }
 
+// handleNoteRevisions возвращает историю версий заметки.
+func (s *Server) handleNoteRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.badRequest(w, "Invalid lesson ID")
+		return
+	}
+
+	revisions, err := s.progressRepo.ListNoteRevisions(id)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if revisions == nil {
+		revisions = []progress.NoteRevision{}
+	}
+
+	s.jsonResponse(w, map[string]interface{}{"revisions": revisions})
+}
+
+// handleRestoreNote восстанавливает заметку из версии.
+func (s *Server) handleRestoreNote(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.badRequest(w, "Invalid lesson ID")
+		return
+	}
+	revID, err := strconv.ParseInt(chi.URLParam(r, "rev"), 10, 64)
+	if err != nil {
+		s.badRequest(w, "Invalid revision ID")
+		return
+	}
+
+	note, err := s.progressRepo.RestoreNoteRevision(id, revID)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if note == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{
+		"success": true,
+		"note":    note.NoteMD,
+	})
+}
+
+// handleExportNotes отдаёт все заметки одним Markdown-файлом или ZIP-архивом (?format=zip).
+func (s *Server) handleExportNotes(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.progressRepo.Notebook()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	filename := "golearning-notes-" + time.Now().Format("2006-01-02")
+	if r.URL.Query().Get("format") == "zip" {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.zip"`)
+		if err := progress.WriteNotebookZip(w, entries); err != nil {
+			log.Printf("Notes export error: %v", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.md"`)
+	if err := progress.WriteNotebookMarkdown(w, entries); err != nil {
+		log.Printf("Notes export error: %v", err)
+	}
+}
+
 // handleRun выполняет Go-код.
 func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -439,64 +749,61 @@ func (s *Server) handleCheck(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, result)
 }
 
-// handleCompleteTask отмечает manual‑задание выполненным (self-report) и начисляет очки один раз.
-func (s *Server) handleCompleteTask(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-	taskID, err := strconv.ParseInt(idStr, 10, 64)
+// handleRevealSolution отдаёт эталонное решение задания. Если задание ещё не решено,
+// открытие решения уменьшает очки за него (progress.SolutionRevealPenalty).
+func (s *Server) handleRevealSolution(w http.ResponseWriter, r *http.Request) {
+	taskID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil || taskID <= 0 {
 		s.badRequest(w, "Invalid task ID")
 		return
 	}
 
-	task, err := s.contentRepo.GetTaskByID(taskID)
+	solution, err := s.contentRepo.GetTaskSolution(taskID)
 	if err != nil {
 		s.serverError(w, err)
 		return
 	}
-	if task == nil {
+	if solution == "" {
 		http.NotFound(w, r)
 		return
 	}
 
-	if strings.TrimSpace(task.Mode) != "manual" {
-		s.badRequest(w, "Task is not manual")
-		return
-	}
-
-	alreadySolved, err := s.progressRepo.IsTaskSolvedSuccessfully(taskID)
+	pointsLost, err := s.progressRepo.RevealSolution(taskID)
 	if err != nil {
 		s.serverError(w, err)
 		return
 	}
 
-	pointsAwarded := 0
-	if !alreadySolved {
-		// Создаём success-submission (для бейджа «✅ Выполнено» и истории)
-		submission := &progress.Submission{
-			TaskID: taskID,
-			Code:   "[manual]",
-			Status: "success",
-			Stdout: "",
-			Stderr: "",
-		}
-		if err := s.progressRepo.CreateSubmission(submission); err != nil {
-			s.serverError(w, err)
-			return
-		}
+	s.jsonResponse(w, map[string]interface{}{
+		"solution":    solution,
+		"points_lost": pointsLost,
+	})
+}
 
-		// Начисляем очки только при первом выполнении
-		if err := s.progressRepo.SetPracticeDone(task.LessonID, task.Points); err != nil {
-			s.serverError(w, err)
-			return
-		}
+// handleHeartbeat учитывает активное время, пока вкладка урока видима.
+func (s *Server) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		LessonID int64 `json:"lesson_id"`
+		TaskID   int64 `json:"task_id"`
+		Seconds  int   `json:"seconds"`
+	}
 
-		pointsAwarded = task.Points
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
 	}
 
-	s.jsonResponse(w, map[string]interface{}{
-		"success":        true,
-		"points_awarded": pointsAwarded,
-	})
+	if req.LessonID <= 0 {
+		s.badRequest(w, "Lesson ID is required")
+		return
+	}
+
+	if err := s.progressRepo.AddActiveTime(req.LessonID, req.TaskID, req.Seconds); err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{"success": true})
 }
 
 // --- Helpers ---
@@ -522,3 +829,24 @@ func (s *Server) serverError(w http.ResponseWriter, err error) {
 func (s *Server) badRequest(w http.ResponseWriter, msg string) {
 	http.Error(w, msg, http.StatusBadRequest)
 }
+
+// highlightSnippet экранирует фрагмент заметки из поиска и размечает совпадения тегом <mark>.
+// Заметки пишет пользователь или приносит импорт прогресса — как HTML их выводить нельзя.
+func highlightSnippet(s string) template.HTML {
+	s = template.HTMLEscapeString(s)
+	s = strings.NewReplacer(progress.SnippetMarkOpen, "<mark>", progress.SnippetMarkClose, "</mark>").Replace(s)
+	return template.HTML(s)
+}
+
+// formatDuration форматирует секунды в «1 ч 5 мин», «12 мин» или «40 сек».
+func formatDuration(sec int) string {
+	if sec < 60 {
+		return fmt.Sprintf("%d сек", sec)
+	}
+	// Сначала округляем до минут: 59 мин 45 сек — это уже «1 ч 0 мин»
+	min := (sec + 30) / 60
+	if min < 60 {
+		return fmt.Sprintf("%d мин", min)
+	}
+	return fmt.Sprintf("%d ч %d мин", min/60, min%60)
+}
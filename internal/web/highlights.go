@@ -0,0 +1,181 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"golearning/internal/progress"
+)
+
+// handleHighlights — страница «Мои выделения», сгруппированная по урокам.
+func (s *Server) handleHighlights(w http.ResponseWriter, r *http.Request) {
+	highlights, err := s.progressRepo.ListAllHighlights()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	type LessonHighlights struct {
+		Slug        string
+		Title       string
+		ModuleTitle string
+		Highlights  []progress.HighlightWithLesson
+	}
+
+	var lessons []LessonHighlights
+	for _, h := range highlights {
+		if len(lessons) == 0 || lessons[len(lessons)-1].Slug != h.LessonSlug {
+			lessons = append(lessons, LessonHighlights{
+				Slug:        h.LessonSlug,
+				Title:       h.LessonTitle,
+				ModuleTitle: h.ModuleTitle,
+			})
+		}
+		last := &lessons[len(lessons)-1]
+		last.Highlights = append(last.Highlights, h)
+	}
+
+	// Загружаем статистику для шапки
+	stats, _ := s.progressRepo.GetStats()
+
+	data := map[string]interface{}{
+		"Lessons": lessons,
+		"Total":   len(highlights),
+		"Stats":   stats,
+	}
+
+	s.render(w, "highlights.html", data)
+}
+
+// handleLessonHighlights возвращает выделения урока.
+func (s *Server) handleLessonHighlights(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.badRequest(w, "Invalid lesson ID")
+		return
+	}
+
+	highlights, err := s.progressRepo.ListHighlightsByLesson(id)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{"highlights": highlights})
+}
+
+// handleCreateHighlight создаёт выделение.
+func (s *Server) handleCreateHighlight(w http.ResponseWriter, r *http.Request) {
+	var h progress.Highlight
+	if err := json.NewDecoder(r.Body).Decode(&h); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
+	}
+
+	if h.LessonID <= 0 || h.Quote == "" {
+		s.badRequest(w, "Lesson ID and quote are required")
+		return
+	}
+
+	if err := s.progressRepo.CreateHighlight(&h); err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{
+		"success":   true,
+		"highlight": h,
+	})
+}
+
+// handleUpdateHighlight меняет комментарий и цвет выделения.
+func (s *Server) handleUpdateHighlight(w http.ResponseWriter, r *http.Request) {
+	h, ok := s.highlightFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Comment string `json:"comment"`
+		Color   string `json:"color"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
+	}
+	if req.Color == "" {
+		req.Color = h.Color
+	}
+
+	if err := s.progressRepo.UpdateHighlight(h.ID, req.Comment, req.Color); err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{"success": true})
+}
+
+// handleReanchorHighlight сохраняет якорь, заново найденный на клиенте после обновления урока.
+func (s *Server) handleReanchorHighlight(w http.ResponseWriter, r *http.Request) {
+	h, ok := s.highlightFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		SectionKind  string `json:"section_kind"`
+		SectionIndex int    `json:"section_index"`
+		StartOffset  int    `json:"start_offset"`
+		Orphaned     bool   `json:"orphaned"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
+	}
+
+	err := s.progressRepo.UpdateHighlightAnchor(h.ID, req.SectionKind, req.SectionIndex, req.StartOffset, req.Orphaned)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{"success": true})
+}
+
+// handleDeleteHighlight удаляет выделение.
+func (s *Server) handleDeleteHighlight(w http.ResponseWriter, r *http.Request) {
+	h, ok := s.highlightFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.progressRepo.DeleteHighlight(h.ID); err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{"success": true})
+}
+
+// highlightFromURL загружает выделение по {id} из URL, отвечая ошибкой, если его нет.
+func (s *Server) highlightFromURL(w http.ResponseWriter, r *http.Request) (*progress.Highlight, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.badRequest(w, "Invalid highlight ID")
+		return nil, false
+	}
+
+	h, err := s.progressRepo.GetHighlight(id)
+	if err != nil {
+		s.serverError(w, err)
+		return nil, false
+	}
+	if h == nil {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	return h, true
+}
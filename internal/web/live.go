@@ -0,0 +1,103 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// liveKeepAlive — период комментариев-пингов, чтобы прокси не закрывали соединение.
+const liveKeepAlive = 30 * time.Second
+
+// liveHub рассылает открытым вкладкам события о перезагрузке урока.
+type liveHub struct {
+	mu   sync.Mutex
+	subs map[chan string]string // канал вкладки → slug открытого урока
+}
+
+// EnableLiveReload включает SSE-эндпоинт /api/live: страницы уроков подписываются
+// на него и перезагружаются после NotifyLessonChanged. Вызывается до Router.
+func (s *Server) EnableLiveReload() {
+	s.live = &liveHub{subs: make(map[chan string]string)}
+}
+
+// NotifyLessonChanged просит вкладки с уроком slug перезагрузиться.
+func (s *Server) NotifyLessonChanged(slug string) {
+	s.notifyLesson(slug, slug)
+}
+
+// NotifyLessonMoved отправляет вкладки с уроком oldSlug на урок newSlug:
+// в файле урока сменился заголовок, а с ним и slug.
+func (s *Server) NotifyLessonMoved(oldSlug, newSlug string) {
+	s.notifyLesson(oldSlug, newSlug)
+}
+
+// notifyLesson отправляет вкладкам урока slug событие reload с уроком target.
+func (s *Server) notifyLesson(slug, target string) {
+	if s.live == nil {
+		return
+	}
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+	for ch, subSlug := range s.live.subs {
+		if subSlug != slug {
+			continue
+		}
+		select {
+		case ch <- target:
+		default: // событие уже ждёт отправки
+		}
+	}
+}
+
+// handleLive — поток server-sent events для вкладки урока ?lesson=slug.
+func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+	if s.live == nil {
+		http.NotFound(w, r)
+		return
+	}
+	slug := r.URL.Query().Get("lesson")
+	if slug == "" {
+		s.badRequest(w, "lesson is required")
+		return
+	}
+
+	// Поток живёт, пока открыта вкладка, — общий WriteTimeout к нему не относится
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	ch := make(chan string, 1)
+	s.live.mu.Lock()
+	s.live.subs[ch] = slug
+	s.live.mu.Unlock()
+	defer func() {
+		s.live.mu.Lock()
+		delete(s.live.subs, ch)
+		s.live.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	ticker := time.NewTicker(liveKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case target := <-ch:
+			fmt.Fprintf(w, "event: reload\ndata: %s\n\n", target)
+		case <-ticker.C:
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"golearning/internal/progress"
+)
+
+// handlePaths — список учебных путей и закладок.
+func (s *Server) handlePaths(w http.ResponseWriter, r *http.Request) {
+	paths, err := s.progressRepo.ListPaths()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	bookmarks, err := s.progressRepo.ListBookmarks()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	// Загружаем статистику для шапки
+	stats, _ := s.progressRepo.GetStats()
+
+	data := map[string]interface{}{
+		"Paths":     paths,
+		"Bookmarks": bookmarks,
+		"Stats":     stats,
+	}
+
+	s.render(w, "paths.html", data)
+}
+
+// handlePath — страница одного пути: уроки по порядку, прогресс, редактирование.
+func (s *Server) handlePath(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	path, err := s.progressRepo.GetPath(id)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if path == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	lessons, err := s.progressRepo.ListPathLessons(id)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	// Все уроки — для выбора при добавлении в путь
+	allLessons, err := s.contentRepo.ListAllLessons()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	// Первый непройденный урок — кнопка «Продолжить»
+	var next *progress.PathLesson
+	for i := range lessons {
+		if lessons[i].Status != progress.StatusDone {
+			next = &lessons[i]
+			break
+		}
+	}
+
+	// Загружаем статистику для шапки
+	stats, _ := s.progressRepo.GetStats()
+
+	data := map[string]interface{}{
+		"Path":       path,
+		"Lessons":    lessons,
+		"AllLessons": allLessons,
+		"Next":       next,
+		"Stats":      stats,
+	}
+
+	s.render(w, "path.html", data)
+}
+
+// --- API ---
+
+// handleToggleBookmark ставит или снимает закладку на урок/задание.
+func (s *Server) handleToggleBookmark(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		LessonID int64 `json:"lesson_id"`
+		TaskID   int64 `json:"task_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
+	}
+	if req.LessonID <= 0 {
+		s.badRequest(w, "Lesson ID is required")
+		return
+	}
+
+	bookmarked, err := s.progressRepo.ToggleBookmark(req.LessonID, req.TaskID)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{
+		"success":    true,
+		"bookmarked": bookmarked,
+	})
+}
+
+// handleCreatePath создаёт путь (и сразу добавляет урок, если передан lesson_id).
+func (s *Server) handleCreatePath(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		LessonID    int64  `json:"lesson_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
+	}
+	if req.Title == "" {
+		s.badRequest(w, "Title is required")
+		return
+	}
+
+	path, err := s.progressRepo.CreatePath(req.Title, req.Description)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if req.LessonID > 0 {
+		if err := s.progressRepo.AddLessonToPath(path.ID, req.LessonID); err != nil {
+			s.serverError(w, err)
+			return
+		}
+	}
+
+	s.jsonResponse(w, map[string]interface{}{
+		"success": true,
+		"id":      path.ID,
+	})
+}
+
+// handleDeletePath удаляет путь.
+func (s *Server) handleDeletePath(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.progressRepo.DeletePath(id); err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{"success": true})
+}
+
+// handleAddPathLesson добавляет урок в конец пути.
+func (s *Server) handleAddPathLesson(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		LessonID int64 `json:"lesson_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
+	}
+	if req.LessonID <= 0 {
+		s.badRequest(w, "Lesson ID is required")
+		return
+	}
+
+	if err := s.progressRepo.AddLessonToPath(id, req.LessonID); err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{"success": true})
+}
+
+// handleRemovePathLesson убирает урок из пути.
+func (s *Server) handleRemovePathLesson(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	lessonID, err := strconv.ParseInt(chi.URLParam(r, "lessonID"), 10, 64)
+	if err != nil {
+		s.badRequest(w, "Invalid lesson ID")
+		return
+	}
+
+	if err := s.progressRepo.RemoveLessonFromPath(id, lessonID); err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{"success": true})
+}
+
+// handleReorderPath сохраняет новый порядок уроков пути.
+func (s *Server) handleReorderPath(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		LessonIDs []int64 `json:"lesson_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
+	}
+
+	if err := s.progressRepo.ReorderPath(id, req.LessonIDs); err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{"success": true})
+}
+
+// pathIDFromURL проверяет, что путь {id} из URL существует.
+func (s *Server) pathIDFromURL(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.badRequest(w, "Invalid path ID")
+		return 0, false
+	}
+
+	path, err := s.progressRepo.GetPath(id)
+	if err != nil {
+		s.serverError(w, err)
+		return 0, false
+	}
+	if path == nil {
+		http.NotFound(w, r)
+		return 0, false
+	}
+	return id, true
+}
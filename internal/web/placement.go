@@ -0,0 +1,115 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"golearning/internal/content"
+	"golearning/internal/practice"
+	"golearning/internal/progress"
+)
+
+// placementModuleView — модуль вступительного теста вместе с состоянием уроков.
+type placementModuleView struct {
+	practice.PlacementModule
+	LessonsDone   int
+	LessonsPlaced int
+}
+
+// handlePlacement — вступительный тест по курсу: по 1–2 задания на модуль.
+func (s *Server) handlePlacement(w http.ResponseWriter, r *http.Request) {
+	courses, err := s.contentRepo.ListCourses()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	var course *content.Course
+	slug := r.URL.Query().Get("course")
+	for i := range courses {
+		if slug == "" || courses[i].Slug == slug {
+			course = &courses[i]
+			break
+		}
+	}
+	if course == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	exam, err := s.checker.PlacementExam(course.ID)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	progressMap, err := s.progressRepo.GetAllProgress()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	modules := make([]placementModuleView, 0, len(exam))
+	for _, pm := range exam {
+		view := placementModuleView{PlacementModule: pm}
+		for _, l := range pm.Lessons {
+			if p, ok := progressMap[l.ID]; ok && p.Status == progress.StatusDone {
+				view.LessonsDone++
+				if p.Placement {
+					view.LessonsPlaced++
+				}
+			}
+		}
+		modules = append(modules, view)
+	}
+
+	// Загружаем статистику для шапки
+	stats, _ := s.progressRepo.GetStats()
+
+	data := map[string]interface{}{
+		"Course":  course,
+		"Courses": courses,
+		"Modules": modules,
+		"Stats":   stats,
+	}
+
+	s.render(w, "placement.html", data)
+}
+
+// handleGradePlacement проверяет ответы на задания одного модуля теста.
+func (s *Server) handleGradePlacement(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ModuleID int64 `json:"module_id"`
+		Answers  []struct {
+			TaskID int64  `json:"task_id"`
+			Code   string `json:"code"`
+		} `json:"answers"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
+	}
+
+	if req.ModuleID == 0 {
+		s.badRequest(w, "Module ID is required")
+		return
+	}
+
+	answers := make(map[int64]string, len(req.Answers))
+	for _, a := range req.Answers {
+		answers[a.TaskID] = a.Code
+	}
+
+	result, err := s.checker.GradePlacementModule(r.Context(), req.ModuleID, answers)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if result == nil {
+		s.badRequest(w, "Module has no placement tasks")
+		return
+	}
+
+	s.jsonResponse(w, result)
+}
@@ -1,40 +1,319 @@
 package web
 
 import (
+	"encoding/json"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"golearning"
+	"github.com/go-chi/chi/v5"
+
+	"golearning/internal/content"
+	"golearning/internal/practice"
+	"golearning/internal/progress"
 )
 
-type Project struct {
-	ID       string
-	Title    string
-	Subtitle string
-	SpecMD   string
+// MilestoneLesson — урок, от которого зависит этап.
+type MilestoneLesson struct {
+	Slug  string
+	Title string
+	Done  bool
+}
+
+// MilestoneView — этап проекта с отметкой и зависимостями для страницы проекта.
+type MilestoneView struct {
+	content.Milestone
+	State   *progress.MilestoneState
+	Lessons []MilestoneLesson
 }
 
+// MilestoneSection — этапы одного раздела ТЗ.
+type MilestoneSection struct {
+	Title      string
+	Milestones []MilestoneView
+}
+
+// ProjectView — проект с этапами и прогрессом.
+type ProjectView struct {
+	content.Project
+	Sections []MilestoneSection
+	Done     int
+	Total    int
+}
+
+// Percent возвращает долю выполненных этапов в процентах.
+func (p ProjectView) Percent() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return p.Done * 100 / p.Total
+}
+
+// handleProjects — список capstone-проектов с прогрессом по этапам.
 func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
 	stats, _ := s.progressRepo.GetStats()
 
-	projects := []Project{
-		{
-			ID:       "capstone-rest",
-			Title:    "Capstone REST: сервис заказов (Gin + Postgres)",
-			Subtitle: "JWT, миграции, интеграционные тесты, CI, Docker Compose, метрики/логи/трейсы, нагрузка и профили",
-			SpecMD:   golearning.CapstoneRESTSpecMD,
-		},
-		{
-			ID:       "capstone-grpc",
-			Title:    "Capstone gRPC: Users/Accounts сервис (gRPC + TLS/mTLS)",
-			Subtitle: "Interceptors, deadlines, безопасность, наблюдаемость; опционально grpc-gateway + OpenAPI",
-			SpecMD:   golearning.CapstoneGRPCSpecMD,
-		},
+	projects, err := s.contentRepo.ListProjects()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	views := make([]ProjectView, 0, len(projects))
+	for _, p := range projects {
+		done, total, err := s.milestoneCounts(p.Slug)
+		if err != nil {
+			s.serverError(w, err)
+			return
+		}
+		views = append(views, ProjectView{Project: p, Done: done, Total: total})
 	}
 
 	data := map[string]interface{}{
 		"Stats":    stats,
-		"Projects": projects,
+		"Projects": views,
 	}
 
 	s.render(w, "projects.html", data)
 }
+
+// handleProject — страница проекта: этапы по разделам ТЗ, связанные уроки и полное ТЗ.
+func (s *Server) handleProject(w http.ResponseWriter, r *http.Request) {
+	project, err := s.contentRepo.GetProjectBySlug(chi.URLParam(r, "slug"))
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if project == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	stats, _ := s.progressRepo.GetStats()
+
+	lessons, err := s.contentRepo.ListAllLessons()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	lessonsBySlug := make(map[string]content.Lesson, len(lessons))
+	for _, l := range lessons {
+		lessonsBySlug[l.Slug] = l
+	}
+
+	progressMap, err := s.progressRepo.GetAllProgress()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	view, err := s.buildProjectView(*project, lessonsBySlug, progressMap)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Stats":          stats,
+		"Project":        view,
+		"RelatedLessons": milestoneLessons(project.LessonSlugs, lessonsBySlug, progressMap),
+	}
+
+	s.render(w, "project.html", data)
+}
+
+// milestoneLessons возвращает существующие уроки по slug вместе с отметкой о прохождении.
+func milestoneLessons(slugs []string, lessonsBySlug map[string]content.Lesson, progressMap map[int64]*progress.Progress) []MilestoneLesson {
+	var result []MilestoneLesson
+	for _, slug := range slugs {
+		l, ok := lessonsBySlug[slug]
+		if !ok {
+			continue
+		}
+		pr := progressMap[l.ID]
+		result = append(result, MilestoneLesson{
+			Slug:  l.Slug,
+			Title: l.Title,
+			Done:  pr != nil && pr.Status == progress.StatusDone,
+		})
+	}
+	return result
+}
+
+// buildProjectView группирует этапы проекта по разделам ТЗ и считает прогресс.
+func (s *Server) buildProjectView(p content.Project, lessonsBySlug map[string]content.Lesson, progressMap map[int64]*progress.Progress) (*ProjectView, error) {
+	milestones, err := s.contentRepo.ListMilestones(p.Slug)
+	if err != nil {
+		return nil, err
+	}
+	states, err := s.progressRepo.GetMilestoneStates(p.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &ProjectView{Project: p, Total: len(milestones)}
+	for _, ms := range milestones {
+		mv := MilestoneView{
+			Milestone: ms,
+			State:     states[ms.ID],
+			Lessons:   milestoneLessons(ms.LessonSlugs, lessonsBySlug, progressMap),
+		}
+		if mv.State != nil && mv.State.Done {
+			view.Done++
+		}
+
+		n := len(view.Sections)
+		if n == 0 || view.Sections[n-1].Title != ms.Section {
+			view.Sections = append(view.Sections, MilestoneSection{Title: ms.Section})
+			n++
+		}
+		view.Sections[n-1].Milestones = append(view.Sections[n-1].Milestones, mv)
+	}
+	return view, nil
+}
+
+// projectMilestone находит этап по параметрам URL; nil — если этапа нет в этом проекте.
+func (s *Server) projectMilestone(r *http.Request) (*content.Milestone, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "mid"), 10, 64)
+	if err != nil || id <= 0 {
+		return nil, nil
+	}
+	ms, err := s.contentRepo.GetMilestone(id)
+	if err != nil || ms == nil {
+		return nil, err
+	}
+	if ms.ProjectSlug != chi.URLParam(r, "id") {
+		return nil, nil
+	}
+	return ms, nil
+}
+
+// milestoneCounts возвращает число выполненных и всех этапов проекта.
+func (s *Server) milestoneCounts(projectSlug string) (int, int, error) {
+	milestones, err := s.contentRepo.ListMilestones(projectSlug)
+	if err != nil {
+		return 0, 0, err
+	}
+	states, err := s.progressRepo.GetMilestoneStates(projectSlug)
+	if err != nil {
+		return 0, 0, err
+	}
+	done := 0
+	for _, st := range states {
+		if st.Done {
+			done++
+		}
+	}
+	return done, len(milestones), nil
+}
+
+// handleSetMilestone отмечает этап проекта выполненным или снимает отметку.
+func (s *Server) handleSetMilestone(w http.ResponseWriter, r *http.Request) {
+	ms, err := s.projectMilestone(r)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if ms == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req struct {
+		Done bool `json:"done"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
+	}
+
+	if err := s.progressRepo.SetMilestoneDone(ms.ID, req.Done); err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	done, total, err := s.milestoneCounts(ms.ProjectSlug)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{
+		"success":       true,
+		"done":          req.Done,
+		"project_done":  done,
+		"project_total": total,
+	})
+}
+
+// handleVerifyMilestone проверяет локальный проект скриптом этапа.
+// Успешная проверка отмечает этап выполненным.
+func (s *Server) handleVerifyMilestone(w http.ResponseWriter, r *http.Request) {
+	ms, err := s.projectMilestone(r)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if ms == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if ms.VerifyScript == "" {
+		s.badRequest(w, "Milestone has no verify script")
+		return
+	}
+
+	var req struct {
+		Dir string `json:"dir"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
+	}
+	req.Dir = strings.TrimSpace(req.Dir)
+	if req.Dir == "" {
+		s.badRequest(w, "Directory is required")
+		return
+	}
+	if info, err := os.Stat(req.Dir); err != nil || !info.IsDir() {
+		s.badRequest(w, "Directory not found on server")
+		return
+	}
+
+	steps, err := practice.ParseVerifyScript(ms.VerifyScript)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	// go build/vet/test могут идти дольше общего WriteTimeout сервера
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Now().Add(time.Duration(len(steps)+1) * practice.ProjectStepTimeout))
+
+	result, err := practice.VerifyProject(r.Context(), req.Dir, steps)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	if err := s.progressRepo.SaveMilestoneVerification(ms.ID, result.Success, result.Report()); err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	done, total, err := s.milestoneCounts(ms.ProjectSlug)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{
+		"success":       result.Success,
+		"steps":         result.Steps,
+		"report":        result.Report(),
+		"project_done":  done,
+		"project_total": total,
+	})
+}
@@ -0,0 +1,316 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"golearning/internal/practice"
+	"golearning/internal/progress"
+)
+
+// maxReviewArchiveBytes — предельный размер архива, загружаемого на проверку.
+const maxReviewArchiveBytes = 20 << 20
+
+// reviewArchiveExts — допустимые расширения архивов.
+var reviewArchiveExts = []string{".zip", ".tar.gz", ".tgz"}
+
+// handleReviews — очередь проверки manual-заданий и история решений.
+func (s *Server) handleReviews(w http.ResponseWriter, r *http.Request) {
+	pending, err := s.progressRepo.ListReviews(progress.ReviewPending)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	all, err := s.progressRepo.ListReviews("")
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	var history []progress.ReviewWithTask
+	for _, rv := range all {
+		if rv.Status != progress.ReviewPending {
+			history = append(history, rv)
+		}
+	}
+
+	// Загружаем статистику для шапки
+	stats, _ := s.progressRepo.GetStats()
+
+	data := map[string]interface{}{
+		"Pending": pending,
+		"History": history,
+		"Stats":   stats,
+	}
+
+	s.render(w, "reviews.html", data)
+}
+
+// handleSubmitReview принимает артефакт manual-задания и ставит его в очередь проверки.
+// Форма (multipart): kind = path|archive|link, path, link, archive, description.
+func (s *Server) handleSubmitReview(w http.ResponseWriter, r *http.Request) {
+	taskID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || taskID <= 0 {
+		s.badRequest(w, "Invalid task ID")
+		return
+	}
+
+	task, err := s.contentRepo.GetTaskByID(taskID)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if task == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if strings.TrimSpace(task.Mode) != "manual" {
+		s.badRequest(w, "Task is not manual")
+		return
+	}
+
+	solved, err := s.progressRepo.IsTaskSolvedSuccessfully(taskID)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if solved {
+		s.badRequest(w, "Task is already approved")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxReviewArchiveBytes+1<<20)
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		s.badRequest(w, "Invalid form or archive is too large")
+		return
+	}
+
+	rv := &progress.TaskReview{
+		TaskID:       taskID,
+		ArtifactKind: r.FormValue("kind"),
+		Description:  strings.TrimSpace(r.FormValue("description")),
+	}
+	var archive []byte
+
+	switch rv.ArtifactKind {
+	case progress.ArtifactPath:
+		path := strings.TrimSpace(r.FormValue("path"))
+		if path == "" {
+			s.badRequest(w, "Path is required")
+			return
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			s.badRequest(w, "Invalid path")
+			return
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			s.badRequest(w, "Directory not found on server")
+			return
+		}
+		rv.Artifact = abs
+
+	case progress.ArtifactArchive:
+		file, header, err := r.FormFile("archive")
+		if err != nil {
+			s.badRequest(w, "Archive is required")
+			return
+		}
+		defer file.Close()
+		if !hasArchiveExt(header.Filename) {
+			s.badRequest(w, "Archive must be .zip, .tar.gz or .tgz")
+			return
+		}
+		archive, err = io.ReadAll(io.LimitReader(file, maxReviewArchiveBytes+1))
+		if err != nil {
+			s.serverError(w, err)
+			return
+		}
+		if len(archive) > maxReviewArchiveBytes {
+			s.badRequest(w, "Archive is too large")
+			return
+		}
+		rv.Artifact = filepath.Base(header.Filename)
+
+	case progress.ArtifactLink:
+		link := strings.TrimSpace(r.FormValue("link"))
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			s.badRequest(w, "Link must be an http(s) URL")
+			return
+		}
+		if rv.Description == "" {
+			s.badRequest(w, "Description is required for a link")
+			return
+		}
+		rv.Artifact = link
+
+	default:
+		s.badRequest(w, "Unknown artifact kind")
+		return
+	}
+
+	if err := s.progressRepo.CreateReview(rv, archive); err != nil {
+		if errors.Is(err, progress.ErrReviewPending) {
+			http.Error(w, "Previous submission is still pending review", http.StatusConflict)
+			return
+		}
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, rv)
+}
+
+// handleDecideReview одобряет или отклоняет сдачу; очки начисляются только при одобрении.
+func (s *Server) handleDecideReview(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		s.badRequest(w, "Invalid review ID")
+		return
+	}
+
+	var req struct {
+		Approve  bool   `json:"approve"`
+		Feedback string `json:"feedback"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
+	}
+	req.Feedback = strings.TrimSpace(req.Feedback)
+	if !req.Approve && req.Feedback == "" {
+		s.badRequest(w, "Feedback is required to reject")
+		return
+	}
+
+	rv, err := s.progressRepo.GetReview(id)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if rv == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	points, err := s.progressRepo.DecideReview(id, req.Approve, req.Feedback)
+	if err != nil {
+		if errors.Is(err, progress.ErrReviewClosed) {
+			http.Error(w, "Review is already closed", http.StatusConflict)
+			return
+		}
+		s.serverError(w, err)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{
+		"success":        true,
+		"points_awarded": points,
+	})
+}
+
+// handleVerifyProject проверяет локальный проект manual-задания скриптом <Verify>.
+func (s *Server) handleVerifyProject(w http.ResponseWriter, r *http.Request) {
+	taskID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || taskID <= 0 {
+		s.badRequest(w, "Invalid task ID")
+		return
+	}
+
+	var req struct {
+		Dir string `json:"dir"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.badRequest(w, "Invalid JSON")
+		return
+	}
+	req.Dir = strings.TrimSpace(req.Dir)
+	if req.Dir == "" {
+		s.badRequest(w, "Directory is required")
+		return
+	}
+	if info, err := os.Stat(req.Dir); err != nil || !info.IsDir() {
+		s.badRequest(w, "Directory not found on server")
+		return
+	}
+
+	task, err := s.contentRepo.GetTaskByID(taskID)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if task == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if strings.TrimSpace(task.VerifyScript) == "" {
+		s.badRequest(w, "Task has no verify script")
+		return
+	}
+
+	// go build/vet/test могут идти дольше общего WriteTimeout сервера
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Now().Add(4 * practice.ProjectStepTimeout))
+
+	result, err := s.checker.CheckProject(r.Context(), taskID, req.Dir)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	var reviewID int64
+	if result.Review != nil {
+		reviewID = result.Review.ID
+	}
+	s.jsonResponse(w, map[string]interface{}{
+		"success":   result.Success,
+		"steps":     result.Steps,
+		"report":    result.Report(),
+		"review_id": reviewID,
+	})
+}
+
+// handleReviewArchive отдаёт загруженный на проверку архив.
+func (s *Server) handleReviewArchive(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		s.badRequest(w, "Invalid review ID")
+		return
+	}
+
+	name, data, err := s.progressRepo.GetReviewArchive(id)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if data == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.Write(data)
+}
+
+func hasArchiveExt(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range reviewArchiveExts {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,17 @@ document.addEventListener('DOMContentLoaded', () => {
     initCodeEditors();
     initManualTasks();
     initNotesEditor();
+    initProgressImport();
+    initTimeTracking();
+    initHighlights();
+    initBookmarks();
+    initPaths();
+    initPlacement();
+    initReviews();
+    initProjectVerify();
+    initSolutions();
+    initMilestones();
+    initLiveReload();
 });
 
 // ========================================
@@ -161,6 +172,7 @@ function initCodeEditors() {
                         pointsBadge.classList.add('completed');
                     }
                     card.setAttribute('data-completed', 'true');
+                    card.querySelector('.task-solution')?.setAttribute('data-free', 'true');
                     
                     // Обновляем статистику в шапке
                     updateHeaderStats();
@@ -197,33 +209,40 @@ function initCodeEditors() {
 }
 
 // ========================================
-// Manual Tasks (выполнение в IDE)
+// Manual Tasks (сдача на проверку)
 // ========================================
 
 function initManualTasks() {
     document.querySelectorAll('.task-card[data-task-mode="manual"]').forEach(card => {
-        const taskId = card.dataset.taskId;
-        const completeBtn = card.querySelector('.complete-btn');
+        const form = card.querySelector('.review-form');
         const outputDiv = card.querySelector('.task-output');
         const outputContent = card.querySelector('.output-content');
 
-        if (!completeBtn || !taskId) return;
+        if (!form) return;
 
-        completeBtn.addEventListener('click', async () => {
-            completeBtn.disabled = true;
-            const oldText = completeBtn.textContent;
-            completeBtn.textContent = '⏳ Сохраняем...';
+        // Показываем только поле выбранного вида артефакта
+        const syncFields = () => {
+            const kind = form.querySelector('input[name="kind"]:checked').value;
+            form.querySelectorAll('.review-field').forEach(field => {
+                field.hidden = field.dataset.kind !== kind;
+            });
+        };
+        form.querySelectorAll('input[name="kind"]').forEach(radio => {
+            radio.addEventListener('change', syncFields);
+        });
+        syncFields();
 
-            if (outputDiv && outputContent) {
-                outputDiv.style.display = 'block';
-                outputDiv.className = 'task-output';
-                outputContent.textContent = 'Отмечаем выполнение...';
-            }
+        form.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            const submitBtn = form.querySelector('button[type="submit"]');
+            const oldText = submitBtn.textContent;
+            submitBtn.disabled = true;
+            submitBtn.textContent = '⏳ Отправляем...';
 
             try {
-                const response = await fetch(`/api/tasks/${taskId}/complete`, {
+                const response = await fetch(`/api/tasks/${form.dataset.taskId}/review`, {
                     method: 'POST',
-                    headers: { 'Content-Type': 'application/json' }
+                    body: new FormData(form)
                 });
 
                 if (!response.ok) {
@@ -231,41 +250,251 @@ function initManualTasks() {
                     throw new Error(text || `HTTP ${response.status}`);
                 }
 
-                const result = await response.json();
+                outputDiv.style.display = 'block';
+                outputDiv.className = 'task-output success';
+                outputContent.textContent = '📤 Отправлено на проверку. Очки будут начислены после одобрения.';
+                form.remove();
+            } catch (error) {
+                submitBtn.disabled = false;
+                submitBtn.textContent = oldText;
+                outputDiv.style.display = 'block';
+                outputDiv.className = 'task-output error';
+                outputContent.textContent = 'Ошибка: ' + error.message;
+            }
+        });
+    });
+}
+
+// ========================================
+// Project Verify (проверка локального проекта)
+// ========================================
+
+function initProjectVerify() {
+    document.querySelectorAll('.project-verify').forEach(block => {
+        const card = block.closest('.task-card');
+        const form = block.querySelector('.project-verify-form');
+        const outputDiv = card.querySelector('.task-output');
+        const outputContent = card.querySelector('.output-content');
 
-                const points = result.points_awarded || 0;
+        form.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            const submitBtn = form.querySelector('button[type="submit"]');
+            submitBtn.disabled = true;
+            submitBtn.textContent = '⏳ Проверяем...';
+            outputDiv.style.display = 'block';
+            outputDiv.className = 'task-output';
+            outputContent.textContent = 'go build, go vet, go test... это может занять пару минут';
 
-                if (outputDiv && outputContent) {
+            try {
+                const response = await fetch(`/api/tasks/${block.dataset.taskId}/verify`, {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({ dir: form.dir.value })
+                });
+                if (!response.ok) {
+                    throw new Error(await response.text());
+                }
+                const result = await response.json();
+
+                let message = result.report;
+                if (result.success) {
                     outputDiv.className = 'task-output success';
-                    let message = '✅ Задание отмечено выполненным.';
-                    if (points) {
-                        message += `\n🏆 +${points} очков!`;
-                    }
-                    outputContent.textContent = message;
+                    message += result.review_id
+                        ? '\n📝 Проверка пройдена — сдача отправлена на проверку, очки начислятся после одобрения'
+                        : '\n✅ Проверка пройдена';
+                } else {
+                    outputDiv.className = 'task-output error';
                 }
+                outputContent.textContent = message;
+            } catch (error) {
+                outputDiv.className = 'task-output error';
+                outputContent.textContent = 'Ошибка: ' + error.message;
+            } finally {
+                submitBtn.disabled = false;
+                submitBtn.textContent = '🔎 Проверить проект';
+            }
+        });
+    });
+}
+
+// ========================================
+// Solutions (эталонные решения заданий)
+// ========================================
+
+function initSolutions() {
+    document.querySelectorAll('.task-solution').forEach(block => {
+        const btn = block.querySelector('.solution-btn');
+        const code = block.querySelector('.solution-code');
+
+        btn.addEventListener('click', async () => {
+            if (!block.dataset.free &&
+                !confirm(`Открыть эталонное решение? За это задание будет начислено на ${block.dataset.penalty}% меньше очков.`)) {
+                return;
+            }
+            btn.disabled = true;
 
-                // Обновляем бейдж очков на "Выполнено"
-                const pointsBadge = card.querySelector('.task-points');
-                if (pointsBadge && !pointsBadge.classList.contains('completed')) {
-                    pointsBadge.textContent = '✅ Выполнено';
-                    pointsBadge.classList.add('completed');
+            try {
+                const response = await fetch(`/api/tasks/${block.dataset.taskId}/solution`, { method: 'POST' });
+                if (!response.ok) {
+                    throw new Error(await response.text());
                 }
-                card.setAttribute('data-completed', 'true');
+                const result = await response.json();
 
-                // Закрываем кнопку (или оставляем disabled)
-                completeBtn.textContent = '✅ Выполнено';
+                code.textContent = result.solution;
+                code.hidden = false;
+                btn.remove();
+                block.dataset.free = 'true';
 
-                // Обновляем статистику в шапке
-                updateHeaderStats();
+                if (result.points_lost) {
+                    const badge = block.closest('.task-card').querySelector('.task-points');
+                    if (badge && !badge.classList.contains('completed')) {
+                        badge.textContent += ` −${block.dataset.penalty}%`;
+                    }
+                }
             } catch (error) {
-                completeBtn.disabled = false;
-                completeBtn.textContent = oldText;
+                btn.disabled = false;
+                alert('Ошибка: ' + error.message);
+            }
+        });
+    });
+}
 
-                if (outputDiv && outputContent) {
-                    outputDiv.className = 'task-output error';
-                    outputContent.textContent = 'Ошибка: ' + error.message;
+// ========================================
+// Milestones (этапы capstone-проектов)
+// ========================================
+
+function initMilestones() {
+    document.querySelectorAll('.project-detail').forEach(card => {
+        const projectId = card.dataset.projectId;
+        const dirInput = card.querySelector('.project-dir-form input[name="dir"]');
+        const dirKey = `project-dir:${projectId}`;
+
+        // Каталог проекта запоминаем, чтобы не вводить его перед каждой проверкой
+        dirInput.value = localStorage.getItem(dirKey) || '';
+        dirInput.addEventListener('change', () => localStorage.setItem(dirKey, dirInput.value.trim()));
+        card.querySelector('.project-dir-form').addEventListener('submit', e => e.preventDefault());
+
+        const updateProgress = (done, total) => {
+            const percent = total ? Math.floor(done * 100 / total) : 0;
+            card.querySelector('.project-progress .progress-bar').style.width = `${percent}%`;
+            card.querySelector('.project-progress-text').textContent = `${done} / ${total} этапов · ${percent}%`;
+        };
+
+        card.querySelectorAll('.milestone[data-milestone-id]').forEach(item => {
+            const url = `/api/projects/${projectId}/milestones/${item.dataset.milestoneId}`;
+            const checkbox = item.querySelector('.milestone-check input');
+
+            checkbox.addEventListener('change', async () => {
+                try {
+                    const response = await fetch(url, {
+                        method: 'POST',
+                        headers: { 'Content-Type': 'application/json' },
+                        body: JSON.stringify({ done: checkbox.checked })
+                    });
+                    if (!response.ok) {
+                        throw new Error(await response.text());
+                    }
+                    const result = await response.json();
+                    item.classList.toggle('milestone-done', result.done);
+                    if (!result.done) {
+                        item.querySelector('.milestone-verified')?.remove();
+                    }
+                    updateProgress(result.project_done, result.project_total);
+                } catch (error) {
+                    checkbox.checked = !checkbox.checked;
+                    alert('Ошибка: ' + error.message);
                 }
-            }
+            });
+
+            const verifyBtn = item.querySelector('.milestone-verify-btn');
+            if (!verifyBtn) return;
+            const report = item.querySelector('.milestone-verify-report');
+
+            verifyBtn.addEventListener('click', async () => {
+                const dir = dirInput.value.trim();
+                if (!dir) {
+                    dirInput.focus();
+                    return;
+                }
+                localStorage.setItem(dirKey, dir);
+
+                verifyBtn.disabled = true;
+                verifyBtn.textContent = '⏳ Проверяем...';
+                report.hidden = false;
+                report.className = 'milestone-verify-report';
+                report.textContent = 'Проверка может занять пару минут';
+
+                try {
+                    const response = await fetch(`${url}/verify`, {
+                        method: 'POST',
+                        headers: { 'Content-Type': 'application/json' },
+                        body: JSON.stringify({ dir })
+                    });
+                    if (!response.ok) {
+                        throw new Error(await response.text());
+                    }
+                    const result = await response.json();
+
+                    report.textContent = result.report;
+                    report.classList.add(result.success ? 'success' : 'error');
+                    if (result.success) {
+                        checkbox.checked = true;
+                        item.classList.add('milestone-done');
+                        if (!item.querySelector('.milestone-verified')) {
+                            const badge = document.createElement('span');
+                            badge.className = 'milestone-verified';
+                            badge.title = 'Подтверждено автопроверкой';
+                            badge.textContent = '🔎 проверено';
+                            item.querySelector('.milestone-check').appendChild(badge);
+                        }
+                    } else {
+                        item.querySelector('.milestone-verified')?.remove();
+                    }
+                    updateProgress(result.project_done, result.project_total);
+                } catch (error) {
+                    report.classList.add('error');
+                    report.textContent = 'Ошибка: ' + error.message;
+                } finally {
+                    verifyBtn.disabled = false;
+                    verifyBtn.textContent = 'Проверить';
+                }
+            });
+        });
+    });
+}
+
+// ========================================
+// Reviews (проверка manual-заданий)
+// ========================================
+
+function initReviews() {
+    document.querySelectorAll('.review-card[data-review-id]').forEach(card => {
+        const reviewId = card.dataset.reviewId;
+        const feedback = card.querySelector('.review-feedback-input');
+
+        card.querySelectorAll('.review-decide-btn').forEach(btn => {
+            btn.addEventListener('click', async () => {
+                const approve = btn.dataset.approve === 'true';
+                if (!approve && !feedback.value.trim()) {
+                    alert('Для отклонения напишите, что нужно исправить');
+                    feedback.focus();
+                    return;
+                }
+
+                try {
+                    const response = await fetch(`/api/reviews/${reviewId}`, {
+                        method: 'POST',
+                        headers: { 'Content-Type': 'application/json' },
+                        body: JSON.stringify({ approve, feedback: feedback.value })
+                    });
+                    if (!response.ok) {
+                        throw new Error(await response.text());
+                    }
+                    window.location.reload();
+                } catch (error) {
+                    alert('Ошибка: ' + error.message);
+                }
+            });
         });
     });
 }
@@ -417,6 +646,73 @@ function initNotesEditor() {
     
     // Кнопка сохранения
     saveBtn.addEventListener('click', saveNotes);
+
+    // История версий
+    const historyBtn = document.querySelector('.notes-history-btn');
+    const historyBox = document.querySelector('.notes-history');
+    historyBtn?.addEventListener('click', async () => {
+        if (!historyBox.hidden) {
+            historyBox.hidden = true;
+            return;
+        }
+        await loadHistory();
+        historyBox.hidden = false;
+    });
+
+    async function loadHistory() {
+        historyBox.textContent = 'Загрузка...';
+        try {
+            const response = await fetch(`/api/notes/lesson/${lessonId}/revisions`);
+            if (!response.ok) throw new Error(`HTTP ${response.status}`);
+            const { revisions } = await response.json();
+
+            historyBox.textContent = '';
+            if (revisions.length === 0) {
+                historyBox.textContent = 'Прежних версий пока нет.';
+                return;
+            }
+            revisions.forEach(rev => {
+                const row = document.createElement('div');
+                row.className = 'note-revision';
+
+                const date = document.createElement('span');
+                date.className = 'note-revision-date';
+                date.textContent = new Date(rev.created_at).toLocaleString('ru-RU');
+
+                const preview = document.createElement('span');
+                preview.className = 'note-revision-preview';
+                preview.textContent = rev.note_md;
+
+                const restoreBtn = document.createElement('button');
+                restoreBtn.className = 'btn btn-secondary btn-sm';
+                restoreBtn.textContent = '↩️ Восстановить';
+                restoreBtn.addEventListener('click', () => restore(rev.id));
+
+                row.append(date, preview, restoreBtn);
+                historyBox.appendChild(row);
+            });
+        } catch (error) {
+            historyBox.textContent = '❌ Не удалось загрузить историю';
+        }
+    }
+
+    async function restore(revisionId) {
+        if (!confirm('Восстановить эту версию? Текущая заметка сохранится в истории.')) return;
+        clearTimeout(saveTimeout);
+
+        try {
+            const response = await fetch(`/api/notes/lesson/${lessonId}/revisions/${revisionId}/restore`, {
+                method: 'POST'
+            });
+            if (!response.ok) throw new Error(`HTTP ${response.status}`);
+            const { note } = await response.json();
+            notesInput.value = note;
+            statusSpan.textContent = '✓ Версия восстановлена';
+            await loadHistory();
+        } catch (error) {
+            statusSpan.textContent = '❌ Ошибка восстановления';
+        }
+    }
     
     async function saveNotes() {
         clearTimeout(saveTimeout);
@@ -470,3 +766,702 @@ async function resetProgress() {
         alert('❌ Ошибка сети: ' + error.message);
     }
 }
+
+// ========================================
+// Export / Import Progress
+// ========================================
+
+function initProgressImport() {
+    const input = document.querySelector('.import-progress-input');
+    if (!input) return;
+
+    input.addEventListener('change', async () => {
+        const file = input.files[0];
+        if (!file) return;
+
+        try {
+            const response = await fetch('/api/progress/import', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: await file.text()
+            });
+
+            if (!response.ok) {
+                const text = await response.text();
+                throw new Error(text || `HTTP ${response.status}`);
+            }
+
+            const { report } = await response.json();
+            let message = '✅ Прогресс импортирован!\n\n';
+            message += `Уроков объединено: ${report.progress_merged}\n`;
+            message += `Заметок импортировано: ${report.notes_imported} (оставлено локальных: ${report.notes_kept})\n`;
+            message += `Отправок добавлено: ${report.submissions_added} (пропущено: ${report.submissions_skipped})`;
+            if (report.unknown_lessons && report.unknown_lessons.length > 0) {
+                message += `\n\n⚠️ Не найдено уроков: ${report.unknown_lessons.length}`;
+            }
+            alert(message);
+            window.location.reload();
+        } catch (error) {
+            alert('❌ Ошибка импорта: ' + error.message);
+        } finally {
+            input.value = '';
+        }
+    });
+}
+
+// ========================================
+// Time Tracking (heartbeat)
+// ========================================
+
+const HEARTBEAT_INTERVAL_SEC = 15;
+const IDLE_TIMEOUT_MS = 2 * 60 * 1000;
+
+function initTimeTracking() {
+    const article = document.querySelector('.lesson-content[data-lesson-id]');
+    if (!article) return;
+
+    const lessonId = Number(article.dataset.lessonId);
+    let activeTaskId = 0;
+    let lastActivity = Date.now();
+    let pendingSec = 0;
+
+    // Без действий пользователя дольше IDLE_TIMEOUT_MS время не считаем
+    ['mousemove', 'keydown', 'scroll', 'click', 'touchstart'].forEach(event => {
+        document.addEventListener(event, () => { lastActivity = Date.now(); }, { passive: true });
+    });
+
+    // Время на задание идёт, пока пользователь работает в его карточке
+    document.addEventListener('focusin', event => {
+        const card = event.target.closest('.task-card');
+        if (card) activeTaskId = Number(card.dataset.taskId);
+    });
+    document.addEventListener('click', event => {
+        const card = event.target.closest('.task-card');
+        activeTaskId = card ? Number(card.dataset.taskId) : activeTaskId;
+    });
+
+    function send(useBeacon) {
+        if (pendingSec <= 0) return;
+        const body = JSON.stringify({ lesson_id: lessonId, task_id: activeTaskId, seconds: pendingSec });
+        pendingSec = 0;
+
+        if (useBeacon && navigator.sendBeacon) {
+            navigator.sendBeacon('/api/time/heartbeat', new Blob([body], { type: 'application/json' }));
+            return;
+        }
+        fetch('/api/time/heartbeat', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body,
+            keepalive: true
+        }).catch(error => console.error('Heartbeat error:', error));
+    }
+
+    // Копим только видимое и активное время с момента прошлого тика
+    let lastTick = Date.now();
+    function tick(wasVisible) {
+        const now = Date.now();
+        if (wasVisible && now - lastActivity <= IDLE_TIMEOUT_MS) {
+            pendingSec += Math.round((now - lastTick) / 1000);
+        }
+        lastTick = now;
+    }
+
+    setInterval(() => {
+        tick(document.visibilityState === 'visible');
+        send(false);
+    }, HEARTBEAT_INTERVAL_SEC * 1000);
+
+    document.addEventListener('visibilitychange', () => {
+        if (document.visibilityState === 'hidden') {
+            // Вкладку только что скрыли — отрезок до этого момента был видимым
+            tick(true);
+            send(true);
+        } else {
+            lastTick = Date.now();
+        }
+    });
+}
+
+// ========================================
+// Highlights (выделения и комментарии)
+// ========================================
+
+const HIGHLIGHT_CONTEXT_LEN = 32;
+
+function initHighlights() {
+    const article = document.querySelector('.lesson-content[data-lesson-id]');
+    const toolbar = document.querySelector('.highlight-toolbar');
+    const popover = document.querySelector('.highlight-popover');
+    if (!article || !toolbar || !popover) return;
+
+    const lessonId = Number(article.dataset.lessonId);
+    const highlights = new Map();
+    let pendingRange = null;
+    let activeId = null;
+
+    loadHighlights();
+
+    // Показываем панель над выделенным текстом
+    article.addEventListener('mouseup', () => {
+        setTimeout(() => {
+            const selection = window.getSelection();
+            if (!selection || selection.isCollapsed || !selection.toString().trim()) {
+                toolbar.hidden = true;
+                return;
+            }
+            const range = selection.getRangeAt(0);
+            const container = closestSectionContent(range.commonAncestorContainer);
+            if (!container) {
+                toolbar.hidden = true;
+                return;
+            }
+            pendingRange = range.cloneRange();
+            placeNear(toolbar, range.getBoundingClientRect());
+            toolbar.hidden = false;
+        });
+    });
+
+    toolbar.querySelectorAll('.highlight-action').forEach(btn => {
+        btn.addEventListener('mousedown', event => event.preventDefault());
+        btn.addEventListener('click', async () => {
+            toolbar.hidden = true;
+            if (!pendingRange) return;
+            const h = await createHighlight(pendingRange);
+            pendingRange = null;
+            window.getSelection().removeAllRanges();
+            if (h && btn.dataset.action === 'comment') {
+                openPopover(h.id);
+            }
+        });
+    });
+
+    // Клик по выделению открывает редактор комментария
+    article.addEventListener('click', event => {
+        const mark = event.target.closest('mark.hl');
+        if (!mark) return;
+        openPopover(Number(mark.dataset.highlightId));
+    });
+
+    document.addEventListener('mousedown', event => {
+        if (!popover.hidden && !popover.contains(event.target) && !event.target.closest('mark.hl')) {
+            popover.hidden = true;
+        }
+        if (!toolbar.hidden && !toolbar.contains(event.target)) {
+            toolbar.hidden = true;
+        }
+    });
+
+    popover.querySelectorAll('.highlight-color').forEach(btn => {
+        btn.addEventListener('click', () => {
+            popover.querySelectorAll('.highlight-color').forEach(b => b.classList.remove('active'));
+            btn.classList.add('active');
+        });
+    });
+
+    popover.querySelector('.highlight-save').addEventListener('click', async () => {
+        const h = highlights.get(activeId);
+        if (!h) return;
+        const comment = popover.querySelector('.highlight-comment-input').value.trim();
+        const color = popover.querySelector('.highlight-color.active')?.dataset.color || h.color;
+
+        try {
+            const response = await fetch(`/api/highlights/${h.id}`, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ comment, color })
+            });
+            if (!response.ok) throw new Error(`HTTP ${response.status}`);
+            h.comment = comment;
+            h.color = color;
+            marksOf(h.id).forEach(mark => styleMark(mark, h));
+            popover.hidden = true;
+        } catch (error) {
+            alert('❌ Не удалось сохранить: ' + error.message);
+        }
+    });
+
+    popover.querySelector('.highlight-delete').addEventListener('click', async () => {
+        const id = activeId;
+        if (!confirm('Удалить выделение?')) return;
+        try {
+            const response = await fetch(`/api/highlights/${id}`, { method: 'DELETE' });
+            if (!response.ok) throw new Error(`HTTP ${response.status}`);
+            unwrapHighlight(id);
+            highlights.delete(id);
+            popover.hidden = true;
+        } catch (error) {
+            alert('❌ Не удалось удалить: ' + error.message);
+        }
+    });
+
+    async function loadHighlights() {
+        try {
+            const response = await fetch(`/api/highlights/lesson/${lessonId}`);
+            if (!response.ok) return;
+            const data = await response.json();
+            data.highlights.forEach(h => {
+                highlights.set(h.id, h);
+                anchorHighlight(h);
+            });
+            if (location.hash.startsWith('#hl-')) {
+                document.getElementById(location.hash.slice(1))?.scrollIntoView({ block: 'center' });
+            }
+        } catch (error) {
+            console.error('Error loading highlights:', error);
+        }
+    }
+
+    async function createHighlight(range) {
+        const container = closestSectionContent(range.commonAncestorContainer);
+        const section = container.closest('.lesson-section');
+        const text = container.textContent;
+
+        let start = textOffset(container, range.startContainer, range.startOffset);
+        let quote = range.toString();
+        // Пробелы по краям не входят в цитату
+        start += quote.length - quote.trimStart().length;
+        quote = quote.trim();
+        const end = start + quote.length;
+
+        const payload = {
+            lesson_id: lessonId,
+            section_kind: section.dataset.sectionKind,
+            section_index: Number(section.dataset.sectionIndex),
+            start_offset: start,
+            quote,
+            prefix: text.slice(Math.max(0, start - HIGHLIGHT_CONTEXT_LEN), start),
+            suffix: text.slice(end, end + HIGHLIGHT_CONTEXT_LEN),
+            color: 'yellow'
+        };
+
+        try {
+            const response = await fetch('/api/highlights', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(payload)
+            });
+            if (!response.ok) throw new Error(await response.text());
+            const { highlight } = await response.json();
+            highlights.set(highlight.id, highlight);
+            wrapText(container, start, end, highlight);
+            return highlight;
+        } catch (error) {
+            alert('❌ Не удалось сохранить выделение: ' + error.message);
+            return null;
+        }
+    }
+
+    // Находит выделение в текущем тексте урока и, если якорь сдвинулся, сохраняет новый
+    function anchorHighlight(h) {
+        const anchor = findAnchor(h);
+        if (!anchor) {
+            if (!h.orphaned) {
+                h.orphaned = true;
+                saveAnchor(h, { orphaned: true });
+            }
+            return;
+        }
+
+        wrapText(anchor.container, anchor.start, anchor.start + h.quote.length, h);
+
+        const section = anchor.container.closest('.lesson-section');
+        const kind = section.dataset.sectionKind;
+        const index = Number(section.dataset.sectionIndex);
+        if (h.orphaned || kind !== h.section_kind || index !== h.section_index || anchor.start !== h.start_offset) {
+            Object.assign(h, { section_kind: kind, section_index: index, start_offset: anchor.start, orphaned: false });
+            saveAnchor(h, { section_kind: kind, section_index: index, start_offset: anchor.start, orphaned: false });
+        }
+    }
+
+    function saveAnchor(h, anchor) {
+        fetch(`/api/highlights/${h.id}/anchor`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(anchor)
+        }).catch(error => console.error('Error saving anchor:', error));
+    }
+
+    // Сначала ищем в исходной секции, затем во всём уроке.
+    // Среди нескольких вхождений цитаты выбираем то, где лучше совпадает контекст.
+    function findAnchor(h) {
+        const all = Array.from(article.querySelectorAll('.lesson-section[data-section-kind] .section-content'));
+        const home = all.find(c => {
+            const section = c.closest('.lesson-section');
+            return section.dataset.sectionKind === h.section_kind && Number(section.dataset.sectionIndex) === h.section_index;
+        });
+
+        if (home) {
+            const text = home.textContent;
+            if (text.substr(h.start_offset, h.quote.length) === h.quote &&
+                text.slice(Math.max(0, h.start_offset - h.prefix.length), h.start_offset) === h.prefix) {
+                return { container: home, start: h.start_offset };
+            }
+        }
+
+        const candidates = home ? [home, ...all.filter(c => c !== home)] : all;
+        let best = null;
+        candidates.forEach(container => {
+            const text = container.textContent;
+            let pos = text.indexOf(h.quote);
+            while (pos !== -1) {
+                let score = commonSuffix(text.slice(0, pos), h.prefix) +
+                    commonPrefix(text.slice(pos + h.quote.length), h.suffix);
+                if (container === home) {
+                    score += 1 - Math.min(1, Math.abs(pos - h.start_offset) / 1000);
+                }
+                if (!best || score > best.score) {
+                    best = { container, start: pos, score };
+                }
+                pos = text.indexOf(h.quote, pos + 1);
+            }
+        });
+        return best;
+    }
+
+    function openPopover(id) {
+        const h = highlights.get(id);
+        const mark = marksOf(id)[0];
+        if (!h || !mark) return;
+
+        activeId = id;
+        popover.querySelector('.highlight-comment-input').value = h.comment || '';
+        popover.querySelectorAll('.highlight-color').forEach(b => {
+            b.classList.toggle('active', b.dataset.color === h.color);
+        });
+        placeNear(popover, mark.getBoundingClientRect());
+        popover.hidden = false;
+        popover.querySelector('.highlight-comment-input').focus();
+    }
+
+    function marksOf(id) {
+        return Array.from(article.querySelectorAll(`mark.hl[data-highlight-id="${id}"]`));
+    }
+
+    function unwrapHighlight(id) {
+        marksOf(id).forEach(mark => {
+            const parent = mark.parentNode;
+            while (mark.firstChild) parent.insertBefore(mark.firstChild, mark);
+            parent.removeChild(mark);
+            parent.normalize();
+        });
+    }
+}
+
+function closestSectionContent(node) {
+    const el = node.nodeType === Node.ELEMENT_NODE ? node : node.parentElement;
+    return el?.closest('.lesson-section[data-section-kind] .section-content') || null;
+}
+
+// Смещение точки (node, offset) от начала текста контейнера
+function textOffset(container, node, offset) {
+    const range = document.createRange();
+    range.selectNodeContents(container);
+    range.setEnd(node, offset);
+    return range.toString().length;
+}
+
+// Оборачивает символы [start, end) текста контейнера в <mark>, разрезая текстовые узлы
+function wrapText(container, start, end, h) {
+    const walker = document.createTreeWalker(container, NodeFilter.SHOW_TEXT);
+    const parts = [];
+    let pos = 0;
+    while (walker.nextNode()) {
+        const node = walker.currentNode;
+        const len = node.textContent.length;
+        if (pos + len > start && pos < end) {
+            parts.push({ node, from: Math.max(0, start - pos), to: Math.min(len, end - pos) });
+        }
+        pos += len;
+        if (pos >= end) break;
+    }
+
+    parts.forEach(({ node, from, to }, i) => {
+        let target = node;
+        if (from > 0) target = target.splitText(from);
+        if (to - from < target.textContent.length) target.splitText(to - from);
+
+        const mark = document.createElement('mark');
+        mark.className = 'hl';
+        mark.dataset.highlightId = h.id;
+        if (i === 0) mark.id = `hl-${h.id}`;
+        styleMark(mark, h);
+        target.parentNode.insertBefore(mark, target);
+        mark.appendChild(target);
+    });
+}
+
+function styleMark(mark, h) {
+    mark.className = `hl hl-${h.color}` + (h.comment ? ' hl-commented' : '');
+    mark.title = h.comment || '';
+}
+
+function placeNear(el, rect) {
+    el.style.top = `${window.scrollY + rect.bottom + 8}px`;
+    el.style.left = `${window.scrollX + Math.max(8, rect.left)}px`;
+}
+
+function commonPrefix(a, b) {
+    let n = 0;
+    while (n < a.length && n < b.length && a[n] === b[n]) n++;
+    return n;
+}
+
+function commonSuffix(a, b) {
+    let n = 0;
+    while (n < a.length && n < b.length && a[a.length - 1 - n] === b[b.length - 1 - n]) n++;
+    return n;
+}
+
+// ========================================
+// Bookmarks
+// ========================================
+
+function initBookmarks() {
+    document.querySelectorAll('.bookmark-btn').forEach(btn => {
+        btn.addEventListener('click', async () => {
+            try {
+                const response = await fetch('/api/bookmarks', {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({
+                        lesson_id: Number(btn.dataset.lessonId),
+                        task_id: Number(btn.dataset.taskId)
+                    })
+                });
+                if (!response.ok) throw new Error(`HTTP ${response.status}`);
+                const { bookmarked } = await response.json();
+
+                if (btn.dataset.removeItem && !bookmarked) {
+                    btn.closest('.bookmark-item')?.remove();
+                    return;
+                }
+                btn.classList.toggle('active', bookmarked);
+                if (btn.classList.contains('bookmark-task') || btn.dataset.removeItem) {
+                    btn.textContent = bookmarked ? '★' : '☆';
+                } else {
+                    btn.textContent = bookmarked ? '★ В закладках' : '☆ В закладки';
+                }
+            } catch (error) {
+                console.error('Error toggling bookmark:', error);
+            }
+        });
+    });
+}
+
+// ========================================
+// Learning Paths
+// ========================================
+
+async function pathRequest(url, method, body) {
+    const response = await fetch(url, {
+        method,
+        headers: { 'Content-Type': 'application/json' },
+        body: body ? JSON.stringify(body) : undefined
+    });
+    if (!response.ok) {
+        throw new Error((await response.text()) || `HTTP ${response.status}`);
+    }
+    return response.json();
+}
+
+function initPaths() {
+    // Страница урока: добавить в существующий или новый путь
+    const addBtn = document.querySelector('.path-add-btn');
+    addBtn?.addEventListener('click', async () => {
+        const select = document.querySelector('.path-select');
+        const lessonId = Number(addBtn.dataset.lessonId);
+        try {
+            if (select.value === 'new') {
+                const title = prompt('Название нового пути:');
+                if (!title || !title.trim()) return;
+                const { id } = await pathRequest('/api/paths', 'POST', { title, lesson_id: lessonId });
+                window.location.href = `${window.location.pathname}?path=${id}`;
+                return;
+            }
+            await pathRequest(`/api/paths/${select.value}/lessons`, 'POST', { lesson_id: lessonId });
+            window.location.href = `${window.location.pathname}?path=${select.value}`;
+        } catch (error) {
+            alert('❌ Не удалось добавить в путь: ' + error.message);
+        }
+    });
+
+    // Список путей: создание
+    const createForm = document.querySelector('.path-create-form');
+    createForm?.addEventListener('submit', async event => {
+        event.preventDefault();
+        const data = new FormData(createForm);
+        try {
+            const { id } = await pathRequest('/api/paths', 'POST', {
+                title: data.get('title'),
+                description: data.get('description')
+            });
+            window.location.href = `/paths/${id}`;
+        } catch (error) {
+            alert('❌ Не удалось создать путь: ' + error.message);
+        }
+    });
+
+    // Страница пути: порядок, удаление, добавление уроков
+    const page = document.querySelector('.paths-page[data-path-id]');
+    if (!page) return;
+    const pathId = page.dataset.pathId;
+    const list = page.querySelector('.path-lessons');
+
+    list?.addEventListener('click', async event => {
+        const item = event.target.closest('.path-lesson');
+        if (!item) return;
+
+        try {
+            if (event.target.closest('.path-remove')) {
+                await pathRequest(`/api/paths/${pathId}/lessons/${item.dataset.lessonId}`, 'DELETE');
+                window.location.reload();
+                return;
+            }
+
+            const moveBtn = event.target.closest('.path-move');
+            if (!moveBtn) return;
+            if (moveBtn.dataset.direction === 'up' && item.previousElementSibling) {
+                list.insertBefore(item, item.previousElementSibling);
+            } else if (moveBtn.dataset.direction === 'down' && item.nextElementSibling) {
+                list.insertBefore(item.nextElementSibling, item);
+            } else {
+                return;
+            }
+            const lessonIds = Array.from(list.querySelectorAll('.path-lesson')).map(li => Number(li.dataset.lessonId));
+            await pathRequest(`/api/paths/${pathId}/order`, 'POST', { lesson_ids: lessonIds });
+        } catch (error) {
+            alert('❌ Ошибка: ' + error.message);
+        }
+    });
+
+    page.querySelector('.path-add-lesson-btn')?.addEventListener('click', async () => {
+        const lessonId = Number(page.querySelector('.path-lesson-select').value);
+        try {
+            await pathRequest(`/api/paths/${pathId}/lessons`, 'POST', { lesson_id: lessonId });
+            window.location.reload();
+        } catch (error) {
+            alert('❌ Не удалось добавить урок: ' + error.message);
+        }
+    });
+
+    page.querySelector('.path-delete-btn')?.addEventListener('click', async () => {
+        if (!confirm('Удалить путь? Уроки и прогресс по ним останутся.')) return;
+        try {
+            await pathRequest(`/api/paths/${pathId}`, 'DELETE');
+            window.location.href = '/paths';
+        } catch (error) {
+            alert('❌ Не удалось удалить путь: ' + error.message);
+        }
+    });
+}
+
+// ========================================
+// Placement (вступительный тест)
+// ========================================
+
+function initPlacement() {
+    document.querySelectorAll('.placement-module').forEach(section => {
+        const moduleId = parseInt(section.dataset.moduleId);
+        const checkBtn = section.querySelector('.placement-check-btn');
+        const status = section.querySelector('.placement-status');
+
+        // Код берём из CodeMirror, если редактор подключён
+        const codeOf = card => {
+            const cm = card.querySelector('.CodeMirror');
+            return cm && cm.CodeMirror ? cm.CodeMirror.getValue() : card.querySelector('.code-input').value;
+        };
+
+        checkBtn?.addEventListener('click', async () => {
+            const cards = section.querySelectorAll('.placement-task');
+            const answers = Array.from(cards).map(card => ({
+                task_id: parseInt(card.dataset.taskId),
+                code: codeOf(card)
+            }));
+
+            checkBtn.disabled = true;
+            checkBtn.textContent = '⏳ Проверяем...';
+            status.textContent = '';
+
+            try {
+                const response = await fetch('/api/placement/module', {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({ module_id: moduleId, answers })
+                });
+                if (!response.ok) {
+                    throw new Error(await response.text());
+                }
+                const result = await response.json();
+
+                cards.forEach(card => {
+                    const res = result.Results[card.dataset.taskId];
+                    const outputDiv = card.querySelector('.task-output');
+                    const outputContent = card.querySelector('.output-content');
+                    if (!res) return;
+
+                    outputDiv.style.display = 'block';
+                    if (res.Success) {
+                        outputDiv.className = 'task-output success';
+                        outputContent.textContent = '✅ Решено';
+                    } else {
+                        outputDiv.className = 'task-output error';
+                        let message = '❌ ' + (res.Error || 'Решение неверное');
+                        if (res.Output) {
+                            message += '\n\n📤 Ваш вывод:\n' + res.Output;
+                        }
+                        if (res.Hints && res.Hints.length > 0) {
+                            message += '\n\n💡 Подсказки:\n' + res.Hints.join('\n');
+                        }
+                        outputContent.textContent = message;
+                    }
+                });
+
+                if (result.Passed) {
+                    section.classList.add('passed');
+                    status.textContent = result.LessonsMarked
+                        ? `⏭ Засчитано уроков: ${result.LessonsMarked}`
+                        : '✅ Все уроки модуля уже пройдены';
+                    updateHeaderStats();
+                } else {
+                    status.textContent = 'Модуль не засчитан — решите все задания';
+                }
+            } catch (error) {
+                status.textContent = 'Ошибка: ' + error.message;
+            } finally {
+                checkBtn.disabled = false;
+                checkBtn.textContent = '✓ Проверить модуль';
+            }
+        });
+    });
+}
+
+// ========================================
+// Live Reload (сервер запущен с -watch)
+// ========================================
+
+function initLiveReload() {
+    const article = document.querySelector('.lesson-content[data-live-reload]');
+    if (!article || !window.EventSource) return;
+
+    const slug = article.dataset.liveReload;
+    const scrollKey = 'live-reload-scroll:' + slug;
+
+    // После перезагрузки возвращаемся туда, где читали
+    const savedScroll = sessionStorage.getItem(scrollKey);
+    if (savedScroll !== null) {
+        sessionStorage.removeItem(scrollKey);
+        window.scrollTo(0, Number(savedScroll));
+    }
+
+    const source = new EventSource('/api/live?lesson=' + encodeURIComponent(slug));
+    source.addEventListener('reload', (event) => {
+        // Заголовок урока сменился вместе со slug — переходим на новый адрес
+        if (event.data && event.data !== slug) {
+            location.href = '/lessons/' + encodeURIComponent(event.data);
+            return;
+        }
+        sessionStorage.setItem(scrollKey, String(window.scrollY));
+        location.reload();
+    });
+}